@@ -0,0 +1,205 @@
+// Package auth verifies the credentials of API clients: bcrypt passwords of
+// local users, API keys stored as hashes, HS256 or RS256 signed JWTs, and
+// browser sessions started by signing in with an OpenID Connect provider.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// APIKeyPrefix starts every API key, so keys are told apart from JWTs and
+// are easy to spot when leaked
+const APIKeyPrefix = "blk_"
+
+// LocalIssuer is the issuer of the tokens the server signs at login
+const LocalIssuer = "golang-baseline"
+
+// ErrInvalidCredentials is returned for any password, key or token that
+// does not check out, without telling which part was wrong
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// HashPassword hashes a password with bcrypt
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether a password matches a bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewAPIKey generates an API key. Only the lookup ID and the hash of the
+// key need to be stored; the key itself is shown to its owner once.
+func NewAPIKey() (key, lookup, hash string, err error) {
+	lookupBytes := make([]byte, 6)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(lookupBytes); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+	lookup = hex.EncodeToString(lookupBytes)
+	key = APIKeyPrefix + lookup + "_" + hex.EncodeToString(secretBytes)
+	return key, lookup, HashAPIKey(key), nil
+}
+
+// ParseAPIKey returns the lookup ID of an API key, or false when the value
+// is not shaped like one
+func ParseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	lookup, secret, ok := strings.Cut(rest, "_")
+	if !ok || lookup == "" || secret == "" {
+		return "", false
+	}
+	return lookup, true
+}
+
+// HashAPIKey hashes an API key for storage. Keys are long and random, so a
+// fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckAPIKey reports whether a key matches a stored hash, in constant time
+func CheckAPIKey(hash, key string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashAPIKey(key))) == 1
+}
+
+// NewSecret generates a random value for session IDs and the state and
+// nonce of logins
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// HashSessionID hashes a session ID for storage, so the IDs held by the
+// server cannot be replayed as cookies
+func HashSessionID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// Claims are the claims of the tokens the server issues and accepts
+type Claims struct {
+	Username string `json:"preferred_username,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenOptions configure the signing and verification of JWTs. Secret
+// signs the tokens issued at login and verifies HS256 tokens, which must
+// come from LocalIssuer; the public keys verify RS256 tokens from another
+// issuer, whose Issuer and Audience are checked when set.
+type TokenOptions struct {
+	Secret         []byte
+	PublicKeyFiles []string
+	Issuer         string
+	Audience       string
+	TTL            time.Duration
+}
+
+// Tokens issues and verifies JWTs
+type Tokens struct {
+	options    TokenOptions
+	publicKeys []*rsa.PublicKey
+}
+
+// NewTokens loads the configured keys
+func NewTokens(options TokenOptions) (*Tokens, error) {
+	tokens := &Tokens{options: options}
+	for _, file := range options.PublicKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing JWT public key %s: %w", file, err)
+		}
+		tokens.publicKeys = append(tokens.publicKeys, key)
+	}
+	return tokens, nil
+}
+
+// Issue signs an HS256 token for a user
+func (t *Tokens) Issue(userID, username string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.options.TTL)
+	claims := Claims{
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Issuer:    LocalIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.options.Secret)
+	return token, expiresAt, err
+}
+
+// Verify checks the signature, expiry, issuer and audience of a token and
+// returns its claims. Only HS256 and RS256 are accepted, so a token cannot
+// pick a weaker algorithm or pass a public key off as an HMAC secret.
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() == "HS256" {
+			if len(t.options.Secret) == 0 {
+				return nil, errors.New("HS256 tokens are not accepted")
+			}
+			return t.options.Secret, nil
+		}
+		if len(t.publicKeys) == 0 {
+			return nil, errors.New("RS256 tokens are not accepted")
+		}
+		keys := jwt.VerificationKeySet{}
+		for _, key := range t.publicKeys {
+			keys.Keys = append(keys.Keys, key)
+		}
+		return keys, nil
+	}, jwt.WithValidMethods([]string{"HS256", "RS256"}), jwt.WithExpirationRequired(), jwt.WithLeeway(30*time.Second))
+	if err != nil || claims.Subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if parsed.Method.Alg() == "HS256" {
+		if claims.Issuer != LocalIssuer {
+			return nil, ErrInvalidCredentials
+		}
+		return claims, nil
+	}
+	// Tokens of another issuer must not pass themselves off as ours
+	if claims.Issuer == LocalIssuer || (t.options.Issuer != "" && claims.Issuer != t.options.Issuer) {
+		return nil, ErrInvalidCredentials
+	}
+	if t.options.Audience != "" && !slices.Contains(claims.Audience, t.options.Audience) {
+		return nil, ErrInvalidCredentials
+	}
+	return claims, nil
+}
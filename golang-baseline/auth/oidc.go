@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDCOptions configure single sign-on with an OpenID Connect provider.
+// Scopes are requested on top of "openid"; GroupsClaim names the ID token
+// claim that lists the groups of the user.
+type OIDCOptions struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+}
+
+// Identity is the account an OpenID Connect provider vouches for
+type Identity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+// OIDC signs users in with the authorization code flow and PKCE. The
+// provider is discovered on first use, so the server starts while the
+// provider is unreachable.
+type OIDC struct {
+	options OIDCOptions
+
+	mutex    sync.Mutex
+	config   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDC creates a client for a provider
+func NewOIDC(options OIDCOptions) *OIDC {
+	return &OIDC{options: options}
+}
+
+// NewPKCEVerifier generates the code verifier of a login
+func NewPKCEVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// AuthCodeURL returns the login page of the provider. The state and nonce
+// tie the callback and the ID token to this login; the S256 challenge of
+// the code verifier ties the code exchange to it.
+func (o *OIDC) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	config, _, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange trades an authorization code for the ID token of the user. The
+// signature, issuer, audience, expiry and nonce of the token are checked.
+func (o *OIDC) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	config, verifier, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchanging authorization code: %w", err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("the provider returned no ID token")
+	}
+	idToken, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidCredentials)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	text := func(name string) string {
+		value, _ := claims[name].(string)
+		return value
+	}
+	identity := &Identity{
+		Issuer:   idToken.Issuer,
+		Subject:  idToken.Subject,
+		Username: text("preferred_username"),
+		Email:    text("email"),
+		Name:     text("name"),
+	}
+	// Providers send the groups as a list, or as a single string when
+	// there is only one
+	switch groups := claims[o.options.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+	return identity, nil
+}
+
+// discover fetches the configuration of the provider once it is reachable
+func (o *OIDC) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.config != nil {
+		return o.config, o.verifier, nil
+	}
+	// The provider keeps the context to refresh its signing keys, so it
+	// must outlive the request that discovered it
+	provider, err := oidc.NewProvider(context.WithoutCancel(ctx), o.options.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovering OpenID Connect provider: %w", err)
+	}
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range o.options.Scopes {
+		if scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+	o.config = &oauth2.Config{
+		ClientID:     o.options.ClientID,
+		ClientSecret: o.options.ClientSecret,
+		RedirectURL:  o.options.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	o.verifier = provider.Verifier(&oidc.Config{ClientID: o.options.ClientID})
+	return o.config, o.verifier, nil
+}
@@ -3,6 +3,13 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+)
+
+// Policies for rules that can either flag or refuse an operation
+const (
+	PolicyWarn   = "warn"
+	PolicyReject = "reject"
 )
 
 // Config holds the application configuration
@@ -10,6 +17,120 @@ type Config struct {
 	Port        string
 	Environment string
 	LogLevel    string
+
+	// BlockedStatusPolicy controls what happens when a work item with
+	// unfinished blockers is moved to IN_PROGRESS: "warn" or "reject"
+	BlockedStatusPolicy string
+
+	// WIPLimitPolicy controls what happens when a story status change
+	// breaks a WIP limit of its backlog: "warn" or "reject"
+	WIPLimitPolicy string
+
+	// RequireChecklistForDone stops a story from moving to DONE while
+	// required checklist items are unchecked
+	RequireChecklistForDone bool
+
+	// WorkHoursPerDay converts effort, expressed in hours, into days
+	WorkHoursPerDay int
+
+	// Attachment storage and upload limits. Allowed types may end in "/*"
+	// to accept a whole family such as "image/*".
+	AttachmentDir          string
+	AttachmentMaxSize      int64
+	AttachmentAllowedTypes []string
+
+	// RecurrenceCheckInterval is how often, in seconds, recurring stories
+	// are checked for due occurrences; zero turns the scheduler off
+	RecurrenceCheckInterval int
+
+	// Jira integration, enabled when JiraBaseURL is set. With an email the
+	// token is a Jira Cloud API token; without one it is a personal access
+	// token. JiraStatusMap entries look like "In Review=IN_PROGRESS" and
+	// take precedence over the status category of a Jira status.
+	JiraBaseURL        string
+	JiraEmail          string
+	JiraAPIToken       string
+	JiraStartDateField string
+	JiraStatusMap      []string
+	JiraTimeout        int
+
+	// JiraSyncInterval is how often, in seconds, linked items are synced
+	// with Jira; zero turns the periodic sync off
+	JiraSyncInterval int
+
+	// Webhook delivery. Failed deliveries are retried up to
+	// WebhookMaxAttempts times, waiting WebhookRetryBase seconds doubled
+	// after every failure and at most WebhookRetryMax seconds. Each webhook
+	// keeps its WebhookLogSize most recent deliveries. Webhooks may only
+	// call loopback, private and link-local addresses with
+	// WebhookAllowPrivateNetworks, which is meant for local development.
+	WebhookWorkers              int
+	WebhookMaxAttempts          int
+	WebhookRetryBase            int
+	WebhookRetryMax             int
+	WebhookTimeout              int
+	WebhookLogSize              int
+	WebhookAllowPrivateNetworks bool
+
+	// Change feed. FeedHistorySize events are kept for clients resuming
+	// with a last event ID; FeedHeartbeat is the number of seconds between
+	// heartbeats on idle connections, 15 when it is not positive.
+	FeedHistorySize int
+	FeedHeartbeat   int
+
+	// EventWorkers is the number of workers of each asynchronous event
+	// subscriber, such as webhook delivery
+	EventWorkers int
+
+	// TrustedProxyHops is the number of reverse proxies in front of the
+	// server, each appending to the X-Forwarded-For header the source IP of
+	// requests is taken from. Leave it at 0 when clients reach the server
+	// directly, as they could forge the header.
+	TrustedProxyHops int
+
+	// Authentication. With AuthRequired every endpoint but the health check
+	// and login needs an API key or a bearer token; without it requests
+	// without credentials are served anonymously. AuthBootstrapKey is an
+	// API key accepted without being stored, to register the first users.
+	AuthRequired     bool
+	AuthBootstrapKey string
+
+	// JWTs. JWTSecret signs the tokens issued at login; a random secret is
+	// used when it is empty, so tokens do not survive a restart. RS256
+	// tokens of another issuer are verified with the PEM public keys and
+	// checked against JWTIssuer and JWTAudience when set. JWTTTL is in
+	// minutes.
+	JWTSecret         string
+	JWTPublicKeyFiles []string
+	JWTIssuer         string
+	JWTAudience       string
+	JWTTTL            int
+
+	// OpenID Connect single sign-on, enabled when OIDCIssuer is set. Users
+	// are provisioned into OIDCWorkspace on their first login. Entries of
+	// OIDCGroupRoles look like "engineering=editor" and give the members
+	// of a group a role on every backlog of the workspace; the role
+	// "admin" makes them workspace admins. Groups are read from the
+	// OIDCGroupsClaim claim of the ID token.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+	OIDCGroupsClaim  string
+	OIDCGroupRoles   []string
+	OIDCWorkspace    string
+
+	// Browser sessions started by single sign-on. SessionTTL is in
+	// minutes. Session cookies are only sent over HTTPS unless
+	// SessionCookieInsecure is set, which is meant for local development.
+	SessionTTL            int
+	SessionCookieInsecure bool
+
+	// CORSAllowedOrigins are the browser origins allowed to call the API
+	// and open feed WebSockets. Entries may hold one "*" wildcard, such as
+	// "https://*.example.com", and a lone "*" allows every origin.
+	CORSAllowedOrigins []string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -18,6 +139,67 @@ func LoadConfig() *Config {
 		Port:        getEnv("PORT", "8080"),
 		Environment: getEnv("ENVIRONMENT", "development"),
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
+
+		BlockedStatusPolicy: getEnv("BLOCKED_STATUS_POLICY", PolicyWarn),
+		WIPLimitPolicy:      getEnv("WIP_LIMIT_POLICY", PolicyWarn),
+		WorkHoursPerDay:     getEnvAsInt("WORK_HOURS_PER_DAY", 8),
+
+		RequireChecklistForDone: getEnvAsBool("REQUIRE_CHECKLIST_FOR_DONE", true),
+
+		AttachmentDir:     getEnv("ATTACHMENT_DIR", "./data/attachments"),
+		AttachmentMaxSize: int64(getEnvAsInt("ATTACHMENT_MAX_SIZE", 10<<20)),
+		AttachmentAllowedTypes: getEnvAsSlice("ATTACHMENT_ALLOWED_TYPES", []string{
+			"image/*", "text/*", "application/pdf", "application/zip", "application/json",
+		}),
+
+		RecurrenceCheckInterval: getEnvAsInt("RECURRENCE_CHECK_INTERVAL", 60),
+
+		JiraBaseURL:        getEnv("JIRA_BASE_URL", ""),
+		JiraEmail:          getEnv("JIRA_EMAIL", ""),
+		JiraAPIToken:       getEnv("JIRA_API_TOKEN", ""),
+		JiraStartDateField: getEnv("JIRA_START_DATE_FIELD", "customfield_10015"),
+		JiraStatusMap:      getEnvAsSlice("JIRA_STATUS_MAP", nil),
+		JiraTimeout:        getEnvAsInt("JIRA_TIMEOUT", 30),
+		JiraSyncInterval:   getEnvAsInt("JIRA_SYNC_INTERVAL", 300),
+
+		WebhookWorkers:     getEnvAsInt("WEBHOOK_WORKERS", 4),
+		WebhookMaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookRetryBase:   getEnvAsInt("WEBHOOK_RETRY_BASE", 10),
+		WebhookRetryMax:    getEnvAsInt("WEBHOOK_RETRY_MAX", 3600),
+		WebhookTimeout:     getEnvAsInt("WEBHOOK_TIMEOUT", 10),
+		WebhookLogSize:     getEnvAsInt("WEBHOOK_LOG_SIZE", 100),
+
+		WebhookAllowPrivateNetworks: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+
+		FeedHistorySize: getEnvAsInt("FEED_HISTORY_SIZE", 1000),
+		FeedHeartbeat:   getEnvAsInt("FEED_HEARTBEAT", 15),
+
+		EventWorkers: getEnvAsInt("EVENT_WORKERS", 4),
+
+		TrustedProxyHops: getEnvAsInt("TRUSTED_PROXY_HOPS", 0),
+
+		AuthRequired:     getEnvAsBool("AUTH_REQUIRED", true),
+		AuthBootstrapKey: getEnv("AUTH_BOOTSTRAP_KEY", ""),
+
+		JWTSecret:         getEnv("JWT_SECRET", ""),
+		JWTPublicKeyFiles: getEnvAsSlice("JWT_PUBLIC_KEY_FILES", nil),
+		JWTIssuer:         getEnv("JWT_ISSUER", ""),
+		JWTAudience:       getEnv("JWT_AUDIENCE", ""),
+		JWTTTL:            getEnvAsInt("JWT_TTL", 60),
+
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/auth/oidc/callback"),
+		OIDCScopes:       getEnvAsSlice("OIDC_SCOPES", []string{"profile", "email", "groups"}),
+		OIDCGroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCGroupRoles:   getEnvAsSlice("OIDC_GROUP_ROLES", nil),
+		OIDCWorkspace:    getEnv("OIDC_WORKSPACE", "default"),
+
+		SessionTTL:            getEnvAsInt("SESSION_TTL", 480),
+		SessionCookieInsecure: getEnvAsBool("SESSION_COOKIE_INSECURE", false),
+
+		CORSAllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 	}
 }
 
@@ -48,3 +230,17 @@ func getEnvAsBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+// getEnvAsSlice gets a comma separated environment variable as a slice with a fallback value
+func getEnvAsSlice(key string, fallback []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var values []string
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		return values
+	}
+	return fallback
+}
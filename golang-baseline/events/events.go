@@ -0,0 +1,245 @@
+// Package events is an in-process bus for domain events. Every change to an
+// aggregate is published once, with snapshots of the aggregate before and
+// after it, to subscribers that run either synchronously with the change or
+// asynchronously on background workers.
+package events
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a change to one aggregate. Before is empty when the aggregate
+// was created and After is empty when it was deleted; both hold the JSON
+// form of the aggregate, so they cannot change after publishing. IDs
+// increase by one per event. WorkspaceID is the workspace the aggregate
+// belongs to. Actor, RequestID and SourceIP tell who made the change; they
+// are empty for changes made by the application itself.
+type Event struct {
+	ID          uint64          `json:"id"`
+	Type        string          `json:"type"`
+	WorkspaceID string          `json:"workspace_id"`
+	Aggregate   string          `json:"aggregate"`
+	AggregateID string          `json:"aggregate_id"`
+	BacklogID   string          `json:"backlog_id,omitempty"`
+	StoryID     string          `json:"story_id,omitempty"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	Actor       string          `json:"actor,omitempty"`
+	RequestID   string          `json:"request_id,omitempty"`
+	SourceIP    string          `json:"source_ip,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// Key identifies the aggregate of an event. Events with the same key reach
+// every subscriber in the order they were published.
+func (e Event) Key() string {
+	return e.Aggregate + ":" + e.AggregateID
+}
+
+// Decode unmarshals the snapshots into before and after. Either may be nil
+// to skip it; missing snapshots leave their target untouched.
+func (e Event) Decode(before, after interface{}) error {
+	if before != nil && len(e.Before) > 0 {
+		if err := json.Unmarshal(e.Before, before); err != nil {
+			return err
+		}
+	}
+	if after != nil && len(e.After) > 0 {
+		if err := json.Unmarshal(e.After, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler receives events
+type Handler func(Event)
+
+// Matches reports whether an event type matches one of the patterns. A
+// pattern is an exact type, a prefix ending in "*" such as "story.*", or
+// "*"; no patterns match everything.
+func Matches(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriber is a handler with the events it wants. Asynchronous
+// subscribers have one queue per worker; events are spread over the queues
+// by aggregate, so one aggregate is always handled by the same worker.
+type subscriber struct {
+	name     string
+	patterns []string
+	handler  Handler
+	queues   []*queue
+}
+
+// handle calls the handler, logging a panic instead of passing it on so
+// one broken subscriber cannot take down the change that published the
+// event
+func (s *subscriber) handle(event Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("ERROR: event subscriber %s panicked on %s event %d: %v", s.name, event.Type, event.ID, recovered)
+		}
+	}()
+	s.handler(event)
+}
+
+// queue is an unbounded FIFO of events, so publishing never blocks
+type queue struct {
+	mutex  sync.Mutex
+	events []Event
+	wake   chan struct{}
+}
+
+func (q *queue) push(event Event) {
+	q.mutex.Lock()
+	q.events = append(q.events, event)
+	q.mutex.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// pop returns the oldest event, or false when the queue is empty
+func (q *queue) pop() (Event, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.events) == 0 {
+		return Event{}, false
+	}
+	event := q.events[0]
+	q.events[0] = Event{}
+	q.events = q.events[1:]
+	return event, true
+}
+
+// Bus delivers published events to subscribers. Publishers must serialise
+// their calls to Publish for the order of events to be meaningful; the
+// service does so by publishing while it holds its lock.
+type Bus struct {
+	mutex       sync.Mutex
+	nextID      uint64
+	subscribers []*subscriber
+}
+
+// NewBus creates a bus without subscribers
+func NewBus() *Bus {
+	return &Bus{nextID: 1}
+}
+
+// Subscribe registers a synchronous handler for the events matching the
+// patterns. It runs inside Publish, before Publish returns, so it sees
+// events in exactly the order they were published. It must be quick and
+// must not publish or wait on the publisher.
+func (b *Bus) Subscribe(name string, handler Handler, patterns ...string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers = append(b.subscribers, &subscriber{name: name, patterns: patterns, handler: handler})
+}
+
+// SubscribeAsync registers a handler that runs on its own workers once the
+// bus is started. Events of one aggregate are handled one at a time in the
+// order they were published; events of different aggregates may be
+// handled concurrently when there are several workers.
+func (b *Bus) SubscribeAsync(name string, workers int, handler Handler, patterns ...string) {
+	if workers < 1 {
+		workers = 1
+	}
+	sub := &subscriber{name: name, patterns: patterns, handler: handler}
+	for i := 0; i < workers; i++ {
+		sub.queues = append(sub.queues, &queue{wake: make(chan struct{}, 1)})
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers = append(b.subscribers, sub)
+}
+
+// Publish numbers an event, stamps it and hands it to the subscribers:
+// synchronous handlers run right away, asynchronous ones are queued.
+func (b *Bus) Publish(event Event) Event {
+	b.mutex.Lock()
+	event.ID = b.nextID
+	b.nextID++
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	subscribers := b.subscribers
+	b.mutex.Unlock()
+
+	for _, sub := range subscribers {
+		if !Matches(sub.patterns, event.Type) {
+			continue
+		}
+		if sub.queues == nil {
+			sub.handle(event)
+			continue
+		}
+		hash := fnv.New32a()
+		hash.Write([]byte(event.Key()))
+		sub.queues[hash.Sum32()%uint32(len(sub.queues))].push(event)
+	}
+	return event
+}
+
+// Start runs the workers of the asynchronous subscribers in the background.
+// Events published before Start wait in their queues. The returned function
+// stops the workers once they have handled every event already queued.
+func (b *Bus) Start() func() {
+	b.mutex.Lock()
+	subscribers := b.subscribers
+	b.mutex.Unlock()
+
+	done := make(chan struct{})
+	var workers sync.WaitGroup
+	for _, sub := range subscribers {
+		for _, q := range sub.queues {
+			workers.Add(1)
+			go func(sub *subscriber, q *queue) {
+				defer workers.Done()
+				for {
+					if event, ok := q.pop(); ok {
+						sub.handle(event)
+						continue
+					}
+					select {
+					case <-done:
+						// Drain what was published before stopping
+						for event, ok := q.pop(); ok; event, ok = q.pop() {
+							sub.handle(event)
+						}
+						return
+					case <-q.wake:
+					}
+				}
+			}(sub, q)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			workers.Wait()
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package feed keeps a short history of change events and fans them out to
+// live subscribers, who can resume from the last event they saw
+package feed
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EventReset tells a subscriber that the events after its last event ID
+// are no longer available, so it must reload its state
+const EventReset = "reset"
+
+// subscriberBuffer is the number of events a subscriber may fall behind
+// before it is disconnected
+const subscriberBuffer = 256
+
+// Event is a change published to the feed. IDs increase by one per event.
+type Event struct {
+	ID          uint64          `json:"id"`
+	Type        string          `json:"type"`
+	WorkspaceID string          `json:"workspace_id,omitempty"`
+	BacklogID   string          `json:"backlog_id,omitempty"`
+	StoryID     string          `json:"story_id,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// Filter selects events by backlog or story. An empty filter matches every
+// event; otherwise an event matches when its backlog or its story is listed.
+type Filter struct {
+	BacklogIDs []string
+	StoryIDs   []string
+
+	// WorkspaceID, when set, hides the events of other workspaces
+	WorkspaceID string
+
+	// Allow, when set, hides the events of backlogs the subscriber may not
+	// see. Events outside any backlog are not checked.
+	Allow func(backlogID string) bool
+}
+
+// Matches reports whether an event passes the filter
+func (f Filter) Matches(event Event) bool {
+	if f.WorkspaceID != "" && event.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	if f.Allow != nil && event.BacklogID != "" && !f.Allow(event.BacklogID) {
+		return false
+	}
+	if len(f.BacklogIDs) == 0 && len(f.StoryIDs) == 0 {
+		return true
+	}
+	for _, id := range f.BacklogIDs {
+		if id == event.BacklogID {
+			return true
+		}
+	}
+	for _, id := range f.StoryIDs {
+		if id == event.StoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives the events matching its filter. Events is closed
+// when the subscription is closed or falls too far behind; the subscriber
+// should then reconnect with the ID of the last event it received.
+type Subscription struct {
+	Events <-chan Event
+
+	hub    *Hub
+	filter Filter
+	events chan Event
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.hub.mutex.Lock()
+	defer s.hub.mutex.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub holds the most recent events and the live subscriptions
+type Hub struct {
+	mutex         sync.Mutex
+	history       []Event
+	size          int
+	nextID        uint64
+	subscriptions map[*Subscription]bool
+}
+
+// NewHub creates a hub remembering up to size events for resuming
+func NewHub(size int) *Hub {
+	if size < 1 {
+		size = 1
+	}
+	return &Hub{size: size, nextID: 1, subscriptions: make(map[*Subscription]bool)}
+}
+
+// Publish records an event and sends it to matching subscribers. It never
+// blocks: subscribers that cannot keep up are disconnected.
+func (h *Hub) Publish(eventType, workspaceID, backlogID, storyID string, data json.RawMessage) Event {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	event := Event{
+		ID:          h.nextID,
+		Type:        eventType,
+		WorkspaceID: workspaceID,
+		BacklogID:   backlogID,
+		StoryID:     storyID,
+		OccurredAt:  time.Now(),
+		Data:        data,
+	}
+	h.nextID++
+	h.history = append(h.history, event)
+	if len(h.history) > h.size {
+		h.history = append([]Event{}, h.history[len(h.history)-h.size:]...)
+	}
+
+	for sub := range h.subscriptions {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+	return event
+}
+
+// Subscribe starts a subscription. When resume is set, the events after
+// lastID that are still remembered are delivered first; if some of them
+// were already forgotten, or lastID is from before a restart, a reset event
+// is delivered instead.
+func (h *Hub) Subscribe(filter Filter, lastID uint64, resume bool) *Subscription {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var missed []Event
+	if resume {
+		oldest := h.nextID
+		if len(h.history) > 0 {
+			oldest = h.history[0].ID
+		}
+		if lastID+1 < oldest || lastID >= h.nextID {
+			missed = []Event{{ID: h.nextID - 1, Type: EventReset, OccurredAt: time.Now()}}
+		} else {
+			for _, event := range h.history {
+				if event.ID > lastID && filter.Matches(event) {
+					missed = append(missed, event)
+				}
+			}
+		}
+	}
+
+	events := make(chan Event, subscriberBuffer+len(missed))
+	for _, event := range missed {
+		events <- event
+	}
+	sub := &Subscription{Events: events, hub: h, filter: filter, events: events}
+	h.subscriptions[sub] = true
+	return sub
+}
+
+// LastID returns the ID of the latest event, or 0 before the first one
+func (h *Hub) LastID() uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.nextID - 1
+}
+
+// remove ends a subscription. Callers must hold the lock.
+func (h *Hub) remove(sub *Subscription) {
+	if h.subscriptions[sub] {
+		delete(h.subscriptions, sub)
+		close(sub.events)
+	}
+}
@@ -7,3 +7,13 @@ require (
 	github.com/gorilla/mux v1.8.0
 	github.com/rs/cors v1.10.1
 )
+
+require (
+	github.com/coreos/go-oidc/v3 v3.11.0
+	github.com/golang-jwt/jwt/v5 v5.3.1
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/crypto v0.25.0
+	golang.org/x/oauth2 v0.21.0
+)
+
+require github.com/go-jose/go-jose/v4 v4.0.2 // indirect
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Role handlers
+func (h *Handler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	role, err := h.serviceFor(r).CreateRole(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, role, "")
+}
+
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, true, h.serviceFor(r).GetRoles(), "")
+}
+
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	role, err := h.serviceFor(r).UpdateRole(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, role, "")
+}
+
+func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteRole(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Role deleted successfully"}, "")
+}
+
+// Team handlers
+func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	team, err := h.serviceFor(r).CreateTeam(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, team, "")
+}
+
+func (h *Handler) GetAllTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := h.serviceFor(r).GetAllTeams()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, teams, "")
+}
+
+func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	team, err := h.serviceFor(r).GetTeam(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, team, "")
+}
+
+func (h *Handler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	team, err := h.serviceFor(r).UpdateTeam(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, team, "")
+}
+
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteTeam(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Team deleted successfully"}, "")
+}
+
+// Grant handlers
+func (h *Handler) CreateGrant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	var req models.CreateGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	grant, err := h.serviceFor(r).CreateGrant(backlogID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, grant, "")
+}
+
+func (h *Handler) GetGrantsByBacklog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	grants, err := h.serviceFor(r).GetGrantsByBacklog(backlogID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, grants, "")
+}
+
+func (h *Handler) DeleteGrant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteGrant(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Grant deleted successfully"}, "")
+}
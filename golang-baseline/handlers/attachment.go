@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"golang-baseline/services"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead leaves room for multipart headers and boundaries on top
+// of the largest accepted file
+const multipartOverhead = 1 << 20
+
+// Attachment handlers
+func (h *Handler) UploadStoryAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, models.ItemStory)
+}
+
+func (h *Handler) UploadSubTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) uploadAttachment(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	maxSize := h.serviceFor(r).MaxAttachmentSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(multipartOverhead); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.sendResponse(w, http.StatusRequestEntityTooLarge, false, nil,
+				fmt.Sprintf("Files may not be larger than %d bytes", maxSize))
+			return
+		}
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Missing file field")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.serviceFor(r).CreateAttachment(itemType, itemID, services.UploadRequest{
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		UploadedBy:  r.FormValue("uploaded_by"),
+		Content:     file,
+	})
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, attachment, "")
+}
+
+func (h *Handler) GetStoryAttachments(w http.ResponseWriter, r *http.Request) {
+	h.getAttachments(w, r, models.ItemStory)
+}
+
+func (h *Handler) GetSubTaskAttachments(w http.ResponseWriter, r *http.Request) {
+	h.getAttachments(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) getAttachments(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	attachments, err := h.serviceFor(r).GetAttachments(itemType, itemID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, attachments, "")
+}
+
+func (h *Handler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	attachment, err := h.serviceFor(r).GetAttachment(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, attachment, "")
+}
+
+// DownloadAttachment streams the content of an attachment. Range requests
+// and conditional requests are handled by http.ServeContent.
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	attachment, blob, err := h.serviceFor(r).OpenAttachment(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("ETag", `"`+attachment.Hash+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, attachment.FileName, attachment.CreatedAt, blob)
+}
+
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteAttachment(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Attachment deleted successfully"}, "")
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"golang-baseline/models"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Audit handlers
+
+// GetAuditLog returns audit entries, newest first. Pass the ID of the last
+// entry as "before" to get the next page.
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, h.serviceFor(r).GetAuditLog(filter), "")
+}
+
+// ExportAuditLog writes every matching audit entry as JSON Lines, oldest
+// first
+func (h *Handler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, err.Error())
+		return
+	}
+
+	entries := h.serviceFor(r).ExportAuditLog(filter)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": "audit.jsonl"}))
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return
+		}
+	}
+}
+
+// parseAuditFilter reads the filter parameters of the audit endpoints.
+// Times are RFC 3339.
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		Entity:    query.Get("entity"),
+		EntityID:  query.Get("entity_id"),
+		BacklogID: query.Get("backlog_id"),
+		StoryID:   query.Get("story_id"),
+		RequestID: query.Get("request_id"),
+	}
+
+	var err error
+	if value := query.Get("since"); value != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("since must be an RFC 3339 time")
+		}
+	}
+	if value := query.Get("until"); value != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("until must be an RFC 3339 time")
+		}
+	}
+	if value := query.Get("before"); value != "" {
+		if filter.Before, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return filter, errors.New("before must be an entry ID")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			return filter, errors.New("limit must be a positive number")
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Cookies of single sign-on. The state cookie ties the callback of the
+// provider to the browser that started the login.
+const (
+	sessionCookie = "session"
+	stateCookie   = "oidc_state"
+)
+
+// publicPaths are served without checking credentials
+var publicPaths = map[string]bool{
+	"/":                       true,
+	"/health":                 true,
+	"/api/auth/login":         true,
+	"/api/auth/oidc/login":    true,
+	"/api/auth/oidc/callback": true,
+}
+
+// Authenticate resolves the credentials of a request into its principal,
+// which becomes the actor of the request. Credentials come from the
+// headers, or else from the session cookie of single sign-on. Requests
+// without credentials are refused when authentication is required, and
+// served anonymously otherwise; requests with bad credentials are always
+// refused.
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, _ := r.Context().Value(requestInfoKey{}).(models.RequestInfo)
+		info.Actor = models.ActorAnonymous
+		cookie, _ := r.Cookie(sessionCookie)
+
+		if credential := requestCredential(r); credential != "" && !publicPaths[r.URL.Path] {
+			principal, err := h.service.Authenticate(credential)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				h.sendResponse(w, http.StatusUnauthorized, false, nil, "Invalid credentials")
+				return
+			}
+			info.Principal = principal
+			info.Actor = principal.Username
+		} else if cookie != nil && !publicPaths[r.URL.Path] {
+			principal, err := h.service.AuthenticateSession(cookie.Value)
+			if err != nil {
+				h.clearCookie(w, sessionCookie, "/")
+				h.sendResponse(w, http.StatusUnauthorized, false, nil, "Session expired")
+				return
+			}
+			// Browsers send the cookie with requests other sites trigger,
+			// so changes must come from this site or an allowed origin
+			if !safeMethod(r.Method) && !h.sameOrigin(r) {
+				h.sendResponse(w, http.StatusForbidden, false, nil, "Cross-origin request refused")
+				return
+			}
+			info.Principal = principal
+			info.Actor = principal.Username
+		} else if h.service.AuthRequired() && !publicPaths[r.URL.Path] {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.sendResponse(w, http.StatusUnauthorized, false, nil, "Authentication required")
+			return
+		}
+		if info.Workspace != "" && !h.service.WorkspaceExists(info.Workspace) {
+			h.sendResponse(w, http.StatusBadRequest, false, nil, "Unknown workspace")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+	})
+}
+
+// requestCredential returns the API key or token of a request, from the
+// Authorization bearer or the X-API-Key header. Feed requests may pass it
+// as the access_token parameter, as browsers cannot set headers on
+// EventSource and WebSocket connections.
+func requestCredential(r *http.Request) string {
+	if scheme, credential, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(credential)
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/feed/") {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+// safeMethod reports whether a request method does not change anything
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// sameOrigin reports whether a request comes from the server's own pages
+// or an origin allowed by the CORS policy. Requests without an Origin
+// header are not made by scripts of another site.
+func (h *Handler) sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if parsed, err := url.Parse(origin); err == nil && parsed.Host == r.Host {
+		return true
+	}
+	return h.originAllowed(r)
+}
+
+// setCookie sets an HttpOnly cookie, limited to HTTPS unless the server
+// is configured for local development
+func (h *Handler) setCookie(w http.ResponseWriter, name, value, path string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.service.SecureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearCookie removes a cookie set by setCookie
+func (h *Handler) clearCookie(w http.ResponseWriter, name, path string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     path,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.service.SecureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Auth handlers
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	login, err := h.serviceFor(r).Login(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, login, "")
+}
+
+// GetPrincipal returns who the request is authenticated as
+func (h *Handler) GetPrincipal(w http.ResponseWriter, r *http.Request) {
+	info, _ := r.Context().Value(requestInfoKey{}).(models.RequestInfo)
+	if info.Principal == nil {
+		h.sendResponse(w, http.StatusUnauthorized, false, nil, "Not authenticated")
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, info.Principal, "")
+}
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	key, err := h.serviceFor(r).CreateAPIKey(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, key, "")
+}
+
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.serviceFor(r).GetAPIKeys()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, keys, "")
+}
+
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteAPIKey(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "API key deleted successfully"}, "")
+}
+
+// OIDCLogin sends the browser to the login page of the identity provider.
+// The redirect parameter is the local path to return to once signed in.
+func (h *Handler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	login, err := h.serviceFor(r).BeginOIDCLogin(r.Context(), r.URL.Query().Get("redirect"))
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.setCookie(w, stateCookie, login.State, "/api/auth/oidc", time.Now().Add(10*time.Minute))
+	http.Redirect(w, r, login.URL, http.StatusFound)
+}
+
+// OIDCCallback completes a login when the identity provider sends the
+// browser back, starts a session and returns to where the login started
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	h.clearCookie(w, stateCookie, "/api/auth/oidc")
+	if providerError := query.Get("error"); providerError != "" {
+		message := "Sign-in failed: " + providerError
+		if description := query.Get("error_description"); description != "" {
+			message += " (" + description + ")"
+		}
+		h.sendResponse(w, http.StatusUnauthorized, false, nil, message)
+		return
+	}
+	state := query.Get("state")
+	if cookie, err := r.Cookie(stateCookie); err != nil || state == "" || cookie.Value != state {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "The sign-in was not started by this browser")
+		return
+	}
+
+	login, err := h.serviceFor(r).CompleteOIDCLogin(r.Context(), state, query.Get("code"))
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.setCookie(w, sessionCookie, login.SessionID, "/", login.ExpiresAt)
+	http.Redirect(w, r, login.Redirect, http.StatusFound)
+}
+
+// Logout ends the browser session of the request
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		h.service.Logout(cookie.Value)
+	}
+	h.clearCookie(w, sessionCookie, "/")
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Logged out successfully"}, "")
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"golang-baseline/config"
+	"golang-baseline/models"
+	"golang-baseline/services"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubProvider is an OpenID Connect provider serving discovery, its signing
+// keys and the token endpoint. Authorization is done by the tests, which
+// call authorize with the login URL the browser was sent to.
+type stubProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mutex sync.Mutex
+	codes map[string]stubCode
+	// nonce replaces the nonce of the next ID tokens when set
+	nonce string
+}
+
+// stubCode is an authorization code waiting to be exchanged
+type stubCode struct {
+	challenge string
+	nonce     string
+	claims    map[string]interface{}
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{key: key, codes: make(map[string]stubCode)}
+	p.Server = httptest.NewServer(p)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *stubProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.URL,
+			"authorization_endpoint":                p.URL + "/authorize",
+			"token_endpoint":                        p.URL + "/token",
+			"jwks_uri":                              p.URL + "/keys",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	case "/keys":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+			}},
+		})
+	case "/token":
+		p.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// token exchanges a code for an ID token, once, when the code verifier
+// matches the challenge of the login
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	p.mutex.Lock()
+	code, exists := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	nonce := p.nonce
+	p.mutex.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !exists || base64.RawURLEncoding.EncodeToString(verifier[:]) != code.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if nonce == "" {
+		nonce = code.nonce
+	}
+	claims := map[string]interface{}{
+		"iss":   p.URL,
+		"aud":   "client",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	}
+	for name, value := range code.claims {
+		claims[name] = value
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.sign(claims),
+	})
+}
+
+// sign returns an RS256 JWT of claims
+func (p *stubProvider) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "test"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// authorize signs a user in at the login URL and returns the code the
+// provider calls back with
+func (p *stubProvider) authorize(t *testing.T, loginURL string, claims map[string]interface{}) string {
+	t.Helper()
+	parsed, err := url.Parse(loginURL)
+	if err != nil || !strings.HasPrefix(loginURL, p.URL+"/authorize?") {
+		t.Fatalf("login URL = %q", loginURL)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" ||
+		query.Get("client_id") != "client" || !strings.Contains(query.Get("scope"), "openid") {
+		t.Fatalf("login URL = %q", loginURL)
+	}
+	code := fmt.Sprintf("code-%d", time.Now().UnixNano())
+	p.mutex.Lock()
+	p.codes[code] = stubCode{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), claims: claims}
+	p.mutex.Unlock()
+	return code
+}
+
+func newOIDCTestHandler(t *testing.T, provider *stubProvider) (*Handler, *services.Service) {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.AttachmentDir = t.TempDir()
+	cfg.OIDCIssuer = provider.URL
+	cfg.OIDCClientID = "client"
+	cfg.OIDCClientSecret = "secret"
+	cfg.OIDCGroupRoles = []string{"platform=admin", "developers=editor", "support=viewer"}
+	service, err := services.NewService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHandler(service, func(*http.Request) bool { return false }), service
+}
+
+// cookie returns the cookie of a response by name
+func cookie(t *testing.T, rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %s cookie in %v", name, rec.Result().Header)
+	return nil
+}
+
+// beginLogin starts a login and returns the URL of the provider and the
+// state cookie
+func beginLogin(t *testing.T, h *Handler) (string, *http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.OIDCLogin(rec, httptest.NewRequest("GET", "/api/auth/oidc/login?redirect=/boards", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d: %s", rec.Code, rec.Body)
+	}
+	return rec.Header().Get("Location"), cookie(t, rec, stateCookie)
+}
+
+// callback sends the browser back from the provider
+func callback(h *Handler, state *http.Cookie, code string) *httptest.ResponseRecorder {
+	query := url.Values{"state": {state.Value}, "code": {code}}
+	r := httptest.NewRequest("GET", "/api/auth/oidc/callback?"+query.Encode(), nil)
+	r.AddCookie(&http.Cookie{Name: state.Name, Value: state.Value})
+	rec := httptest.NewRecorder()
+	h.OIDCCallback(rec, r)
+	return rec
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := newStubProvider(t)
+	h, service := newOIDCTestHandler(t, provider)
+
+	loginURL, state := beginLogin(t, h)
+	if !state.HttpOnly || !state.Secure || state.SameSite != http.SameSiteLaxMode || state.Path != "/api/auth/oidc" {
+		t.Errorf("state cookie = %+v", state)
+	}
+	code := provider.authorize(t, loginURL, map[string]interface{}{
+		"sub":                "ada-1",
+		"preferred_username": "ada",
+		"email":              "ada@example.com",
+		"name":               "Ada Lovelace",
+		"groups":             []string{"developers", "platform"},
+	})
+	rec := callback(h, state, code)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/boards" {
+		t.Fatalf("callback status = %d, location %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if cleared := cookie(t, rec, stateCookie); cleared.MaxAge >= 0 {
+		t.Errorf("state cookie not cleared: %+v", cleared)
+	}
+	session := cookie(t, rec, sessionCookie)
+	if !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteLaxMode || session.Path != "/" ||
+		!session.Expires.After(time.Now()) {
+		t.Errorf("session cookie = %+v", session)
+	}
+
+	// The first login provisions the user with the roles of its groups
+	principal, err := service.AuthenticateSession(session.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Username != "ada" || principal.Method != models.AuthSession || principal.WorkspaceID != models.DefaultWorkspace {
+		t.Errorf("principal = %+v", principal)
+	}
+	user, err := service.GetUser(principal.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.Admin || len(user.Roles) != 1 || user.Roles[0] != models.RoleEditor || user.Email != "ada@example.com" ||
+		user.DisplayName != "Ada Lovelace" || user.SSOSubject != provider.URL+" ada-1" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+
+	// Later logins update the same user, following its groups; a single
+	// group may be sent as a string
+	loginURL, state = beginLogin(t, h)
+	code = provider.authorize(t, loginURL, map[string]interface{}{
+		"sub":    "ada-1",
+		"name":   "Ada King",
+		"groups": "support",
+	})
+	if rec := callback(h, state, code); rec.Code != http.StatusFound {
+		t.Fatalf("second callback status = %d: %s", rec.Code, rec.Body)
+	}
+	users, _ := service.GetAllUsers()
+	if len(users) != 1 || users[0].ID != user.ID || users[0].Admin || len(users[0].Roles) != 1 ||
+		users[0].Roles[0] != models.RoleViewer || users[0].DisplayName != "Ada King" {
+		t.Errorf("users = %+v", users)
+	}
+	// Users returned earlier are copies, left as they were
+	if !user.Admin || user.DisplayName != "Ada Lovelace" {
+		t.Errorf("returned user changed: %+v", user)
+	}
+}
+
+func TestOIDCLoginRefused(t *testing.T) {
+	provider := newStubProvider(t)
+	h, service := newOIDCTestHandler(t, provider)
+	claims := map[string]interface{}{"sub": "alan-1", "preferred_username": "alan"}
+
+	t.Run("state mismatch", func(t *testing.T) {
+		loginURL, state := beginLogin(t, h)
+		code := provider.authorize(t, loginURL, claims)
+		query := url.Values{"state": {state.Value}, "code": {code}}
+		r := httptest.NewRequest("GET", "/api/auth/oidc/callback?"+query.Encode(), nil)
+		r.AddCookie(&http.Cookie{Name: stateCookie, Value: "another login"})
+		rec := httptest.NewRecorder()
+		h.OIDCCallback(rec, r)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("replayed state", func(t *testing.T) {
+		loginURL, state := beginLogin(t, h)
+		if rec := callback(h, state, provider.authorize(t, loginURL, claims)); rec.Code != http.StatusFound {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		if rec := callback(h, state, provider.authorize(t, loginURL, claims)); rec.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("PKCE verifier mismatch", func(t *testing.T) {
+		loginURL, state := beginLogin(t, h)
+		code := provider.authorize(t, loginURL, claims)
+		provider.mutex.Lock()
+		provider.codes[code] = stubCode{challenge: "another challenge", claims: claims}
+		provider.mutex.Unlock()
+		rec := callback(h, state, code)
+		if rec.Code != http.StatusBadGateway || strings.Contains(rec.Header().Get("Set-Cookie"), sessionCookie+"=") {
+			t.Errorf("status = %d: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		provider.mutex.Lock()
+		provider.nonce = "another nonce"
+		provider.mutex.Unlock()
+		defer func() {
+			provider.mutex.Lock()
+			provider.nonce = ""
+			provider.mutex.Unlock()
+		}()
+		loginURL, state := beginLogin(t, h)
+		rec := callback(h, state, provider.authorize(t, loginURL, claims))
+		if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "nonce") {
+			t.Errorf("status = %d: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("local username", func(t *testing.T) {
+		if _, err := service.CreateUser(models.CreateUserRequest{Username: "grace"}); err != nil {
+			t.Fatal(err)
+		}
+		loginURL, state := beginLogin(t, h)
+		rec := callback(h, state, provider.authorize(t, loginURL, map[string]interface{}{"sub": "grace-1", "preferred_username": "grace"}))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d: %s", rec.Code, rec.Body)
+		}
+	})
+}
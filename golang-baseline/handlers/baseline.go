@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Baseline handlers
+func (h *Handler) CreateBaseline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	var req models.CreateBaselineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	baseline, err := h.serviceFor(r).CreateBaseline(backlogID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, baseline, "")
+}
+
+func (h *Handler) GetBaselinesByBacklog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	baselines, err := h.serviceFor(r).GetBaselinesByBacklog(backlogID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, baselines, "")
+}
+
+func (h *Handler) GetBaseline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	baseline, err := h.serviceFor(r).GetBaseline(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, baseline, "")
+}
+
+func (h *Handler) DeleteBaseline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteBaseline(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Baseline deleted successfully"}, "")
+}
+
+func (h *Handler) CompareBaseline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	comparison, err := h.serviceFor(r).CompareBaseline(id, r.URL.Query().Get("against"))
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, comparison, "")
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Board handlers
+func (h *Handler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+	swimlane := strings.ToLower(r.URL.Query().Get("swimlane"))
+
+	board, err := h.serviceFor(r).GetBoard(backlogID, swimlane, parseListFilter(r))
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, board, "")
+}
+
+func (h *Handler) UpdateWIPLimits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	var req models.UpdateWIPLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	limits, err := h.serviceFor(r).UpdateWIPLimits(backlogID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, limits, "")
+}
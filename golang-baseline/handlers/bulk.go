@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+)
+
+// Bulk handler
+func (h *Handler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	result, err := h.serviceFor(r).Bulk(req)
+	if err != nil {
+		// A rejected atomic request still reports the outcome of every item
+		var data interface{}
+		if result != nil {
+			data = result
+		}
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, data, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, result, "")
+}
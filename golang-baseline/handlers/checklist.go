@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Checklist handlers
+func (h *Handler) AddStoryChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.addChecklistItem(w, r, models.ItemStory)
+}
+
+func (h *Handler) AddSubTaskChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.addChecklistItem(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) addChecklistItem(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	var req models.CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	item, err := h.serviceFor(r).AddChecklistItem(itemType, itemID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, item, "")
+}
+
+func (h *Handler) UpdateStoryChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.updateChecklistItem(w, r, models.ItemStory)
+}
+
+func (h *Handler) UpdateSubTaskChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.updateChecklistItem(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) updateChecklistItem(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+
+	var req models.UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	item, err := h.serviceFor(r).UpdateChecklistItem(itemType, vars["id"], vars["itemId"], req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, item, "")
+}
+
+func (h *Handler) DeleteStoryChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.deleteChecklistItem(w, r, models.ItemStory)
+}
+
+func (h *Handler) DeleteSubTaskChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.deleteChecklistItem(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) deleteChecklistItem(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+
+	if err := h.serviceFor(r).DeleteChecklistItem(itemType, vars["id"], vars["itemId"]); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Checklist item deleted successfully"}, "")
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Comment handlers
+func (h *Handler) CreateStoryComment(w http.ResponseWriter, r *http.Request) {
+	h.createComment(w, r, models.ItemStory)
+}
+
+func (h *Handler) CreateSubTaskComment(w http.ResponseWriter, r *http.Request) {
+	h.createComment(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) createComment(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	comment, err := h.serviceFor(r).CreateComment(itemType, itemID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, comment, "")
+}
+
+func (h *Handler) GetStoryComments(w http.ResponseWriter, r *http.Request) {
+	h.getComments(w, r, models.ItemStory)
+}
+
+func (h *Handler) GetSubTaskComments(w http.ResponseWriter, r *http.Request) {
+	h.getComments(w, r, models.ItemSubTask)
+}
+
+func (h *Handler) getComments(w http.ResponseWriter, r *http.Request, itemType models.ItemType) {
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	comments, err := h.serviceFor(r).GetComments(itemType, itemID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, comments, "")
+}
+
+func (h *Handler) GetComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	comment, err := h.serviceFor(r).GetComment(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, comment, "")
+}
+
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	comment, err := h.serviceFor(r).UpdateComment(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, comment, "")
+}
+
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteComment(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Comment deleted successfully"}, "")
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Custom field handlers
+func (h *Handler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	var req models.CreateCustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	field, err := h.serviceFor(r).CreateCustomField(backlogID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, field, "")
+}
+
+func (h *Handler) GetCustomFieldsByBacklog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	fields, err := h.serviceFor(r).GetCustomFieldsByBacklog(backlogID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, fields, "")
+}
+
+func (h *Handler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateCustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	field, err := h.serviceFor(r).UpdateCustomField(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, field, "")
+}
+
+func (h *Handler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteCustomField(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Custom field deleted successfully"}, "")
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Epic handlers
+func (h *Handler) CreateEpic(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateEpicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	epic, err := h.serviceFor(r).CreateEpic(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, epic, "")
+}
+
+func (h *Handler) GetAllEpics(w http.ResponseWriter, r *http.Request) {
+	epics, err := h.serviceFor(r).GetAllEpics()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, epics, "")
+}
+
+func (h *Handler) GetEpic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	epic, err := h.serviceFor(r).GetEpic(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, epic, "")
+}
+
+func (h *Handler) UpdateEpic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateEpicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	epic, err := h.serviceFor(r).UpdateEpic(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, epic, "")
+}
+
+func (h *Handler) DeleteEpic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteEpic(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Epic deleted successfully"}, "")
+}
+
+func (h *Handler) AddStoriesToEpic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.MembershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	epic, err := h.serviceFor(r).AddStoriesToEpic(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, epic, "")
+}
+
+func (h *Handler) RemoveStoryFromEpic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	epic, err := h.serviceFor(r).RemoveStoryFromEpic(vars["id"], vars["storyId"])
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, epic, "")
+}
+
+// Milestone handlers
+func (h *Handler) CreateMilestone(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMilestoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	milestone, err := h.serviceFor(r).CreateMilestone(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, milestone, "")
+}
+
+func (h *Handler) GetAllMilestones(w http.ResponseWriter, r *http.Request) {
+	milestones, err := h.serviceFor(r).GetAllMilestones()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, milestones, "")
+}
+
+func (h *Handler) GetMilestone(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	milestone, err := h.serviceFor(r).GetMilestone(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, milestone, "")
+}
+
+func (h *Handler) UpdateMilestone(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateMilestoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	milestone, err := h.serviceFor(r).UpdateMilestone(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, milestone, "")
+}
+
+func (h *Handler) DeleteMilestone(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteMilestone(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Milestone deleted successfully"}, "")
+}
+
+func (h *Handler) AddStoriesToMilestone(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.MembershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	milestone, err := h.serviceFor(r).AddStoriesToMilestone(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, milestone, "")
+}
+
+func (h *Handler) RemoveStoryFromMilestone(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	milestone, err := h.serviceFor(r).RemoveStoryFromMilestone(vars["id"], vars["storyId"])
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, milestone, "")
+}
+
+func (h *Handler) GetReleaseReadiness(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	readiness, err := h.serviceFor(r).GetReleaseReadiness(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, readiness, "")
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-baseline/feed"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// feedWriteTimeout bounds every write to a feed connection
+const feedWriteTimeout = 10 * time.Second
+
+// Change feed handlers
+
+// StreamFeed streams changes as Server-Sent Events. Browsers reconnecting
+// with EventSource send Last-Event-ID and receive the events they missed.
+func (h *Handler) StreamFeed(w http.ResponseWriter, r *http.Request) {
+	filter, lastID, resume, err := parseFeedRequest(r)
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, err.Error())
+		return
+	}
+
+	// The stream outlives the server write timeout, so each write gets its
+	// own deadline instead
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(format string, args ...interface{}) error {
+		rc.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	if err := write("retry: 3000\n\n"); err != nil {
+		return
+	}
+
+	sub := h.serviceFor(r).SubscribeFeed(filter, lastID, resume)
+	defer sub.Close()
+	heartbeat := time.NewTicker(h.serviceFor(r).FeedHeartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Fell behind; the client reconnects and resumes
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err := write("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// FeedSocket streams changes over a WebSocket, one JSON event per message.
+// Heartbeats are ping frames; clients that stop answering are dropped.
+func (h *Handler) FeedSocket(w http.ResponseWriter, r *http.Request) {
+	filter, lastID, resume, err := parseFeedRequest(r)
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, err.Error())
+		return
+	}
+
+	// Browsers do not apply CORS to WebSockets, so the handshake checks the
+	// origin against the same policy
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "" || h.originAllowed(r)
+		},
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+
+	sub := h.serviceFor(r).SubscribeFeed(filter, lastID, resume)
+	defer sub.Close()
+	interval := h.serviceFor(r).FeedHeartbeat()
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	// Clients only send control frames; reading processes them and notices
+	// when the connection goes away
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(2 * interval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * interval))
+	})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind, resume from the last event ID")
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(feedWriteTimeout))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseFeedRequest reads the subscription of a feed request. Backlogs and
+// stories may be repeated or comma separated; the last event ID comes from
+// the Last-Event-ID header or the last_event_id parameter.
+func parseFeedRequest(r *http.Request) (feed.Filter, uint64, bool, error) {
+	query := r.URL.Query()
+	filter := feed.Filter{
+		BacklogIDs: queryValues(query["backlog_id"]),
+		StoryIDs:   queryValues(query["story_id"]),
+	}
+
+	last := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if last == "" {
+		last = query.Get("last_event_id")
+	}
+	if last == "" {
+		return filter, 0, false, nil
+	}
+	lastID, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return filter, 0, false, fmt.Errorf("last event ID must be a number")
+	}
+	return filter, lastID, true, nil
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Gantt handler
+func (h *Handler) GetGanttChart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	chart, err := h.serviceFor(r).GetGanttChart(backlogID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, chart, "")
+}
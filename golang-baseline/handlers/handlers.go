@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"golang-baseline/models"
 	"golang-baseline/services"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -12,12 +14,15 @@ import (
 // Handler contains the service and handles HTTP requests
 type Handler struct {
 	service *services.Service
+	// originAllowed applies the CORS policy to WebSocket handshakes
+	originAllowed func(r *http.Request) bool
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(service *services.Service) *Handler {
+func NewHandler(service *services.Service, originAllowed func(r *http.Request) bool) *Handler {
 	return &Handler{
-		service: service,
+		service:       service,
+		originAllowed: originAllowed,
 	}
 }
 
@@ -42,6 +47,78 @@ func (h *Handler) sendResponse(w http.ResponseWriter, statusCode int, success bo
 	json.NewEncoder(w).Encode(response)
 }
 
+// errorStatus maps service errors to HTTP status codes, falling back to the
+// given code for errors without a more specific meaning
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidStatus), errors.Is(err, services.ErrInvalidLink),
+		errors.Is(err, services.ErrInvalidCalendar), errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrDuplicateLink), errors.Is(err, services.ErrDependencyCycle),
+		errors.Is(err, services.ErrBlocked), errors.Is(err, services.ErrChecklistIncomplete),
+		errors.Is(err, services.ErrWIPLimit), errors.Is(err, services.ErrBulkFailed):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidAttachment):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrUpstream):
+		return http.StatusBadGateway
+	case errors.Is(err, services.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return fallback
+}
+
+// statusUpdatedResponse builds the payload returned by status update handlers
+func statusUpdatedResponse(warnings []string) map[string]interface{} {
+	data := map[string]interface{}{"message": "Status updated successfully"}
+	if len(warnings) > 0 {
+		data["warnings"] = warnings
+	}
+	return data
+}
+
+// parseListFilter reads the filter and sort parameters of list endpoints.
+// Multi-valued parameters may be repeated or comma separated, and custom
+// fields are filtered with "cf.<key>=<value>".
+func parseListFilter(r *http.Request) models.ListFilter {
+	query := r.URL.Query()
+	filter := models.ListFilter{
+		Labels: queryValues(query["label"]),
+		Sort:   query.Get("sort"),
+	}
+	for _, priority := range queryValues(query["priority"]) {
+		filter.Priorities = append(filter.Priorities, models.Priority(strings.ToUpper(priority)))
+	}
+	filter.Query = query.Get("q")
+	for param, values := range query {
+		if key := strings.TrimPrefix(param, models.SortCustomFieldPrefix); key != param && len(values) > 0 {
+			if filter.CustomFields == nil {
+				filter.CustomFields = make(map[string]string)
+			}
+			filter.CustomFields[key] = values[0]
+		}
+	}
+	return filter
+}
+
+// queryValues splits comma separated query values and drops empty ones
+func queryValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
 // Health check endpoint
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	h.sendResponse(w, http.StatusOK, true, map[string]string{"status": "healthy"}, "")
@@ -55,9 +132,9 @@ func (h *Handler) CreateBacklog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	backlog, err := h.service.CreateBacklog(req)
+	backlog, err := h.serviceFor(r).CreateBacklog(req)
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
@@ -68,9 +145,9 @@ func (h *Handler) GetBacklog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	backlog, err := h.service.GetBacklog(id)
+	backlog, err := h.serviceFor(r).GetBacklog(id)
 	if err != nil {
-		h.sendResponse(w, http.StatusNotFound, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
 		return
 	}
 
@@ -78,9 +155,9 @@ func (h *Handler) GetBacklog(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetAllBacklogs(w http.ResponseWriter, r *http.Request) {
-	backlogs, err := h.service.GetAllBacklogs()
+	backlogs, err := h.serviceFor(r).GetAllBacklogs()
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
@@ -95,9 +172,9 @@ func (h *Handler) CreateStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	story, err := h.service.CreateStory(req)
+	story, err := h.serviceFor(r).CreateStory(req)
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
@@ -108,9 +185,9 @@ func (h *Handler) GetStory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	story, err := h.service.GetStory(id)
+	story, err := h.serviceFor(r).GetStory(id)
 	if err != nil {
-		h.sendResponse(w, http.StatusNotFound, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
 		return
 	}
 
@@ -121,15 +198,46 @@ func (h *Handler) GetStoriesByBacklog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	backlogID := vars["backlogId"]
 
-	stories, err := h.service.GetStoriesByBacklog(backlogID)
+	stories, err := h.serviceFor(r).GetStoriesByBacklog(backlogID, parseListFilter(r))
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
 	h.sendResponse(w, http.StatusOK, true, stories, "")
 }
 
+func (h *Handler) UpdateStory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateStoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	story, err := h.serviceFor(r).UpdateStory(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, story, "")
+}
+
+func (h *Handler) DeleteStory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteStory(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Story deleted successfully"}, "")
+}
+
 // SubTask handlers
 func (h *Handler) CreateSubTask(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateSubTaskRequest
@@ -138,9 +246,9 @@ func (h *Handler) CreateSubTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subtask, err := h.service.CreateSubTask(req)
+	subtask, err := h.serviceFor(r).CreateSubTask(req)
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
@@ -151,9 +259,9 @@ func (h *Handler) GetSubTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	subtask, err := h.service.GetSubTask(id)
+	subtask, err := h.serviceFor(r).GetSubTask(id)
 	if err != nil {
-		h.sendResponse(w, http.StatusNotFound, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
 		return
 	}
 
@@ -164,15 +272,46 @@ func (h *Handler) GetSubTasksByStory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	storyID := vars["storyId"]
 
-	subtasks, err := h.service.GetSubTasksByStory(storyID)
+	subtasks, err := h.serviceFor(r).GetSubTasksByStory(storyID, parseListFilter(r))
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
 	h.sendResponse(w, http.StatusOK, true, subtasks, "")
 }
 
+func (h *Handler) UpdateSubTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateSubTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	subtask, err := h.serviceFor(r).UpdateSubTask(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, subtask, "")
+}
+
+func (h *Handler) DeleteSubTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteSubTask(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Subtask deleted successfully"}, "")
+}
+
 // Status update handlers
 func (h *Handler) UpdateStoryStatus(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -184,13 +323,13 @@ func (h *Handler) UpdateStoryStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.UpdateStoryStatus(id, req.Status)
+	warnings, err := h.serviceFor(r).UpdateStoryStatus(id, req.Status)
 	if err != nil {
-		h.sendResponse(w, http.StatusNotFound, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
 		return
 	}
 
-	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Status updated successfully"}, "")
+	h.sendResponse(w, http.StatusOK, true, statusUpdatedResponse(warnings), "")
 }
 
 func (h *Handler) UpdateSubTaskStatus(w http.ResponseWriter, r *http.Request) {
@@ -203,20 +342,20 @@ func (h *Handler) UpdateSubTaskStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.UpdateSubTaskStatus(id, req.Status)
+	warnings, err := h.serviceFor(r).UpdateSubTaskStatus(id, req.Status)
 	if err != nil {
-		h.sendResponse(w, http.StatusNotFound, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
 		return
 	}
 
-	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Status updated successfully"}, "")
+	h.sendResponse(w, http.StatusOK, true, statusUpdatedResponse(warnings), "")
 }
 
 // Dashboard handler
 func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.service.GetDashboardStats()
+	stats, err := h.serviceFor(r).GetDashboardStats()
 	if err != nil {
-		h.sendResponse(w, http.StatusInternalServerError, false, nil, err.Error())
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
 		return
 	}
 
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+)
+
+// Import handler
+func (h *Handler) ImportBacklog(w http.ResponseWriter, r *http.Request) {
+	var doc models.ImportDocument
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	result, err := h.serviceFor(r).ImportBacklog(doc)
+	if err != nil {
+		// A rejected document still reports every problem found in it
+		var data interface{}
+		if result != nil {
+			data = result
+		}
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, data, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, result, "")
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Jira handlers
+func (h *Handler) ImportJiraProject(w http.ResponseWriter, r *http.Request) {
+	var req models.JiraImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	result, err := h.serviceFor(r).ImportJiraProject(r.Context(), req)
+	if err != nil {
+		// A rejected import still reports every problem found in it
+		var data interface{}
+		if result != nil {
+			data = result
+		}
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, data, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, result, "")
+}
+
+func (h *Handler) SyncJira(w http.ResponseWriter, r *http.Request) {
+	report, err := h.serviceFor(r).SyncJira(r.Context())
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, report, "")
+}
+
+func (h *Handler) GetJiraSyncReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.serviceFor(r).GetJiraSyncReport()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, report, "")
+}
+
+func (h *Handler) GetJiraLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.serviceFor(r).GetJiraLinks()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, links, "")
+}
+
+func (h *Handler) GetJiraConflicts(w http.ResponseWriter, r *http.Request) {
+	conflicts, err := h.serviceFor(r).GetJiraConflicts()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, conflicts, "")
+}
+
+func (h *Handler) ResolveJiraConflict(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.ResolveJiraConflictRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	link, err := h.serviceFor(r).ResolveJiraConflict(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, link, "")
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Link handlers
+func (h *Handler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	link, err := h.serviceFor(r).CreateLink(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, true, link, "")
+}
+
+func (h *Handler) GetStoryLinks(w http.ResponseWriter, r *http.Request) {
+	h.getLinks(w, r, models.ItemStory, mux.Vars(r)["id"])
+}
+
+func (h *Handler) GetSubTaskLinks(w http.ResponseWriter, r *http.Request) {
+	h.getLinks(w, r, models.ItemSubTask, mux.Vars(r)["id"])
+}
+
+func (h *Handler) getLinks(w http.ResponseWriter, r *http.Request, itemType models.ItemType, id string) {
+	links, err := h.serviceFor(r).GetLinks(itemType, id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, links, "")
+}
+
+func (h *Handler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).DeleteLink(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Link deleted successfully"}, "")
+}
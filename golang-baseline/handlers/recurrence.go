@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Recurrence handlers
+func (h *Handler) GetAllRecurrences(w http.ResponseWriter, r *http.Request) {
+	recurrences, err := h.serviceFor(r).GetAllRecurrences()
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusInternalServerError), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, recurrences, "")
+}
+
+func (h *Handler) GetRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	recurrence, err := h.serviceFor(r).GetRecurrence(id)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, recurrence, "")
+}
+
+func (h *Handler) SetRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.SetRecurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	recurrence, err := h.serviceFor(r).SetRecurrence(id, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, recurrence, "")
+}
+
+func (h *Handler) StopRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.serviceFor(r).StopRecurrence(id); err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, map[string]string{"message": "Recurrence stopped successfully"}, "")
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"golang-baseline/models"
+	"golang-baseline/services"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// requestIDPattern accepts request IDs from clients and proxies that are
+// safe to log and echo back
+var requestIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._:-]{1,128}$`)
+
+// requestInfoKey is the context key of the models.RequestInfo of a request
+type requestInfoKey struct{}
+
+// WithRequestInfo identifies every request before passing it on: its ID,
+// taken from X-Request-ID or generated and echoed back in that header, its
+// source IP and the workspace named by X-Workspace-ID. When proxyHops is
+// positive the requests come through that many trusted reverse proxies and
+// the source IP is taken from X-Forwarded-For. Authenticate adds the actor.
+func WithRequestInfo(next http.Handler, proxyHops int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := models.RequestInfo{
+			RequestID: r.Header.Get("X-Request-ID"),
+			SourceIP:  sourceIP(r, proxyHops),
+			Workspace: strings.TrimSpace(r.Header.Get("X-Workspace-ID")),
+		}
+		if !requestIDPattern.MatchString(info.RequestID) {
+			info.RequestID = uuid.New().String()
+		}
+		w.Header().Set("X-Request-ID", info.RequestID)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+	})
+}
+
+// sourceIP returns the address of the client of a request. Every proxy
+// appends the address it was called from to X-Forwarded-For, and clients
+// may send the header with any addresses they like, so only the last
+// proxyHops entries can be trusted; the earliest of them is the client.
+func sourceIP(r *http.Request, proxyHops int) string {
+	if proxyHops > 0 {
+		var forwarded []string
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			forwarded = append(forwarded, strings.Split(value, ",")...)
+		}
+		if len(forwarded) > 0 {
+			ip := strings.TrimSpace(forwarded[max(len(forwarded)-proxyHops, 0)])
+			if net.ParseIP(ip) != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// serviceFor returns the service view that attributes changes to the
+// request
+func (h *Handler) serviceFor(r *http.Request) *services.Service {
+	info, _ := r.Context().Value(requestInfoKey{}).(models.RequestInfo)
+	return h.service.WithRequest(info)
+}
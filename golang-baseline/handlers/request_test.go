@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		forwarded []string
+		proxyHops int
+		want      string
+	}{
+		{"no proxy", []string{"203.0.113.7"}, 0, "192.0.2.1"},
+		{"one proxy", []string{"203.0.113.7"}, 1, "203.0.113.7"},
+		{"forged by the client", []string{"10.0.0.1, 203.0.113.7"}, 1, "203.0.113.7"},
+		{"two proxies", []string{"10.0.0.1, 203.0.113.7, 198.51.100.2"}, 2, "203.0.113.7"},
+		{"repeated header", []string{"10.0.0.1", "203.0.113.7"}, 1, "203.0.113.7"},
+		{"fewer entries than proxies", []string{"203.0.113.7"}, 3, "203.0.113.7"},
+		{"not an address", []string{"unknown"}, 1, "192.0.2.1"},
+		{"no header", nil, 1, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "192.0.2.1:4321"
+		for _, value := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := sourceIP(r, tt.proxyHops); got != tt.want {
+			t.Errorf("%s: sourceIP() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
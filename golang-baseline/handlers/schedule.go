@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Calendar handlers
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, true, h.serviceFor(r).GetCalendar(), "")
+}
+
+func (h *Handler) UpdateCalendar(w http.ResponseWriter, r *http.Request) {
+	var req models.Calendar
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	calendar, err := h.serviceFor(r).UpdateCalendar(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusBadRequest), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, calendar, "")
+}
+
+// Capacity handlers
+func (h *Handler) GetCapacities(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, true, h.serviceFor(r).GetCapacities(), "")
+}
+
+func (h *Handler) SetCapacity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req models.Capacity
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+	req.PIC = vars["pic"]
+
+	capacity, err := h.serviceFor(r).SetCapacity(req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusBadRequest), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, capacity, "")
+}
+
+// Scheduling handler
+func (h *Handler) ScheduleBacklog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	var req models.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid request body")
+		return
+	}
+
+	result, err := h.serviceFor(r).ScheduleBacklog(backlogID, req)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, result, "")
+}
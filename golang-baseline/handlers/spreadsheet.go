@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"golang-baseline/spreadsheet"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxSheetSize is the largest spreadsheet accepted for import
+const maxSheetSize = 10 << 20
+
+// Spreadsheet handlers
+func (h *Handler) ExportBacklogSheet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Format must be csv or xlsx")
+		return
+	}
+
+	rows, err := h.serviceFor(r).ExportBacklogSheet(backlogID)
+	if err != nil {
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, nil, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", spreadsheet.ContentType(format))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": fmt.Sprintf("backlog-%s.%s", backlogID, format)}))
+	spreadsheet.Write(w, format, rows)
+}
+
+// ImportBacklogSheet accepts the file either as the "file" field of a
+// multipart form or as the raw request body. Options come from form fields
+// or the query string: format, mode, preview and mapping (a JSON object).
+func (h *Handler) ImportBacklogSheet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	backlogID := vars["id"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSheetSize+multipartOverhead)
+	var data []byte
+	var fileName string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(multipartOverhead); err != nil {
+			h.sendSheetReadError(w, err, "Invalid multipart form")
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			h.sendResponse(w, http.StatusBadRequest, false, nil, "Missing file field")
+			return
+		}
+		defer file.Close()
+		fileName = header.Filename
+		if data, err = io.ReadAll(file); err != nil {
+			h.sendSheetReadError(w, err, "Invalid file")
+			return
+		}
+	} else {
+		var err error
+		if data, err = io.ReadAll(r.Body); err != nil {
+			h.sendSheetReadError(w, err, "Invalid request body")
+			return
+		}
+	}
+
+	format := strings.ToLower(r.FormValue("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	}
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Format must be csv or xlsx")
+		return
+	}
+
+	opts := models.SheetImportOptions{Mode: strings.ToLower(r.FormValue("mode"))}
+	if preview := r.FormValue("preview"); preview != "" {
+		var err error
+		if opts.Preview, err = strconv.ParseBool(preview); err != nil {
+			h.sendResponse(w, http.StatusBadRequest, false, nil, "Preview must be true or false")
+			return
+		}
+	}
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			h.sendResponse(w, http.StatusBadRequest, false, nil, "Mapping must be a JSON object of column names")
+			return
+		}
+	}
+
+	rows, err := spreadsheet.Read(data, format)
+	if err != nil {
+		h.sendResponse(w, http.StatusBadRequest, false, nil, "Invalid "+format+" file: "+err.Error())
+		return
+	}
+
+	result, err := h.serviceFor(r).ImportBacklogSheet(backlogID, rows, opts)
+	if err != nil {
+		// A rejected file still reports the problems of every row
+		var data interface{}
+		if result != nil {
+			data = result
+		}
+		h.sendResponse(w, errorStatus(err, http.StatusNotFound), false, data, err.Error())
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, true, result, "")
+}
+
+func (h *Handler) sendSheetReadError(w http.ResponseWriter, err error, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.sendResponse(w, http.StatusRequestEntityTooLarge, false, nil,
+			fmt.Sprintf("Files may not be larger than %d bytes", maxSheetSize))
+		return
+	}
+	h.sendResponse(w, http.StatusBadRequest, false, nil, message)
+}
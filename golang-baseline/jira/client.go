@@ -0,0 +1,206 @@
+// Package jira is a small client for the parts of the Jira REST API (version
+// 2) used to import and synchronise issues
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// pageSize is the number of issues requested per search page
+const pageSize = 100
+
+// Error is returned when Jira answers with an error status
+type Error struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (e *Error) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("jira: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("jira: %d %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// Client calls the Jira REST API of one site. With an email the token is
+// an API token sent with basic authentication, as Jira Cloud expects;
+// without one it is sent as a bearer personal access token.
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the site at baseURL
+func NewClient(baseURL, email, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		email:   email,
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// IssueURL returns the browser URL of an issue
+func (c *Client) IssueURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
+
+// IssueKey extracts the issue key from a browser URL of this site. It
+// reports false for URLs of other sites.
+func (c *Client) IssueKey(issueURL string) (string, bool) {
+	prefix := c.baseURL + "/browse/"
+	if !strings.HasPrefix(issueURL, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(issueURL, prefix)
+	if i := strings.IndexAny(key, "/?#"); i >= 0 {
+		key = key[:i]
+	}
+	return key, key != ""
+}
+
+// GetProject returns a project by key
+func (c *Client) GetProject(ctx context.Context, key string) (*Project, error) {
+	var project Project
+	if err := c.do(ctx, http.MethodGet, "/project/"+url.PathEscape(key), nil, nil, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetIssue returns an issue with the given fields
+func (c *Client) GetIssue(ctx context.Context, key string, fields []string) (*Issue, error) {
+	query := url.Values{"fields": {strings.Join(fields, ",")}}
+	var issue Issue
+	if err := c.do(ctx, http.MethodGet, "/issue/"+url.PathEscape(key), query, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// SearchIssues returns every issue matching a JQL query, following the
+// pages of the search
+func (c *Client) SearchIssues(ctx context.Context, jql string, fields []string) ([]Issue, error) {
+	var issues []Issue
+	for {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {strings.Join(fields, ",")},
+			"startAt":    {fmt.Sprint(len(issues))},
+			"maxResults": {fmt.Sprint(pageSize)},
+		}
+		var page struct {
+			Total  int     `json:"total"`
+			Issues []Issue `json:"issues"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/search", query, nil, &page); err != nil {
+			return nil, err
+		}
+		issues = append(issues, page.Issues...)
+		if len(page.Issues) == 0 || len(issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// UpdateFields sets fields of an issue. A nil value clears the field.
+func (c *Client) UpdateFields(ctx context.Context, key string, fields map[string]interface{}) error {
+	body := map[string]interface{}{"fields": fields}
+	return c.do(ctx, http.MethodPut, "/issue/"+url.PathEscape(key), nil, body, nil)
+}
+
+// Transitions returns the transitions currently available on an issue
+func (c *Client) Transitions(ctx context.Context, key string) ([]Transition, error) {
+	var result struct {
+		Transitions []Transition `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/issue/"+url.PathEscape(key)+"/transitions", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Transitions, nil
+}
+
+// Transition moves an issue through a transition
+func (c *Client) Transition(ctx context.Context, key, transitionID string) error {
+	body := map[string]interface{}{"transition": map[string]string{"id": transitionID}}
+	return c.do(ctx, http.MethodPost, "/issue/"+url.PathEscape(key)+"/transitions", nil, body, nil)
+}
+
+// FindUsers searches users by name or email address
+func (c *Client) FindUsers(ctx context.Context, who string) ([]User, error) {
+	// Jira Cloud reads "query" and Jira Server reads "username"
+	query := url.Values{"query": {who}, "username": {who}}
+	var users []User
+	if err := c.do(ctx, http.MethodGet, "/user/search", query, nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// do sends a request to the REST API and decodes the JSON answer into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.baseURL + "/rest/api/2" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var details struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&details) == nil {
+			apiErr.Messages = details.ErrorMessages
+			for field, message := range details.Errors {
+				apiErr.Messages = append(apiErr.Messages, field+": "+message)
+			}
+		}
+		return apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("jira: invalid response: %w", err)
+	}
+	return nil
+}
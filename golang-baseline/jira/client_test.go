@@ -0,0 +1,187 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClientAuthentication(t *testing.T) {
+	tests := []struct {
+		name, email, token, want string
+	}{
+		{"api token", "ada@example.com", "secret", "Basic YWRhQGV4YW1wbGUuY29tOnNlY3JldA=="},
+		{"personal access token", "", "secret", "Bearer secret"},
+		{"anonymous", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				fmt.Fprint(w, `{"key":"PRJ"}`)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, tt.email, tt.token, time.Second)
+			if _, err := client.GetProject(context.Background(), "PRJ"); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIssuesFollowsPages(t *testing.T) {
+	const total = 250
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/2/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("jql"); got != `project = "PRJ"` {
+			t.Errorf("jql = %q", got)
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		max, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		var issues []Issue
+		for i := start; i < total && i < start+max; i++ {
+			issues = append(issues, Issue{Key: fmt.Sprintf("PRJ-%d", i+1)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"total": total, "issues": issues})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", time.Second)
+	issues, err := client.SearchIssues(context.Background(), `project = "PRJ"`, []string{"summary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != total {
+		t.Errorf("got %d issues, want %d", len(issues), total)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+	if issues[total-1].Key != "PRJ-250" {
+		t.Errorf("last issue = %s", issues[total-1].Key)
+	}
+}
+
+func TestIssueFieldsKeepRawFields(t *testing.T) {
+	var issue Issue
+	data := `{"key":"PRJ-1","fields":{"summary":"Login","duedate":"2024-03-01","customfield_10015":"2024-02-01",` +
+		`"status":{"name":"In Review","statusCategory":{"key":"indeterminate"}}}}`
+	if err := json.Unmarshal([]byte(data), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Fields.Summary != "Login" || issue.Fields.DueDate != "2024-03-01" {
+		t.Errorf("known fields not decoded: %+v", issue.Fields)
+	}
+	if got := issue.Fields.String("customfield_10015"); got != "2024-02-01" {
+		t.Errorf("custom field = %q", got)
+	}
+	if got := issue.Fields.String("missing"); got != "" {
+		t.Errorf("missing field = %q", got)
+	}
+	if issue.Fields.Status.StatusCategory.Key != CategoryInProgress {
+		t.Errorf("status category = %q", issue.Fields.Status.StatusCategory.Key)
+	}
+}
+
+func TestUpdateFieldsAndTransition(t *testing.T) {
+	var updated map[string]map[string]interface{}
+	var transitioned map[string]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/rest/api/2/issue/PRJ-1":
+			json.NewDecoder(r.Body).Decode(&updated)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/PRJ-1/transitions":
+			fmt.Fprint(w, `{"transitions":[{"id":"31","name":"Done","to":{"name":"Done","statusCategory":{"key":"done"}}}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/PRJ-1/transitions":
+			json.NewDecoder(r.Body).Decode(&transitioned)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "token", time.Second)
+	ctx := context.Background()
+	err := client.UpdateFields(ctx, "PRJ-1", map[string]interface{}{"duedate": "2024-03-01", "assignee": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated["fields"]["duedate"] != "2024-03-01" {
+		t.Errorf("duedate not sent: %v", updated)
+	}
+	if value, exists := updated["fields"]["assignee"]; !exists || value != nil {
+		t.Errorf("assignee not cleared: %v", updated)
+	}
+
+	transitions, err := client.Transitions(ctx, "PRJ-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transitions) != 1 || transitions[0].To.StatusCategory.Key != CategoryDone {
+		t.Fatalf("transitions = %+v", transitions)
+	}
+	if err := client.Transition(ctx, "PRJ-1", transitions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if transitioned["transition"]["id"] != "31" {
+		t.Errorf("transition not sent: %v", transitioned)
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errorMessages":["Issue does not exist"],"errors":{"duedate":"invalid date"}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", time.Second)
+	_, err := client.GetIssue(context.Background(), "PRJ-9", []string{"summary"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || len(apiErr.Messages) != 2 {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func TestIssueKey(t *testing.T) {
+	client := NewClient("https://jira.example.com/", "", "", time.Second)
+	tests := []struct {
+		url  string
+		key  string
+		same bool
+	}{
+		{"https://jira.example.com/browse/PRJ-1", "PRJ-1", true},
+		{"https://jira.example.com/browse/PRJ-1?focusedCommentId=3", "PRJ-1", true},
+		{"https://other.example.com/browse/PRJ-1", "", false},
+		{"https://jira.example.com/browse/", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := client.IssueKey(tt.url)
+		if key != tt.key || ok != tt.same {
+			t.Errorf("IssueKey(%q) = %q, %v; want %q, %v", tt.url, key, ok, tt.key, tt.same)
+		}
+	}
+	if got := client.IssueURL("PRJ-1"); got != "https://jira.example.com/browse/PRJ-1" {
+		t.Errorf("IssueURL = %q", got)
+	}
+}
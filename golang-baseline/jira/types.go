@@ -0,0 +1,126 @@
+package jira
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Status categories Jira groups every workflow status into
+const (
+	CategoryToDo       = "new"
+	CategoryInProgress = "indeterminate"
+	CategoryDone       = "done"
+)
+
+// Project is a Jira project
+type Project struct {
+	ID          string `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Issue is a Jira issue with the fields that were requested
+type Issue struct {
+	ID     string      `json:"id"`
+	Key    string      `json:"key"`
+	Fields IssueFields `json:"fields"`
+}
+
+// IssueFields are the fields of an issue. Fields without a dedicated member,
+// such as custom fields, are available through Raw.
+type IssueFields struct {
+	Summary          string     `json:"summary"`
+	Description      string     `json:"description"`
+	Status           *Status    `json:"status"`
+	Assignee         *User      `json:"assignee"`
+	Priority         *Priority  `json:"priority"`
+	IssueType        IssueType  `json:"issuetype"`
+	Parent           *IssueLink `json:"parent"`
+	Labels           []string   `json:"labels"`
+	DueDate          string     `json:"duedate"`
+	OriginalEstimate int        `json:"timeoriginalestimate"`
+	Updated          string     `json:"updated"`
+
+	Raw map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the known fields and keeps every field in Raw
+func (f *IssueFields) UnmarshalJSON(data []byte) error {
+	type plain IssueFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &f.Raw)
+}
+
+// String returns a field holding a string, or an empty string when the
+// field is missing, null or not a string
+func (f IssueFields) String(name string) string {
+	var value string
+	if raw, exists := f.Raw[name]; exists {
+		json.Unmarshal(raw, &value)
+	}
+	return value
+}
+
+// Status is a workflow status
+type Status struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// User is a Jira user. Jira Cloud identifies users by AccountID and Jira
+// Server by Name.
+type User struct {
+	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+}
+
+// Matches reports whether the user is the one described by a display name,
+// email address or user name, ignoring case
+func (u User) Matches(who string) bool {
+	for _, candidate := range []string{u.DisplayName, u.EmailAddress, u.Name} {
+		if candidate != "" && strings.EqualFold(candidate, who) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ref returns the value identifying the user in field updates
+func (u User) Ref() map[string]string {
+	if u.AccountID != "" {
+		return map[string]string{"accountId": u.AccountID}
+	}
+	return map[string]string{"name": u.Name}
+}
+
+// Priority is the priority of an issue
+type Priority struct {
+	Name string `json:"name"`
+}
+
+// IssueType is the type of an issue
+type IssueType struct {
+	Name    string `json:"name"`
+	Subtask bool   `json:"subtask"`
+}
+
+// IssueLink refers to another issue
+type IssueLink struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// Transition moves an issue to another status
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   Status `json:"to"`
+}
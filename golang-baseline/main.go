@@ -6,6 +6,8 @@ import (
 	"golang-baseline/services"
 	"golang-baseline/utils"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -22,28 +24,65 @@ func main() {
 	logger.Infof("Configuration loaded - Port: %s, Environment: %s", cfg.Port, cfg.Environment)
 
 	// Initialize service
-	service := services.NewService()
+	service, err := services.NewService(cfg)
+	if err != nil {
+		logger.Errorf("Could not initialize service: %s", err.Error())
+		os.Exit(1)
+	}
 	logger.Info("Service initialized")
 
-	// Initialize handlers
-	handler := handlers.NewHandler(service)
-	logger.Info("Handlers initialized")
+	// Start generating recurring stories
+	if cfg.RecurrenceCheckInterval > 0 {
+		stop := service.StartRecurrenceScheduler(time.Duration(cfg.RecurrenceCheckInterval) * time.Second)
+		defer stop()
+		logger.Infof("Recurrence scheduler started - checking every %ds", cfg.RecurrenceCheckInterval)
+	}
 
-	// Setup router
-	router := setupRoutes(handler)
-	logger.Info("Routes configured")
+	// Start delivering webhook events
+	stopWebhooks := service.StartWebhookDispatcher()
+	defer stopWebhooks()
+	logger.Infof("Webhook dispatcher started - %d workers", cfg.WebhookWorkers)
+
+	// Start the asynchronous domain event subscribers. Stopped first, so the
+	// events they drain can still be delivered as webhooks.
+	stopEvents := service.StartEventBus()
+	defer stopEvents()
+	logger.Infof("Event bus started - %d workers per asynchronous subscriber", cfg.EventWorkers)
+
+	// Start syncing items linked to Jira issues
+	if cfg.JiraBaseURL != "" && cfg.JiraSyncInterval > 0 {
+		stop := service.StartJiraSync(time.Duration(cfg.JiraSyncInterval) * time.Second)
+		defer stop()
+		logger.Infof("Jira sync started - syncing with %s every %ds", cfg.JiraBaseURL, cfg.JiraSyncInterval)
+	}
 
 	// Setup CORS
 	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
+		AllowedOrigins: cfg.CORSAllowedOrigins,
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{"X-Request-ID"},
 	})
+	logger.Infof("CORS configured - allowed origins: %s", strings.Join(cfg.CORSAllowedOrigins, ", "))
+
+	// Initialize handlers
+	handler := handlers.NewHandler(service, c.OriginAllowed)
+	logger.Info("Handlers initialized")
+
+	// Setup router
+	router := setupRoutes(handler)
+	logger.Info("Routes configured")
+	if cfg.OIDCIssuer != "" {
+		logger.Infof("Single sign-on configured - provider %s", cfg.OIDCIssuer)
+	}
+	if !cfg.AuthRequired {
+		logger.Info("Authentication is not required - requests without credentials are served anonymously")
+	}
 
 	// Create server
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      c.Handler(router),
+		Handler:      c.Handler(handlers.WithRequestInfo(handler.Authenticate(router), cfg.TrustedProxyHops)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -57,14 +96,142 @@ func main() {
 	logger.Info("  GET  /api/backlogs         - Get all backlogs")
 	logger.Info("  POST /api/backlogs         - Create backlog")
 	logger.Info("  GET  /api/backlogs/{id}    - Get specific backlog")
+	logger.Info("  POST /api/import           - Create or update a backlog tree in one request")
+	logger.Info("  GET  /api/backlogs/{id}/gantt - Get backlog timeline and critical path")
+	logger.Info("  POST /api/backlogs/{id}/schedule - Propose or apply plan dates")
+	logger.Info("  GET  /api/backlogs/{id}/board - Get Kanban board")
+	logger.Info("  PUT  /api/backlogs/{id}/wip-limits - Set WIP limits per status")
+	logger.Info("  GET  /api/backlogs/{id}/export - Export stories and subtasks as CSV or XLSX")
+	logger.Info("  POST /api/backlogs/{id}/import - Import stories and subtasks from CSV or XLSX")
+	logger.Info("  GET  /api/backlogs/{id}/baselines - Get backlog baselines")
+	logger.Info("  POST /api/backlogs/{id}/baselines - Save baseline")
+	logger.Info("  GET  /api/baselines/{id}   - Get specific baseline")
+	logger.Info("  DELETE /api/baselines/{id} - Delete baseline")
+	logger.Info("  GET  /api/baselines/{id}/compare - Compare plan or actuals with baseline")
 	logger.Info("  GET  /api/stories          - Get stories by backlog")
 	logger.Info("  POST /api/stories          - Create story")
 	logger.Info("  GET  /api/stories/{id}     - Get specific story")
+	logger.Info("  PUT  /api/stories/{id}     - Update story")
+	logger.Info("  DELETE /api/stories/{id}   - Delete story and its subtasks")
 	logger.Info("  PUT  /api/stories/{id}/status - Update story status")
 	logger.Info("  GET  /api/subtasks         - Get subtasks by story")
 	logger.Info("  POST /api/subtasks         - Create subtask")
 	logger.Info("  GET  /api/subtasks/{id}    - Get specific subtask")
+	logger.Info("  PUT  /api/subtasks/{id}    - Update subtask")
+	logger.Info("  DELETE /api/subtasks/{id}  - Delete subtask")
 	logger.Info("  PUT  /api/subtasks/{id}/status - Update subtask status")
+	logger.Info("  GET  /api/backlogs/{id}/labels - Get backlog labels")
+	logger.Info("  POST /api/backlogs/{id}/labels - Create label")
+	logger.Info("  PUT  /api/labels/{id}      - Update label")
+	logger.Info("  DELETE /api/labels/{id}    - Delete label")
+	logger.Info("  POST /api/labels/bulk      - Add or remove labels on many items")
+	logger.Info("  POST /api/bulk             - Change status, assign, move, relabel or delete many items")
+	logger.Info("  GET  /api/backlogs/{id}/fields - Get backlog custom fields")
+	logger.Info("  POST /api/backlogs/{id}/fields - Create custom field")
+	logger.Info("  PUT  /api/fields/{id}      - Update custom field")
+	logger.Info("  DELETE /api/fields/{id}    - Delete custom field and its values")
+	logger.Info("  GET  /api/users            - Get all users")
+	logger.Info("  POST /api/users            - Register user")
+	logger.Info("  GET  /api/users/{id}       - Get specific user")
+	logger.Info("  GET  /api/stories/{id}/comments  - Get story comment threads")
+	logger.Info("  POST /api/stories/{id}/comments  - Comment on story")
+	logger.Info("  GET  /api/subtasks/{id}/comments - Get subtask comment threads")
+	logger.Info("  POST /api/subtasks/{id}/comments - Comment on subtask")
+	logger.Info("  GET  /api/comments/{id}    - Get comment with edit history")
+	logger.Info("  PUT  /api/comments/{id}    - Edit comment")
+	logger.Info("  DELETE /api/comments/{id}  - Delete comment")
+	logger.Info("  POST /api/stories/{id}/checklist  - Add story checklist item")
+	logger.Info("  PUT  /api/stories/{id}/checklist/{itemId} - Update story checklist item")
+	logger.Info("  DELETE /api/stories/{id}/checklist/{itemId} - Delete story checklist item")
+	logger.Info("  POST /api/subtasks/{id}/checklist - Add subtask checklist item")
+	logger.Info("  PUT  /api/subtasks/{id}/checklist/{itemId} - Update subtask checklist item")
+	logger.Info("  DELETE /api/subtasks/{id}/checklist/{itemId} - Delete subtask checklist item")
+	logger.Info("  GET  /api/stories/{id}/attachments  - Get story attachments")
+	logger.Info("  POST /api/stories/{id}/attachments  - Upload story attachment")
+	logger.Info("  GET  /api/subtasks/{id}/attachments - Get subtask attachments")
+	logger.Info("  POST /api/subtasks/{id}/attachments - Upload subtask attachment")
+	logger.Info("  GET  /api/attachments/{id} - Get attachment metadata")
+	logger.Info("  GET  /api/attachments/{id}/download - Download attachment")
+	logger.Info("  DELETE /api/attachments/{id} - Delete attachment")
+	logger.Info("  POST /api/links            - Link two stories or subtasks")
+	logger.Info("  DELETE /api/links/{id}     - Delete link")
+	logger.Info("  GET  /api/stories/{id}/links  - Get story links")
+	logger.Info("  GET  /api/subtasks/{id}/links - Get subtask links")
+	logger.Info("  GET  /api/epics            - Get all epics")
+	logger.Info("  POST /api/epics            - Create epic")
+	logger.Info("  GET  /api/epics/{id}       - Get specific epic")
+	logger.Info("  PUT  /api/epics/{id}       - Update epic")
+	logger.Info("  DELETE /api/epics/{id}     - Delete epic")
+	logger.Info("  POST /api/epics/{id}/stories - Add stories to epic")
+	logger.Info("  DELETE /api/epics/{id}/stories/{storyId} - Remove story from epic")
+	logger.Info("  GET  /api/milestones       - Get all milestones")
+	logger.Info("  POST /api/milestones       - Create milestone")
+	logger.Info("  GET  /api/milestones/{id}  - Get specific milestone")
+	logger.Info("  PUT  /api/milestones/{id}  - Update milestone")
+	logger.Info("  DELETE /api/milestones/{id} - Delete milestone")
+	logger.Info("  POST /api/milestones/{id}/stories - Add stories to milestone")
+	logger.Info("  DELETE /api/milestones/{id}/stories/{storyId} - Remove story from milestone")
+	logger.Info("  GET  /api/milestones/{id}/readiness - Release readiness report")
+	logger.Info("  GET  /api/calendar         - Get working calendar")
+	logger.Info("  PUT  /api/calendar         - Update working calendar")
+	logger.Info("  GET  /api/capacities       - Get capacity overrides")
+	logger.Info("  PUT  /api/capacities/{pic} - Set capacity of a person")
+	logger.Info("  GET  /api/templates        - Get all story templates")
+	logger.Info("  POST /api/templates        - Create story template")
+	logger.Info("  GET  /api/templates/{id}   - Get specific template")
+	logger.Info("  DELETE /api/templates/{id} - Delete template")
+	logger.Info("  POST /api/templates/{id}/instantiate - Create story from template")
+	logger.Info("  POST /api/stories/{id}/template - Save story as template")
+	logger.Info("  POST /api/stories/{id}/clone - Deep-clone story")
+	logger.Info("  POST /api/backlogs/{id}/clone - Deep-clone backlog")
+	logger.Info("  GET  /api/recurrences      - Get recurring story series")
+	logger.Info("  GET  /api/stories/{id}/recurrence - Get story recurrence")
+	logger.Info("  PUT  /api/stories/{id}/recurrence - Make story recurring")
+	logger.Info("  DELETE /api/stories/{id}/recurrence - Stop recurrence")
+	logger.Info("  GET  /api/webhooks         - Get all webhooks")
+	logger.Info("  POST /api/webhooks         - Create webhook")
+	logger.Info("  GET  /api/webhooks/{id}    - Get specific webhook")
+	logger.Info("  PUT  /api/webhooks/{id}    - Update webhook")
+	logger.Info("  DELETE /api/webhooks/{id}  - Delete webhook")
+	logger.Info("  POST /api/webhooks/{id}/ping - Send a test ping")
+	logger.Info("  GET  /api/webhooks/{id}/deliveries - Get the delivery log")
+	logger.Info("  GET  /api/feed/events      - Stream changes as Server-Sent Events")
+	logger.Info("  GET  /api/feed/ws          - Stream changes over a WebSocket")
+	logger.Info("  POST /api/jira/import      - Import a Jira project into a backlog")
+	logger.Info("  POST /api/jira/sync        - Sync linked items with Jira now")
+	logger.Info("  GET  /api/jira/sync        - Get the latest Jira sync report")
+	logger.Info("  GET  /api/jira/links       - Get items linked to Jira issues")
+	logger.Info("  GET  /api/jira/conflicts   - Get unresolved Jira sync conflicts")
+	logger.Info("  POST /api/jira/conflicts/{id}/resolve - Keep the local or the Jira value")
+	logger.Info("  POST /api/auth/login       - Log in with a password and get a bearer token")
+	logger.Info("  GET  /api/auth/oidc/login  - Sign in with the OpenID Connect provider")
+	logger.Info("  GET  /api/auth/oidc/callback - Complete single sign-on and start a session")
+	logger.Info("  POST /api/auth/logout      - End the browser session")
+	logger.Info("  GET  /api/auth/me          - Get the authenticated principal")
+	logger.Info("  GET  /api/auth/keys        - Get your API keys")
+	logger.Info("  POST /api/auth/keys        - Create an API key")
+	logger.Info("  DELETE /api/auth/keys/{id} - Delete an API key")
+	logger.Info("  GET  /api/audit            - Query the audit log")
+	logger.Info("  GET  /api/audit/export     - Export the audit log as JSON Lines")
+	logger.Info("  GET  /api/roles            - Get built-in and custom roles")
+	logger.Info("  POST /api/roles            - Create custom role")
+	logger.Info("  PUT  /api/roles/{id}       - Update custom role")
+	logger.Info("  DELETE /api/roles/{id}     - Delete custom role")
+	logger.Info("  GET  /api/teams            - Get all teams")
+	logger.Info("  POST /api/teams            - Create team")
+	logger.Info("  GET  /api/teams/{id}       - Get specific team")
+	logger.Info("  PUT  /api/teams/{id}       - Update team")
+	logger.Info("  DELETE /api/teams/{id}     - Delete team")
+	logger.Info("  GET  /api/backlogs/{id}/grants - Get who has access to a backlog")
+	logger.Info("  POST /api/backlogs/{id}/grants - Grant a role on a backlog")
+	logger.Info("  DELETE /api/grants/{id}    - Revoke a grant")
+	logger.Info("  GET  /api/workspaces       - Get workspaces")
+	logger.Info("  POST /api/workspaces       - Create workspace")
+	logger.Info("  GET  /api/workspaces/{id}  - Get specific workspace")
+	logger.Info("  PUT  /api/workspaces/{id}  - Update workspace name and settings")
+	logger.Info("  DELETE /api/workspaces/{id} - Delete empty workspace")
+	logger.Info("  GET  /api/workspaces/{id}/fields - Get workspace custom fields")
+	logger.Info("  POST /api/workspaces/{id}/fields - Create workspace custom field")
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Errorf("Could not start server: %s", err.Error())
@@ -88,18 +255,188 @@ func setupRoutes(handler *handlers.Handler) *mux.Router {
 	api.HandleFunc("/backlogs", handler.GetAllBacklogs).Methods("GET")
 	api.HandleFunc("/backlogs", handler.CreateBacklog).Methods("POST")
 	api.HandleFunc("/backlogs/{id}", handler.GetBacklog).Methods("GET")
+	api.HandleFunc("/import", handler.ImportBacklog).Methods("POST")
+	api.HandleFunc("/backlogs/{id}/gantt", handler.GetGanttChart).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/schedule", handler.ScheduleBacklog).Methods("POST")
+	api.HandleFunc("/backlogs/{id}/board", handler.GetBoard).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/wip-limits", handler.UpdateWIPLimits).Methods("PUT")
+	api.HandleFunc("/backlogs/{id}/export", handler.ExportBacklogSheet).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/import", handler.ImportBacklogSheet).Methods("POST")
+
+	// Baseline routes
+	api.HandleFunc("/backlogs/{id}/baselines", handler.GetBaselinesByBacklog).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/baselines", handler.CreateBaseline).Methods("POST")
+	api.HandleFunc("/baselines/{id}", handler.GetBaseline).Methods("GET")
+	api.HandleFunc("/baselines/{id}", handler.DeleteBaseline).Methods("DELETE")
+	api.HandleFunc("/baselines/{id}/compare", handler.CompareBaseline).Methods("GET")
 
 	// Story routes
 	api.HandleFunc("/stories", handler.CreateStory).Methods("POST")
 	api.HandleFunc("/stories/{id}", handler.GetStory).Methods("GET")
+	api.HandleFunc("/stories/{id}", handler.UpdateStory).Methods("PUT")
+	api.HandleFunc("/stories/{id}", handler.DeleteStory).Methods("DELETE")
 	api.HandleFunc("/stories/{id}/status", handler.UpdateStoryStatus).Methods("PUT")
 	api.HandleFunc("/backlogs/{backlogId}/stories", handler.GetStoriesByBacklog).Methods("GET")
 
 	// SubTask routes
 	api.HandleFunc("/subtasks", handler.CreateSubTask).Methods("POST")
 	api.HandleFunc("/subtasks/{id}", handler.GetSubTask).Methods("GET")
+	api.HandleFunc("/subtasks/{id}", handler.UpdateSubTask).Methods("PUT")
+	api.HandleFunc("/subtasks/{id}", handler.DeleteSubTask).Methods("DELETE")
 	api.HandleFunc("/subtasks/{id}/status", handler.UpdateSubTaskStatus).Methods("PUT")
 	api.HandleFunc("/stories/{storyId}/subtasks", handler.GetSubTasksByStory).Methods("GET")
 
+	// Bulk routes
+	api.HandleFunc("/bulk", handler.Bulk).Methods("POST")
+
+	// Label routes
+	api.HandleFunc("/backlogs/{id}/labels", handler.GetLabelsByBacklog).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/labels", handler.CreateLabel).Methods("POST")
+	api.HandleFunc("/labels/bulk", handler.BulkRelabel).Methods("POST")
+	api.HandleFunc("/labels/{id}", handler.UpdateLabel).Methods("PUT")
+	api.HandleFunc("/labels/{id}", handler.DeleteLabel).Methods("DELETE")
+
+	// Custom field routes
+	api.HandleFunc("/backlogs/{id}/fields", handler.GetCustomFieldsByBacklog).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/fields", handler.CreateCustomField).Methods("POST")
+	api.HandleFunc("/fields/{id}", handler.UpdateCustomField).Methods("PUT")
+	api.HandleFunc("/fields/{id}", handler.DeleteCustomField).Methods("DELETE")
+
+	// User routes
+	api.HandleFunc("/users", handler.GetAllUsers).Methods("GET")
+	api.HandleFunc("/users", handler.CreateUser).Methods("POST")
+	api.HandleFunc("/users/{id}", handler.GetUser).Methods("GET")
+
+	// Comment routes
+	api.HandleFunc("/stories/{id}/comments", handler.GetStoryComments).Methods("GET")
+	api.HandleFunc("/stories/{id}/comments", handler.CreateStoryComment).Methods("POST")
+	api.HandleFunc("/subtasks/{id}/comments", handler.GetSubTaskComments).Methods("GET")
+	api.HandleFunc("/subtasks/{id}/comments", handler.CreateSubTaskComment).Methods("POST")
+	api.HandleFunc("/comments/{id}", handler.GetComment).Methods("GET")
+	api.HandleFunc("/comments/{id}", handler.UpdateComment).Methods("PUT")
+	api.HandleFunc("/comments/{id}", handler.DeleteComment).Methods("DELETE")
+
+	// Checklist routes
+	api.HandleFunc("/stories/{id}/checklist", handler.AddStoryChecklistItem).Methods("POST")
+	api.HandleFunc("/stories/{id}/checklist/{itemId}", handler.UpdateStoryChecklistItem).Methods("PUT")
+	api.HandleFunc("/stories/{id}/checklist/{itemId}", handler.DeleteStoryChecklistItem).Methods("DELETE")
+	api.HandleFunc("/subtasks/{id}/checklist", handler.AddSubTaskChecklistItem).Methods("POST")
+	api.HandleFunc("/subtasks/{id}/checklist/{itemId}", handler.UpdateSubTaskChecklistItem).Methods("PUT")
+	api.HandleFunc("/subtasks/{id}/checklist/{itemId}", handler.DeleteSubTaskChecklistItem).Methods("DELETE")
+
+	// Attachment routes
+	api.HandleFunc("/stories/{id}/attachments", handler.GetStoryAttachments).Methods("GET")
+	api.HandleFunc("/stories/{id}/attachments", handler.UploadStoryAttachment).Methods("POST")
+	api.HandleFunc("/subtasks/{id}/attachments", handler.GetSubTaskAttachments).Methods("GET")
+	api.HandleFunc("/subtasks/{id}/attachments", handler.UploadSubTaskAttachment).Methods("POST")
+	api.HandleFunc("/attachments/{id}", handler.GetAttachment).Methods("GET")
+	api.HandleFunc("/attachments/{id}/download", handler.DownloadAttachment).Methods("GET")
+	api.HandleFunc("/attachments/{id}", handler.DeleteAttachment).Methods("DELETE")
+
+	// Link routes
+	api.HandleFunc("/links", handler.CreateLink).Methods("POST")
+	api.HandleFunc("/links/{id}", handler.DeleteLink).Methods("DELETE")
+	api.HandleFunc("/stories/{id}/links", handler.GetStoryLinks).Methods("GET")
+	api.HandleFunc("/subtasks/{id}/links", handler.GetSubTaskLinks).Methods("GET")
+
+	// Epic routes
+	api.HandleFunc("/epics", handler.GetAllEpics).Methods("GET")
+	api.HandleFunc("/epics", handler.CreateEpic).Methods("POST")
+	api.HandleFunc("/epics/{id}", handler.GetEpic).Methods("GET")
+	api.HandleFunc("/epics/{id}", handler.UpdateEpic).Methods("PUT")
+	api.HandleFunc("/epics/{id}", handler.DeleteEpic).Methods("DELETE")
+	api.HandleFunc("/epics/{id}/stories", handler.AddStoriesToEpic).Methods("POST")
+	api.HandleFunc("/epics/{id}/stories/{storyId}", handler.RemoveStoryFromEpic).Methods("DELETE")
+
+	// Milestone routes
+	api.HandleFunc("/milestones", handler.GetAllMilestones).Methods("GET")
+	api.HandleFunc("/milestones", handler.CreateMilestone).Methods("POST")
+	api.HandleFunc("/milestones/{id}", handler.GetMilestone).Methods("GET")
+	api.HandleFunc("/milestones/{id}", handler.UpdateMilestone).Methods("PUT")
+	api.HandleFunc("/milestones/{id}", handler.DeleteMilestone).Methods("DELETE")
+	api.HandleFunc("/milestones/{id}/stories", handler.AddStoriesToMilestone).Methods("POST")
+	api.HandleFunc("/milestones/{id}/stories/{storyId}", handler.RemoveStoryFromMilestone).Methods("DELETE")
+	api.HandleFunc("/milestones/{id}/readiness", handler.GetReleaseReadiness).Methods("GET")
+
+	// Scheduling routes
+	api.HandleFunc("/calendar", handler.GetCalendar).Methods("GET")
+	api.HandleFunc("/calendar", handler.UpdateCalendar).Methods("PUT")
+	api.HandleFunc("/capacities", handler.GetCapacities).Methods("GET")
+	api.HandleFunc("/capacities/{pic}", handler.SetCapacity).Methods("PUT")
+
+	// Template and clone routes
+	api.HandleFunc("/templates", handler.GetAllTemplates).Methods("GET")
+	api.HandleFunc("/templates", handler.CreateTemplate).Methods("POST")
+	api.HandleFunc("/templates/{id}", handler.GetTemplate).Methods("GET")
+	api.HandleFunc("/templates/{id}", handler.DeleteTemplate).Methods("DELETE")
+	api.HandleFunc("/templates/{id}/instantiate", handler.InstantiateTemplate).Methods("POST")
+	api.HandleFunc("/stories/{id}/template", handler.CreateTemplateFromStory).Methods("POST")
+	api.HandleFunc("/stories/{id}/clone", handler.CloneStory).Methods("POST")
+	api.HandleFunc("/backlogs/{id}/clone", handler.CloneBacklog).Methods("POST")
+
+	// Recurrence routes
+	api.HandleFunc("/recurrences", handler.GetAllRecurrences).Methods("GET")
+	api.HandleFunc("/stories/{id}/recurrence", handler.GetRecurrence).Methods("GET")
+	api.HandleFunc("/stories/{id}/recurrence", handler.SetRecurrence).Methods("PUT")
+	api.HandleFunc("/stories/{id}/recurrence", handler.StopRecurrence).Methods("DELETE")
+
+	// Webhook routes
+	api.HandleFunc("/webhooks", handler.GetAllWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks", handler.CreateWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/{id}", handler.GetWebhook).Methods("GET")
+	api.HandleFunc("/webhooks/{id}", handler.UpdateWebhook).Methods("PUT")
+	api.HandleFunc("/webhooks/{id}", handler.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/webhooks/{id}/ping", handler.PingWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/{id}/deliveries", handler.GetWebhookDeliveries).Methods("GET")
+
+	// Change feed routes
+	api.HandleFunc("/feed/events", handler.StreamFeed).Methods("GET")
+	api.HandleFunc("/feed/ws", handler.FeedSocket).Methods("GET")
+
+	// Jira routes
+	api.HandleFunc("/jira/import", handler.ImportJiraProject).Methods("POST")
+	api.HandleFunc("/jira/sync", handler.SyncJira).Methods("POST")
+	api.HandleFunc("/jira/sync", handler.GetJiraSyncReport).Methods("GET")
+	api.HandleFunc("/jira/links", handler.GetJiraLinks).Methods("GET")
+	api.HandleFunc("/jira/conflicts", handler.GetJiraConflicts).Methods("GET")
+	api.HandleFunc("/jira/conflicts/{id}/resolve", handler.ResolveJiraConflict).Methods("POST")
+
+	// Auth routes
+	api.HandleFunc("/auth/login", handler.Login).Methods("POST")
+	api.HandleFunc("/auth/oidc/login", handler.OIDCLogin).Methods("GET")
+	api.HandleFunc("/auth/oidc/callback", handler.OIDCCallback).Methods("GET")
+	api.HandleFunc("/auth/logout", handler.Logout).Methods("POST")
+	api.HandleFunc("/auth/me", handler.GetPrincipal).Methods("GET")
+	api.HandleFunc("/auth/keys", handler.GetAPIKeys).Methods("GET")
+	api.HandleFunc("/auth/keys", handler.CreateAPIKey).Methods("POST")
+	api.HandleFunc("/auth/keys/{id}", handler.DeleteAPIKey).Methods("DELETE")
+
+	// Audit routes
+	api.HandleFunc("/audit", handler.GetAuditLog).Methods("GET")
+	api.HandleFunc("/audit/export", handler.ExportAuditLog).Methods("GET")
+
+	// Access routes
+	api.HandleFunc("/roles", handler.GetRoles).Methods("GET")
+	api.HandleFunc("/roles", handler.CreateRole).Methods("POST")
+	api.HandleFunc("/roles/{id}", handler.UpdateRole).Methods("PUT")
+	api.HandleFunc("/roles/{id}", handler.DeleteRole).Methods("DELETE")
+	api.HandleFunc("/teams", handler.GetAllTeams).Methods("GET")
+	api.HandleFunc("/teams", handler.CreateTeam).Methods("POST")
+	api.HandleFunc("/teams/{id}", handler.GetTeam).Methods("GET")
+	api.HandleFunc("/teams/{id}", handler.UpdateTeam).Methods("PUT")
+	api.HandleFunc("/teams/{id}", handler.DeleteTeam).Methods("DELETE")
+	api.HandleFunc("/backlogs/{id}/grants", handler.GetGrantsByBacklog).Methods("GET")
+	api.HandleFunc("/backlogs/{id}/grants", handler.CreateGrant).Methods("POST")
+	api.HandleFunc("/grants/{id}", handler.DeleteGrant).Methods("DELETE")
+
+	// Workspace routes
+	api.HandleFunc("/workspaces", handler.GetAllWorkspaces).Methods("GET")
+	api.HandleFunc("/workspaces", handler.CreateWorkspace).Methods("POST")
+	api.HandleFunc("/workspaces/{id}", handler.GetWorkspace).Methods("GET")
+	api.HandleFunc("/workspaces/{id}", handler.UpdateWorkspace).Methods("PUT")
+	api.HandleFunc("/workspaces/{id}", handler.DeleteWorkspace).Methods("DELETE")
+	api.HandleFunc("/workspaces/{id}/fields", handler.GetWorkspaceCustomFields).Methods("GET")
+	api.HandleFunc("/workspaces/{id}/fields", handler.CreateWorkspaceCustomField).Methods("POST")
+
 	return router
 }
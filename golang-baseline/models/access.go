@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+)
+
+// Permissions a role grants on a backlog. Edit covers the work items and
+// their checklists, links, attachments and statuses; manage covers the
+// settings of the backlog: labels, custom fields, WIP limits and grants.
+const (
+	PermView    = "view"
+	PermComment = "comment"
+	PermEdit    = "edit"
+	PermManage  = "manage"
+)
+
+// Permissions lists every permission
+var Permissions = []string{PermView, PermComment, PermEdit, PermManage}
+
+// Built-in roles
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Role is a named set of permissions granted on backlogs. The built-in
+// roles cannot be changed.
+type Role struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateRoleRequest represents the request to create a custom role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// UpdateRoleRequest represents the request to update a custom role
+type UpdateRoleRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// Team is a group of users that roles can be granted to
+type Team struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Name        string    `json:"name"`
+	Members     []string  `json:"members"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateTeamRequest represents the request to create a team. Members are
+// user IDs.
+type CreateTeamRequest struct {
+	Name    string   `json:"name" validate:"required"`
+	Members []string `json:"members"`
+}
+
+// UpdateTeamRequest represents the request to rename a team or replace its
+// members
+type UpdateTeamRequest struct {
+	Name    *string   `json:"name"`
+	Members *[]string `json:"members"`
+}
+
+// Grant gives a role on a backlog to a user or to every member of a team
+type Grant struct {
+	ID        string    `json:"id"`
+	BacklogID string    `json:"backlog_id"`
+	Role      string    `json:"role"`
+	UserID    string    `json:"user_id,omitempty"`
+	TeamID    string    `json:"team_id,omitempty"`
+	GrantedBy string    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateGrantRequest represents the request to grant a role on a backlog.
+// Exactly one of UserID and TeamID must be set.
+type CreateGrantRequest struct {
+	Role   string `json:"role" validate:"required"`
+	UserID string `json:"user_id"`
+	TeamID string `json:"team_id"`
+}
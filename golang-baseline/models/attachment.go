@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment is a file uploaded to a story or subtask. The content lives in
+// the blob store under its SHA-256 hash, shared by identical uploads.
+type Attachment struct {
+	ID          string    `json:"id"`
+	ItemType    ItemType  `json:"item_type"`
+	ItemID      string    `json:"item_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Hash        string    `json:"hash"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
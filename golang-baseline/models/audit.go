@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RequestInfo identifies the request behind a change. Principal is nil
+// for requests without credentials. Workspace is the workspace the request
+// asked for; it only applies to principals that are not local users, as
+// users always work in their own workspace.
+type RequestInfo struct {
+	RequestID string     `json:"request_id"`
+	Actor     string     `json:"actor"`
+	SourceIP  string     `json:"source_ip"`
+	Principal *Principal `json:"principal,omitempty"`
+	Workspace string     `json:"workspace,omitempty"`
+}
+
+// ActorSystem is the actor of changes the application makes by itself,
+// such as generating recurring stories or syncing with Jira
+const ActorSystem = "system"
+
+// Audit actions
+const (
+	AuditCreate       = "create"
+	AuditUpdate       = "update"
+	AuditStatusChange = "status_change"
+	AuditDelete       = "delete"
+)
+
+// AuditEntry records one change. Entries are never changed or removed.
+type AuditEntry struct {
+	ID          uint64        `json:"id"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Actor       string        `json:"actor"`
+	Action      string        `json:"action"`
+	Event       string        `json:"event"`
+	Entity      string        `json:"entity"`
+	EntityID    string        `json:"entity_id"`
+	WorkspaceID string        `json:"workspace_id,omitempty"`
+	BacklogID   string        `json:"backlog_id,omitempty"`
+	StoryID     string        `json:"story_id,omitempty"`
+	Changes     []FieldChange `json:"changes"`
+	RequestID   string        `json:"request_id,omitempty"`
+	SourceIP    string        `json:"source_ip,omitempty"`
+}
+
+// FieldChange is the old and new JSON value of one field. Old is missing
+// for created entities and New for deleted ones.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// AuditFilter selects audit entries. Empty fields match every entry.
+// Before pages backwards: only entries with a lower ID are returned.
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  string
+	BacklogID string
+	StoryID   string
+	RequestID string
+	Since     time.Time
+	Until     time.Time
+	Before    uint64
+	Limit     int
+}
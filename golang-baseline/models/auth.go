@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+)
+
+// Authentication methods
+const (
+	AuthAPIKey    = "api_key"
+	AuthToken     = "token"
+	AuthBootstrap = "bootstrap"
+	AuthSession   = "session"
+)
+
+// RoleAdmin is the role OIDC group mappings use to make users workspace
+// admins
+const RoleAdmin = "admin"
+
+// ActorAnonymous is the actor of requests without credentials, which are
+// only accepted when authentication is not required
+const ActorAnonymous = "anonymous"
+
+// Principal is who made an authenticated request. UserID and WorkspaceID
+// are empty for tokens of another issuer that name no local user, and for
+// the bootstrap key.
+type Principal struct {
+	UserID      string `json:"user_id,omitempty"`
+	Username    string `json:"username"`
+	Method      string `json:"method"`
+	KeyID       string `json:"key_id,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+// APIKey is a key a user hands to scripts and integrations. Only a hash of
+// the key is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Lookup     string     `json:"lookup"`
+	Hash       string     `json:"-"`
+	UserID     string     `json:"user_id"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRequest represents the request to create an API key. Keys
+// without an expiry last until they are deleted.
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" validate:"required"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// CreatedAPIKey is a new API key with the key itself, which is not shown
+// again
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// LoginRequest represents the request to log in with a password
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse holds the bearer token issued at login
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
+
+// Session is a browser session started by signing in with single sign-on.
+// Only a hash of the session ID is stored.
+type Session struct {
+	Hash      string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OIDCLogin is a single sign-on login waiting for the provider to call
+// back. URL is the login page of the provider; State must come back with
+// the callback.
+type OIDCLogin struct {
+	URL   string `json:"url"`
+	State string `json:"-"`
+}
+
+// SessionLogin is a completed single sign-on login. SessionID is the value
+// of the session cookie and Redirect the local path the login started from.
+type SessionLogin struct {
+	SessionID string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Redirect  string    `json:"redirect"`
+	User      *User     `json:"user"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// Baseline is a named snapshot of the plan of a backlog
+type Baseline struct {
+	ID        string          `json:"id"`
+	BacklogID string          `json:"backlog_id"`
+	Name      string          `json:"name"`
+	Stories   []BaselineStory `json:"stories"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BaselineStory records the plan of a story when the baseline was saved
+type BaselineStory struct {
+	ID        string            `json:"id"`
+	Title     string            `json:"title"`
+	PlanStart time.Time         `json:"plan_start"`
+	PlanEnd   time.Time         `json:"plan_end"`
+	Effort    int               `json:"effort"`
+	SubTasks  []BaselineSubTask `json:"subtasks"`
+}
+
+// BaselineSubTask records the plan of a subtask when the baseline was saved
+type BaselineSubTask struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	PlanStart time.Time `json:"plan_start"`
+	PlanEnd   time.Time `json:"plan_end"`
+	Effort    int       `json:"effort"`
+}
+
+// CreateBaselineRequest represents the request to save a baseline
+type CreateBaselineRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// Baseline comparison modes
+const (
+	CompareAgainstPlan   = "plan"
+	CompareAgainstActual = "actual"
+)
+
+// BaselineComparison reports how a backlog deviates from a baseline
+type BaselineComparison struct {
+	BaselineID     string           `json:"baseline_id"`
+	BacklogID      string           `json:"backlog_id"`
+	Against        string           `json:"against"`
+	Stories        []StoryDeviation `json:"stories"`
+	AddedStories   []BaselineStory  `json:"added_stories"`
+	RemovedStories []BaselineStory  `json:"removed_stories"`
+	TotalEffort    EffortComparison `json:"total_effort"`
+}
+
+// StoryDeviation describes how a story moved relative to the baseline.
+// Shifts are expressed in days; positive values mean later than baseline.
+type StoryDeviation struct {
+	ID              string           `json:"id"`
+	Title           string           `json:"title"`
+	BaselineStart   time.Time        `json:"baseline_start"`
+	BaselineEnd     time.Time        `json:"baseline_end"`
+	CurrentStart    *time.Time       `json:"current_start,omitempty"`
+	CurrentEnd      *time.Time       `json:"current_end,omitempty"`
+	StartShiftDays  *int             `json:"start_shift_days,omitempty"`
+	EndShiftDays    *int             `json:"end_shift_days,omitempty"`
+	Effort          EffortComparison `json:"effort"`
+	AddedSubTasks   []string         `json:"added_subtasks"`
+	RemovedSubTasks []string         `json:"removed_subtasks"`
+}
+
+// EffortComparison compares baseline effort with current effort
+type EffortComparison struct {
+	Baseline      int     `json:"baseline"`
+	Current       int     `json:"current"`
+	Growth        int     `json:"growth"`
+	GrowthPercent float64 `json:"growth_percent"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// Swimlane groupings of the board
+const (
+	SwimlaneNone  = ""
+	SwimlanePIC   = "pic"
+	SwimlaneLabel = "label"
+	SwimlaneEpic  = "epic"
+)
+
+// WIPLimits caps how many stories of a backlog may be in each status. A
+// missing or zero limit means the column is unlimited.
+type WIPLimits map[Status]int
+
+// Board is the Kanban view of a backlog: one column per status holding its
+// story cards in order, optionally split into swimlanes
+type Board struct {
+	BacklogID string          `json:"backlog_id"`
+	Swimlane  string          `json:"swimlane,omitempty"`
+	Columns   []BoardColumn   `json:"columns"`
+	Swimlanes []BoardSwimlane `json:"swimlanes,omitempty"`
+}
+
+// BoardColumn is a status column of the board. Count and OverLimit always
+// cover the whole backlog, even when the board is filtered.
+type BoardColumn struct {
+	Status    Status      `json:"status"`
+	WIPLimit  int         `json:"wip_limit,omitempty"`
+	Count     int         `json:"count"`
+	OverLimit bool        `json:"over_limit"`
+	Cards     []BoardCard `json:"cards"`
+}
+
+// BoardSwimlane is a horizontal lane of the board. Key is the PIC, label ID
+// or epic ID the lane groups by; it is empty for the lane of stories
+// without one.
+type BoardSwimlane struct {
+	Key     string          `json:"key"`
+	Name    string          `json:"name"`
+	Columns []BoardLaneCell `json:"columns"`
+}
+
+// BoardLaneCell holds the cards of one status within a swimlane
+type BoardLaneCell struct {
+	Status Status      `json:"status"`
+	Cards  []BoardCard `json:"cards"`
+}
+
+// BoardCard is the summary of a story shown on the board
+type BoardCard struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	PIC            string    `json:"pic"`
+	Priority       Priority  `json:"priority"`
+	Labels         []string  `json:"labels"`
+	EpicID         string    `json:"epic_id,omitempty"`
+	Blocked        bool      `json:"blocked"`
+	CommentCount   int       `json:"comment_count"`
+	SubTasks       int       `json:"subtasks"`
+	SubTasksDone   int       `json:"subtasks_done"`
+	ChecklistTotal int       `json:"checklist_total"`
+	ChecklistDone  int       `json:"checklist_done"`
+	PlanStart      time.Time `json:"plan_start"`
+	PlanEnd        time.Time `json:"plan_end"`
+}
+
+// UpdateWIPLimitsRequest represents the request to set the WIP limits of a
+// backlog. The given limits replace the current ones.
+type UpdateWIPLimitsRequest struct {
+	Limits WIPLimits `json:"limits"`
+}
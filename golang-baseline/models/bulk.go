@@ -0,0 +1,56 @@
+package models
+
+// BulkAction is the change a bulk request applies to every item
+type BulkAction string
+
+const (
+	BulkStatus BulkAction = "status"
+	BulkAssign BulkAction = "assign"
+	BulkMove   BulkAction = "move"
+	BulkLabel  BulkAction = "label"
+	BulkDelete BulkAction = "delete"
+)
+
+// BulkMode decides what happens to the other items when one item fails
+type BulkMode string
+
+const (
+	// BulkAtomic validates every item first and changes nothing unless all
+	// of them pass
+	BulkAtomic BulkMode = "atomic"
+	// BulkBestEffort applies every item that passes and reports the rest
+	BulkBestEffort BulkMode = "best_effort"
+)
+
+// BulkRequest represents the request to apply one action to many stories
+// or subtasks. The fields used depend on the action: Status for status, PIC
+// for assign, TargetID for move (a backlog for stories, a story for
+// subtasks), and Add and Remove for label.
+type BulkRequest struct {
+	Action   BulkAction `json:"action" validate:"required"`
+	ItemType ItemType   `json:"item_type" validate:"required"`
+	IDs      []string   `json:"ids" validate:"required"`
+	Mode     BulkMode   `json:"mode"`
+	Status   Status     `json:"status"`
+	PIC      *string    `json:"pic"`
+	TargetID string     `json:"target_id"`
+	Add      []string   `json:"add"`
+	Remove   []string   `json:"remove"`
+}
+
+// BulkItemResult reports the outcome of a bulk request for one item
+type BulkItemResult struct {
+	ID       string   `json:"id"`
+	Success  bool     `json:"success"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// BulkResult reports the outcome of a bulk request for every item
+type BulkResult struct {
+	Action    BulkAction       `json:"action"`
+	Mode      BulkMode         `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// ChecklistItem is an acceptance criterion or definition-of-done check on a
+// story or subtask
+type ChecklistItem struct {
+	ID        string     `json:"id"`
+	Text      string     `json:"text"`
+	Required  bool       `json:"required"`
+	Checked   bool       `json:"checked"`
+	CheckedBy string     `json:"checked_by,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateChecklistItemRequest represents the request to add a checklist item
+type CreateChecklistItemRequest struct {
+	Text     string `json:"text" validate:"required"`
+	Required bool   `json:"required"`
+}
+
+// UpdateChecklistItemRequest represents the request to change a checklist
+// item. CheckedBy names the user checking the item; it defaults to the user
+// of the request, and only admins may name someone else.
+type UpdateChecklistItemRequest struct {
+	Text      *string `json:"text"`
+	Required  *bool   `json:"required"`
+	Checked   *bool   `json:"checked"`
+	CheckedBy string  `json:"checked_by"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a Markdown message on a story or subtask. Replies reference
+// their parent comment, forming a thread.
+type Comment struct {
+	ID        string        `json:"id"`
+	ItemType  ItemType      `json:"item_type"`
+	ItemID    string        `json:"item_id"`
+	ParentID  string        `json:"parent_id,omitempty"`
+	Author    string        `json:"author"`
+	Body      string        `json:"body"`
+	Mentions  []string      `json:"mentions"`
+	Edits     []CommentEdit `json:"edits"`
+	Deleted   bool          `json:"deleted"`
+	Replies   []Comment     `json:"replies,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// CommentEdit records the body a comment had before it was edited
+type CommentEdit struct {
+	Body     string    `json:"body"`
+	EditedBy string    `json:"edited_by"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// CreateCommentRequest represents the request to comment on a work item.
+// The author defaults to the authenticated user.
+type CreateCommentRequest struct {
+	Author   string `json:"author"`
+	Body     string `json:"body" validate:"required"`
+	ParentID string `json:"parent_id"`
+}
+
+// UpdateCommentRequest represents the request to edit a comment. The
+// editor defaults to the authenticated user.
+type UpdateCommentRequest struct {
+	Editor string `json:"editor"`
+	Body   string `json:"body" validate:"required"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomFieldType represents the type of values a custom field holds
+type CustomFieldType string
+
+const (
+	FieldText   CustomFieldType = "text"
+	FieldNumber CustomFieldType = "number"
+	FieldDate   CustomFieldType = "date"
+	FieldEnum   CustomFieldType = "enum"
+	FieldUser   CustomFieldType = "user"
+)
+
+// IsValid reports whether the field type is supported
+func (t CustomFieldType) IsValid() bool {
+	switch t {
+	case FieldText, FieldNumber, FieldDate, FieldEnum, FieldUser:
+		return true
+	}
+	return false
+}
+
+// CustomField is a typed field that a backlog defines for its stories and
+// subtasks. Values are stored on each item under the field key, which never
+// changes; the display name can be renamed freely. Fields without a
+// BacklogID belong to the workspace and apply to every one of its backlogs.
+type CustomField struct {
+	ID          string          `json:"id"`
+	WorkspaceID string          `json:"workspace_id"`
+	BacklogID   string          `json:"backlog_id"`
+	Key         string          `json:"key"`
+	Name        string          `json:"name"`
+	Type        CustomFieldType `json:"type"`
+	Options     []string        `json:"options,omitempty"`
+	Required    bool            `json:"required"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CustomValues holds custom field values keyed by field key
+type CustomValues map[string]interface{}
+
+// CreateCustomFieldRequest represents the request to define a custom field
+type CreateCustomFieldRequest struct {
+	Key      string          `json:"key" validate:"required"`
+	Name     string          `json:"name" validate:"required"`
+	Type     CustomFieldType `json:"type" validate:"required"`
+	Options  []string        `json:"options"`
+	Required bool            `json:"required"`
+}
+
+// UpdateCustomFieldRequest represents the request to change a custom field.
+// The key and type of a field cannot be changed.
+type UpdateCustomFieldRequest struct {
+	Name     *string   `json:"name"`
+	Options  *[]string `json:"options"`
+	Required *bool     `json:"required"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// Progress summarizes the status of a group of stories
+type Progress struct {
+	TotalStories int            `json:"total_stories"`
+	StatusCount  map[Status]int `json:"status_count"`
+	PercentDone  float64        `json:"percent_done"`
+}
+
+// Epic groups related stories, possibly across several backlogs
+type Epic struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	StoryIDs    []string  `json:"story_ids"`
+	Progress    Progress  `json:"progress"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Milestone represents a release: a target date and the stories it ships
+type Milestone struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"target_date"`
+	StoryIDs    []string  `json:"story_ids"`
+	Progress    Progress  `json:"progress"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateEpicRequest represents the request to create a new epic
+type CreateEpicRequest struct {
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+}
+
+// UpdateEpicRequest represents the request to update an epic. Fields left
+// out of the request are not changed.
+type UpdateEpicRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+}
+
+// CreateMilestoneRequest represents the request to create a new milestone
+type CreateMilestoneRequest struct {
+	Title       string    `json:"title" validate:"required"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"target_date" validate:"required"`
+}
+
+// UpdateMilestoneRequest represents the request to update a milestone.
+// Fields left out of the request are not changed.
+type UpdateMilestoneRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	TargetDate  *time.Time `json:"target_date"`
+}
+
+// MembershipRequest represents the request to add stories to an epic or
+// milestone
+type MembershipRequest struct {
+	StoryIDs []string `json:"story_ids" validate:"required"`
+}
+
+// ReleaseReadiness reports whether the stories of a milestone are complete
+type ReleaseReadiness struct {
+	MilestoneID    string          `json:"milestone_id"`
+	Title          string          `json:"title"`
+	TargetDate     time.Time       `json:"target_date"`
+	DaysRemaining  int             `json:"days_remaining"`
+	Ready          bool            `json:"ready"`
+	Progress       Progress        `json:"progress"`
+	BlockedStories []ReadinessItem `json:"blocked_stories"`
+	OpenStories    []ReadinessItem `json:"open_stories"`
+}
+
+// ReadinessItem describes a story that keeps a milestone from being ready
+type ReadinessItem struct {
+	ID        string   `json:"id"`
+	BacklogID string   `json:"backlog_id"`
+	Title     string   `json:"title"`
+	Status    Status   `json:"status"`
+	PIC       string   `json:"pic"`
+	BlockedBy []string `json:"blocked_by,omitempty"`
+}
@@ -0,0 +1,95 @@
+package models
+
+// Domain events, published by the service whenever an aggregate changes.
+// Snapshots hold the aggregate named by the prefix of the event; checklist
+// changes are updates of their story or subtask, and status changes are
+// published instead of an update when only the status moved.
+const (
+	EventBacklogCreated       = "backlog.created"
+	EventBacklogUpdated       = "backlog.updated"
+	EventStoryCreated         = "story.created"
+	EventStoryUpdated         = "story.updated"
+	EventStoryStatusChanged   = "story.status_changed"
+	EventStoryDeleted         = "story.deleted"
+	EventSubTaskCreated       = "subtask.created"
+	EventSubTaskUpdated       = "subtask.updated"
+	EventSubTaskStatusChanged = "subtask.status_changed"
+	EventSubTaskDeleted       = "subtask.deleted"
+
+	EventLabelCreated       = "label.created"
+	EventLabelUpdated       = "label.updated"
+	EventLabelDeleted       = "label.deleted"
+	EventCustomFieldCreated = "custom_field.created"
+	EventCustomFieldUpdated = "custom_field.updated"
+	EventCustomFieldDeleted = "custom_field.deleted"
+	EventLinkCreated        = "link.created"
+	EventLinkDeleted        = "link.deleted"
+	EventCommentCreated     = "comment.created"
+	EventCommentUpdated     = "comment.updated"
+	EventCommentDeleted     = "comment.deleted"
+	EventAttachmentCreated  = "attachment.created"
+	EventAttachmentDeleted  = "attachment.deleted"
+
+	EventEpicCreated         = "epic.created"
+	EventEpicUpdated         = "epic.updated"
+	EventEpicDeleted         = "epic.deleted"
+	EventMilestoneCreated    = "milestone.created"
+	EventMilestoneUpdated    = "milestone.updated"
+	EventMilestoneDeleted    = "milestone.deleted"
+	EventBaselineCreated     = "baseline.created"
+	EventBaselineDeleted     = "baseline.deleted"
+	EventTemplateCreated     = "template.created"
+	EventTemplateDeleted     = "template.deleted"
+	EventRecurrenceCreated   = "recurrence.created"
+	EventRecurrenceUpdated   = "recurrence.updated"
+	EventRecurrenceDeleted   = "recurrence.deleted"
+	EventCalendarUpdated     = "calendar.updated"
+	EventCapacityUpdated     = "capacity.updated"
+	EventJiraConflictCreated = "jira_conflict.created"
+	EventJiraConflictDeleted = "jira_conflict.deleted"
+
+	EventUserCreated    = "user.created"
+	EventUserUpdated    = "user.updated"
+	EventWebhookCreated = "webhook.created"
+	EventWebhookUpdated = "webhook.updated"
+	EventWebhookDeleted = "webhook.deleted"
+
+	EventAPIKeyCreated = "api_key.created"
+	EventAPIKeyDeleted = "api_key.deleted"
+	EventRoleCreated   = "role.created"
+	EventRoleUpdated   = "role.updated"
+	EventRoleDeleted   = "role.deleted"
+	EventTeamCreated   = "team.created"
+	EventTeamUpdated   = "team.updated"
+	EventTeamDeleted   = "team.deleted"
+	EventGrantCreated  = "grant.created"
+	EventGrantDeleted  = "grant.deleted"
+
+	EventWorkspaceCreated = "workspace.created"
+	EventWorkspaceUpdated = "workspace.updated"
+	EventWorkspaceDeleted = "workspace.deleted"
+
+	// EventPing is only sent by the ping endpoint, whatever the filter
+	EventPing = "ping"
+)
+
+// ChangeEvents lists the events about planned work. They are streamed to
+// the change feed and webhooks can subscribe to them; events about users,
+// access and webhooks stay internal.
+var ChangeEvents = []string{
+	EventBacklogCreated, EventBacklogUpdated,
+	EventStoryCreated, EventStoryUpdated, EventStoryStatusChanged, EventStoryDeleted,
+	EventSubTaskCreated, EventSubTaskUpdated, EventSubTaskStatusChanged, EventSubTaskDeleted,
+	EventLabelCreated, EventLabelUpdated, EventLabelDeleted,
+	EventCustomFieldCreated, EventCustomFieldUpdated, EventCustomFieldDeleted,
+	EventLinkCreated, EventLinkDeleted,
+	EventCommentCreated, EventCommentUpdated, EventCommentDeleted,
+	EventAttachmentCreated, EventAttachmentDeleted,
+	EventEpicCreated, EventEpicUpdated, EventEpicDeleted,
+	EventMilestoneCreated, EventMilestoneUpdated, EventMilestoneDeleted,
+	EventBaselineCreated, EventBaselineDeleted,
+	EventTemplateCreated, EventTemplateDeleted,
+	EventRecurrenceCreated, EventRecurrenceUpdated, EventRecurrenceDeleted,
+	EventCalendarUpdated, EventCapacityUpdated,
+	EventJiraConflictCreated, EventJiraConflictDeleted,
+}
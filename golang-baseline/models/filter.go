@@ -0,0 +1,31 @@
+package models
+
+// Sort keys accepted by list endpoints. Prefix a key with "-" to reverse it.
+const (
+	SortCreatedAt = "created_at"
+	SortPriority  = "priority"
+	SortPlanStart = "plan_start"
+	SortTitle     = "title"
+	SortStatus    = "status"
+
+	// SortCustomFieldPrefix sorts on a custom field, e.g. "cf.risk"
+	SortCustomFieldPrefix = "cf."
+)
+
+// ListFilter narrows and orders the stories or subtasks returned by list
+// endpoints
+type ListFilter struct {
+	// Labels restricts results to items carrying every listed label,
+	// given by ID or name
+	Labels []string
+	// Priorities restricts results to items with any of these priorities
+	Priorities []Priority
+	// CustomFields restricts results to items whose custom field values
+	// equal the given values, keyed by field key
+	CustomFields map[string]string
+	// Query restricts results to items whose title, description or text
+	// custom fields contain the query, ignoring case
+	Query string
+	// Sort is one of the sort keys, optionally prefixed with "-"
+	Sort string
+}
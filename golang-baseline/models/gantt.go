@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// GanttBar represents a date range drawn on the timeline
+type GanttBar struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// GanttTask represents a story or subtask on the timeline together with the
+// results of the critical path computation. Stories that have subtasks are
+// summary tasks whose schedule is rolled up from their subtasks.
+type GanttTask struct {
+	ID             string    `json:"id"`
+	ItemType       ItemType  `json:"item_type"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	Title          string    `json:"title"`
+	PIC            string    `json:"pic"`
+	Status         Status    `json:"status"`
+	Summary        bool      `json:"summary"`
+	Plan           GanttBar  `json:"plan"`
+	Actual         GanttBar  `json:"actual"`
+	DurationDays   int       `json:"duration_days"`
+	EarliestStart  time.Time `json:"earliest_start"`
+	EarliestFinish time.Time `json:"earliest_finish"`
+	LatestStart    time.Time `json:"latest_start"`
+	LatestFinish   time.Time `json:"latest_finish"`
+	SlackDays      int       `json:"slack_days"`
+	Critical       bool      `json:"critical"`
+}
+
+// GanttEdge represents a dependency drawn between two tasks
+type GanttEdge struct {
+	FromID   string   `json:"from_id"`
+	ToID     string   `json:"to_id"`
+	ItemType ItemType `json:"item_type"`
+	Type     LinkType `json:"type"`
+}
+
+// GanttChart represents the timeline of a backlog
+type GanttChart struct {
+	BacklogID     string      `json:"backlog_id"`
+	ProjectStart  time.Time   `json:"project_start"`
+	ProjectFinish time.Time   `json:"project_finish"`
+	Tasks         []GanttTask `json:"tasks"`
+	Edges         []GanttEdge `json:"edges"`
+	CriticalPath  []string    `json:"critical_path"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+)
+
+// ImportDocument is a whole backlog tree created or updated in one request.
+// Items carrying an external key that matches an earlier import are
+// updated in place; items without one are always created.
+type ImportDocument struct {
+	ExternalKey string        `json:"external_key"`
+	Title       string        `json:"title" validate:"required"`
+	Description string        `json:"description"`
+	Labels      []ImportLabel `json:"labels"`
+	Stories     []ImportStory `json:"stories"`
+}
+
+// ImportLabel is a label of an imported backlog, matched by name
+type ImportLabel struct {
+	Name  string `json:"name" validate:"required"`
+	Color string `json:"color"`
+}
+
+// ImportStory is a story of an imported backlog. Labels are given by name
+// and may refer to labels declared in the document.
+type ImportStory struct {
+	ExternalKey  string          `json:"external_key"`
+	Title        string          `json:"title" validate:"required"`
+	Description  string          `json:"description"`
+	JiraURL      string          `json:"jira_url"`
+	EffortOrigin int             `json:"effort_origin"`
+	PIC          string          `json:"pic"`
+	PlanStart    time.Time       `json:"plan_start"`
+	PlanEnd      time.Time       `json:"plan_end"`
+	Status       Status          `json:"status"`
+	Priority     Priority        `json:"priority"`
+	Labels       []string        `json:"labels"`
+	CustomFields CustomValues    `json:"custom_fields"`
+	SubTasks     []ImportSubTask `json:"subtasks"`
+}
+
+// ImportSubTask is a subtask of an imported story
+type ImportSubTask struct {
+	ExternalKey  string       `json:"external_key"`
+	Title        string       `json:"title" validate:"required"`
+	Description  string       `json:"description"`
+	Effort       int          `json:"effort"`
+	JiraURL      string       `json:"jira_url"`
+	PIC          string       `json:"pic"`
+	PlanStart    time.Time    `json:"plan_start"`
+	PlanEnd      time.Time    `json:"plan_end"`
+	Status       Status       `json:"status"`
+	Priority     Priority     `json:"priority"`
+	Labels       []string     `json:"labels"`
+	CustomFields CustomValues `json:"custom_fields"`
+}
+
+// Import actions reported for each item
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+)
+
+// ImportMapping links an item of the document to the stored item. Kind is
+// "backlog", "story" or "subtask"; Path locates the item in the document.
+type ImportMapping struct {
+	Kind        string `json:"kind"`
+	Path        string `json:"path"`
+	ExternalKey string `json:"external_key,omitempty"`
+	ID          string `json:"id"`
+	Action      string `json:"action"`
+}
+
+// ImportIssue is a problem found while validating a document
+type ImportIssue struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ImportResult reports the outcome of an import. When the document does
+// not validate, only Errors is set and nothing was stored. Warnings list
+// workflow rules the status changes broke but that are not enforced.
+type ImportResult struct {
+	BacklogID string          `json:"backlog_id,omitempty"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Mapping   []ImportMapping `json:"mapping,omitempty"`
+	Errors    []ImportIssue   `json:"errors,omitempty"`
+	Warnings  []ImportIssue   `json:"warnings,omitempty"`
+}
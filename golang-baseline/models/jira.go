@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+)
+
+// Fields kept in sync between work items and their Jira issues
+const (
+	JiraFieldStatus    = "status"
+	JiraFieldPIC       = "pic"
+	JiraFieldPlanStart = "plan_start"
+	JiraFieldPlanEnd   = "plan_end"
+)
+
+// JiraSyncFields lists the synchronised fields in the order they are checked
+var JiraSyncFields = []string{JiraFieldStatus, JiraFieldPIC, JiraFieldPlanStart, JiraFieldPlanEnd}
+
+// JiraValues holds synchronised fields by name. Dates use DateLayout and
+// unset values are empty strings.
+type JiraValues map[string]string
+
+// Directions of a synchronised change
+const (
+	JiraPull = "pull"
+	JiraPush = "push"
+)
+
+// Conflict resolutions
+const (
+	JiraKeepLocal  = "local"
+	JiraKeepRemote = "remote"
+)
+
+// JiraLink tracks a story or subtask whose JiraURL points at an issue of
+// the configured Jira site. Synced holds the values both sides agreed on
+// at the last sync; a side that differs from them has changed since.
+type JiraLink struct {
+	ItemType  ItemType   `json:"item_type"`
+	ItemID    string     `json:"item_id"`
+	IssueKey  string     `json:"issue_key"`
+	Synced    JiraValues `json:"synced"`
+	SyncedAt  *time.Time `json:"synced_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// JiraConflict is a field changed on both sides since the last sync. The
+// field is left alone by later syncs until the conflict is resolved.
+type JiraConflict struct {
+	ID         string    `json:"id"`
+	ItemType   ItemType  `json:"item_type"`
+	ItemID     string    `json:"item_id"`
+	IssueKey   string    `json:"issue_key"`
+	Field      string    `json:"field"`
+	Synced     string    `json:"synced"`
+	Local      string    `json:"local"`
+	Remote     string    `json:"remote"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// JiraChange is a field copied from one side to the other by a sync
+type JiraChange struct {
+	ItemType  ItemType `json:"item_type"`
+	ItemID    string   `json:"item_id"`
+	IssueKey  string   `json:"issue_key"`
+	Field     string   `json:"field"`
+	Direction string   `json:"direction"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+}
+
+// JiraSyncError is an issue that could not be synchronised
+type JiraSyncError struct {
+	IssueKey string `json:"issue_key"`
+	Error    string `json:"error"`
+}
+
+// JiraSyncReport is the outcome of one sync run
+type JiraSyncReport struct {
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Checked    int             `json:"checked"`
+	Changes    []JiraChange    `json:"changes"`
+	Conflicts  []JiraConflict  `json:"conflicts"`
+	Errors     []JiraSyncError `json:"errors,omitempty"`
+}
+
+// JiraImportRequest imports a Jira project into a backlog
+type JiraImportRequest struct {
+	ProjectKey string `json:"project_key" validate:"required"`
+}
+
+// JiraImportResult reports a Jira import. Skipped lists issues that could
+// not be placed, such as subtasks whose parent is not in the project.
+type JiraImportResult struct {
+	ProjectKey string `json:"project_key"`
+	ImportResult
+	Skipped []ImportIssue `json:"skipped,omitempty"`
+}
+
+// ResolveJiraConflictRequest picks the side that wins a conflict
+type ResolveJiraConflictRequest struct {
+	Keep string `json:"keep" validate:"required,oneof=local remote"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+// Priority represents how urgent a story or subtask is
+type Priority string
+
+const (
+	PriorityLow      Priority = "LOW"
+	PriorityMedium   Priority = "MEDIUM"
+	PriorityHigh     Priority = "HIGH"
+	PriorityCritical Priority = "CRITICAL"
+)
+
+// Rank returns the ordering weight of a priority, higher being more urgent.
+// Unknown priorities rank lowest.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityCritical:
+		return 4
+	}
+	return 0
+}
+
+// IsValid reports whether the priority is one of the defined levels
+func (p Priority) IsValid() bool {
+	return p.Rank() > 0
+}
+
+// Label categorizes stories and subtasks. Labels are managed per backlog.
+type Label struct {
+	ID        string    `json:"id"`
+	BacklogID string    `json:"backlog_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LabelCount counts the items that carry a label, for dashboard breakdowns
+type LabelCount struct {
+	LabelID   string `json:"label_id"`
+	BacklogID string `json:"backlog_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Count     int    `json:"count"`
+}
+
+// CreateLabelRequest represents the request to create a label in a backlog
+type CreateLabelRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Color string `json:"color"`
+}
+
+// UpdateLabelRequest represents the request to update a label. Fields left
+// out of the request are not changed.
+type UpdateLabelRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// BulkLabelRequest represents the request to relabel many items at once.
+// Labels may be given by ID or by name.
+type BulkLabelRequest struct {
+	ItemType ItemType `json:"item_type" validate:"required"`
+	IDs      []string `json:"ids" validate:"required"`
+	Add      []string `json:"add"`
+	Remove   []string `json:"remove"`
+}
+
+// BulkLabelResult reports the items that were relabeled
+type BulkLabelResult struct {
+	Updated []string `json:"updated"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// ItemType identifies the kind of work item an entity refers to
+type ItemType string
+
+const (
+	ItemStory   ItemType = "story"
+	ItemSubTask ItemType = "subtask"
+)
+
+// LinkType represents the relationship expressed by a link
+type LinkType string
+
+const (
+	LinkBlocks     LinkType = "blocks"
+	LinkBlockedBy  LinkType = "blocked_by"
+	LinkRelatesTo  LinkType = "relates_to"
+	LinkDuplicates LinkType = "duplicates"
+)
+
+// IsValid reports whether the link type is one of the supported types
+func (t LinkType) IsValid() bool {
+	switch t {
+	case LinkBlocks, LinkBlockedBy, LinkRelatesTo, LinkDuplicates:
+		return true
+	}
+	return false
+}
+
+// Link represents a typed relationship between two stories or two subtasks
+type Link struct {
+	ID        string    `json:"id"`
+	ItemType  ItemType  `json:"item_type"`
+	SourceID  string    `json:"source_id"`
+	TargetID  string    `json:"target_id"`
+	Type      LinkType  `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Blocking returns the blocker and blocked item IDs of a blocking link.
+// ok is false for links that do not express a blocking relationship.
+func (l *Link) Blocking() (blocker, blocked string, ok bool) {
+	switch l.Type {
+	case LinkBlocks:
+		return l.SourceID, l.TargetID, true
+	case LinkBlockedBy:
+		return l.TargetID, l.SourceID, true
+	}
+	return "", "", false
+}
+
+// CreateLinkRequest represents the request to link two work items
+type CreateLinkRequest struct {
+	ItemType ItemType `json:"item_type" validate:"required"`
+	SourceID string   `json:"source_id" validate:"required"`
+	TargetID string   `json:"target_id" validate:"required"`
+	Type     LinkType `json:"type" validate:"required"`
+}
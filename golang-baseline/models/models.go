@@ -14,11 +14,23 @@ const (
 	StatusBlocked    Status = "BLOCKED"
 )
 
+// IsValid reports whether the status is one of the supported statuses
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusTodo, StatusInProgress, StatusDone, StatusBlocked:
+		return true
+	}
+	return false
+}
+
 // Backlog represents a project backlog
 type Backlog struct {
 	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	ExternalKey string    `json:"external_key,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
+	WIPLimits   WIPLimits `json:"wip_limits"`
 	Stories     []Story   `json:"stories"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -26,39 +38,57 @@ type Backlog struct {
 
 // Story represents a user story within a backlog
 type Story struct {
-	ID           string    `json:"id"`
-	BacklogID    string    `json:"backlog_id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	JiraURL      string    `json:"jira_url"`
-	EffortOrigin int       `json:"effort_origin"`
-	PIC          string    `json:"pic"`
-	PlanStart    time.Time `json:"plan_start"`
-	PlanEnd      time.Time `json:"plan_end"`
-	ActualStart  *time.Time `json:"actual_start,omitempty"`
-	ActualEnd    *time.Time `json:"actual_end,omitempty"`
-	Status       Status    `json:"status"`
-	SubTasks     []SubTask `json:"subtasks"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string          `json:"id"`
+	BacklogID    string          `json:"backlog_id"`
+	ExternalKey  string          `json:"external_key,omitempty"`
+	Title        string          `json:"title"`
+	Description  string          `json:"description"`
+	JiraURL      string          `json:"jira_url"`
+	EffortOrigin int             `json:"effort_origin"`
+	PIC          string          `json:"pic"`
+	PlanStart    time.Time       `json:"plan_start"`
+	PlanEnd      time.Time       `json:"plan_end"`
+	ActualStart  *time.Time      `json:"actual_start,omitempty"`
+	ActualEnd    *time.Time      `json:"actual_end,omitempty"`
+	Status       Status          `json:"status"`
+	Priority     Priority        `json:"priority"`
+	Labels       []string        `json:"labels"`
+	CustomFields CustomValues    `json:"custom_fields"`
+	Checklist    []ChecklistItem `json:"checklist"`
+	SeriesID     string          `json:"series_id,omitempty"`
+	Occurrence   int             `json:"occurrence,omitempty"`
+	CommentCount int             `json:"comment_count"`
+	Blocked      bool            `json:"blocked"`
+	BlockedBy    []string        `json:"blocked_by,omitempty"`
+	SubTasks     []SubTask       `json:"subtasks"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
 }
 
 // SubTask represents a subtask within a story
 type SubTask struct {
-	ID          string     `json:"id"`
-	StoryID     string     `json:"story_id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Effort      int        `json:"effort"`
-	JiraURL     string     `json:"jira_url"`
-	PIC         string     `json:"pic"`
-	PlanStart   time.Time  `json:"plan_start"`
-	PlanEnd     time.Time  `json:"plan_end"`
-	ActualStart *time.Time `json:"actual_start,omitempty"`
-	ActualEnd   *time.Time `json:"actual_end,omitempty"`
-	Status      Status     `json:"status"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           string          `json:"id"`
+	StoryID      string          `json:"story_id"`
+	ExternalKey  string          `json:"external_key,omitempty"`
+	Title        string          `json:"title"`
+	Description  string          `json:"description"`
+	Effort       int             `json:"effort"`
+	JiraURL      string          `json:"jira_url"`
+	PIC          string          `json:"pic"`
+	PlanStart    time.Time       `json:"plan_start"`
+	PlanEnd      time.Time       `json:"plan_end"`
+	ActualStart  *time.Time      `json:"actual_start,omitempty"`
+	ActualEnd    *time.Time      `json:"actual_end,omitempty"`
+	Status       Status          `json:"status"`
+	Priority     Priority        `json:"priority"`
+	Labels       []string        `json:"labels"`
+	CustomFields CustomValues    `json:"custom_fields"`
+	Checklist    []ChecklistItem `json:"checklist"`
+	CommentCount int             `json:"comment_count"`
+	Blocked      bool            `json:"blocked"`
+	BlockedBy    []string        `json:"blocked_by,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
 }
 
 // CreateBacklogRequest represents the request to create a new backlog
@@ -69,26 +99,64 @@ type CreateBacklogRequest struct {
 
 // CreateStoryRequest represents the request to create a new story
 type CreateStoryRequest struct {
-	BacklogID    string    `json:"backlog_id" validate:"required"`
-	Title        string    `json:"title" validate:"required"`
-	Description  string    `json:"description"`
-	JiraURL      string    `json:"jira_url"`
-	EffortOrigin int       `json:"effort_origin"`
-	PIC          string    `json:"pic"`
-	PlanStart    time.Time `json:"plan_start"`
-	PlanEnd      time.Time `json:"plan_end"`
+	BacklogID    string       `json:"backlog_id" validate:"required"`
+	Title        string       `json:"title" validate:"required"`
+	Description  string       `json:"description"`
+	JiraURL      string       `json:"jira_url"`
+	EffortOrigin int          `json:"effort_origin"`
+	PIC          string       `json:"pic"`
+	PlanStart    time.Time    `json:"plan_start"`
+	PlanEnd      time.Time    `json:"plan_end"`
+	Priority     Priority     `json:"priority"`
+	Labels       []string     `json:"labels"`
+	CustomFields CustomValues `json:"custom_fields"`
 }
 
 // CreateSubTaskRequest represents the request to create a new subtask
 type CreateSubTaskRequest struct {
-	StoryID     string    `json:"story_id" validate:"required"`
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description"`
-	Effort      int       `json:"effort"`
-	JiraURL     string    `json:"jira_url"`
-	PIC         string    `json:"pic"`
-	PlanStart   time.Time `json:"plan_start"`
-	PlanEnd     time.Time `json:"plan_end"`
+	StoryID      string       `json:"story_id" validate:"required"`
+	Title        string       `json:"title" validate:"required"`
+	Description  string       `json:"description"`
+	Effort       int          `json:"effort"`
+	JiraURL      string       `json:"jira_url"`
+	PIC          string       `json:"pic"`
+	PlanStart    time.Time    `json:"plan_start"`
+	PlanEnd      time.Time    `json:"plan_end"`
+	Priority     Priority     `json:"priority"`
+	Labels       []string     `json:"labels"`
+	CustomFields CustomValues `json:"custom_fields"`
+}
+
+// UpdateStoryRequest represents the request to update a story. Fields left
+// out of the request are not changed.
+type UpdateStoryRequest struct {
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	JiraURL      *string    `json:"jira_url"`
+	EffortOrigin *int       `json:"effort_origin"`
+	PIC          *string    `json:"pic"`
+	PlanStart    *time.Time `json:"plan_start"`
+	PlanEnd      *time.Time `json:"plan_end"`
+	Priority     *Priority  `json:"priority"`
+	Labels       *[]string  `json:"labels"`
+	// CustomFields sets the given values; a null value clears the field
+	CustomFields CustomValues `json:"custom_fields"`
+}
+
+// UpdateSubTaskRequest represents the request to update a subtask. Fields
+// left out of the request are not changed.
+type UpdateSubTaskRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Effort      *int       `json:"effort"`
+	JiraURL     *string    `json:"jira_url"`
+	PIC         *string    `json:"pic"`
+	PlanStart   *time.Time `json:"plan_start"`
+	PlanEnd     *time.Time `json:"plan_end"`
+	Priority    *Priority  `json:"priority"`
+	Labels      *[]string  `json:"labels"`
+	// CustomFields sets the given values; a null value clears the field
+	CustomFields CustomValues `json:"custom_fields"`
 }
 
 // UpdateStatusRequest represents the request to update status
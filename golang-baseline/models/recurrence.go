@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a recurring story repeats
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+// IsValid reports whether the frequency is supported
+func (f Frequency) IsValid() bool {
+	switch f {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+// RecurrenceRule describes when a story repeats, modelled on the iCalendar
+// RRULE: every Interval days, weeks or months, ending after Count
+// occurrences or on Until, whichever comes first. Neither means forever.
+type RecurrenceRule struct {
+	Frequency Frequency  `json:"frequency"`
+	Interval  int        `json:"interval"`
+	Count     int        `json:"count,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// String formats the rule as an RRULE value
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency), "INTERVAL=" + strconv.Itoa(r.Interval)}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseRecurrenceRule reads an RRULE value such as
+// "FREQ=WEEKLY;INTERVAL=2;COUNT=10". Only FREQ, INTERVAL, COUNT and UNTIL
+// are understood; UNTIL may be a date or a UTC date-time.
+func ParseRecurrenceRule(value string) (RecurrenceRule, error) {
+	var rule RecurrenceRule
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		name, arg, found := strings.Cut(part, "=")
+		if !found {
+			return rule, fmt.Errorf("malformed rule part %q", part)
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Frequency = Frequency(strings.ToUpper(arg))
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("%s must be a positive number", strings.ToUpper(name))
+			}
+			if strings.EqualFold(name, "INTERVAL") {
+				rule.Interval = n
+			} else {
+				rule.Count = n
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", arg)
+			if err != nil {
+				until, err = time.Parse("20060102", arg)
+			}
+			if err != nil {
+				return rule, fmt.Errorf("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+			}
+			rule.Until = &until
+		default:
+			return rule, fmt.Errorf("unsupported rule part %q", name)
+		}
+	}
+	return rule, nil
+}
+
+// Recurrence is a series of stories generated from a rule. The first
+// occurrence is the story the rule was set on; each later one is a copy of
+// the one before, planned on the next date of the rule.
+type Recurrence struct {
+	ID        string         `json:"id"`
+	BacklogID string         `json:"backlog_id"`
+	Rule      RecurrenceRule `json:"rule"`
+	RRule     string         `json:"rrule"`
+	// Anchor is the plan start of the first occurrence; later occurrences
+	// are computed from it so months of different lengths do not drift
+	Anchor time.Time `json:"anchor"`
+	// Generated is the number of the last occurrence that was created
+	Generated int        `json:"generated"`
+	NextAt    *time.Time `json:"next_at,omitempty"`
+	Finished  bool       `json:"finished"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SetRecurrenceRequest represents the request to make a story recurring.
+// The rule may be given as an RRULE string or as separate fields.
+type SetRecurrenceRequest struct {
+	RRule     string     `json:"rrule"`
+	Frequency Frequency  `json:"frequency"`
+	Interval  int        `json:"interval"`
+	Count     int        `json:"count"`
+	Until     *time.Time `json:"until"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// DateLayout is the layout used for calendar dates such as holidays
+const DateLayout = "2006-01-02"
+
+// Calendar describes which days are available for work
+type Calendar struct {
+	// WorkingDays lists the working weekdays, 0 being Sunday
+	WorkingDays []time.Weekday `json:"working_days"`
+	// Holidays lists non-working dates formatted as YYYY-MM-DD
+	Holidays []string `json:"holidays"`
+}
+
+// DefaultCalendar returns a Monday to Friday calendar without holidays
+func DefaultCalendar() Calendar {
+	return Calendar{
+		WorkingDays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Holidays:    []string{},
+	}
+}
+
+// IsWorkingDay reports whether work can be scheduled on the given date
+func (c Calendar) IsWorkingDay(t time.Time) bool {
+	date := t.Format(DateLayout)
+	for _, holiday := range c.Holidays {
+		if holiday == date {
+			return false
+		}
+	}
+	for _, weekday := range c.WorkingDays {
+		if weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// Capacity represents how many hours per day a person can work
+type Capacity struct {
+	PIC         string  `json:"pic"`
+	HoursPerDay float64 `json:"hours_per_day"`
+}
+
+// ScheduleRequest represents the request to auto-schedule a backlog
+type ScheduleRequest struct {
+	StartDate time.Time `json:"start_date" validate:"required"`
+	Apply     bool      `json:"apply"`
+}
+
+// ScheduleChange describes the plan dates proposed for a story or subtask
+type ScheduleChange struct {
+	ID        string    `json:"id"`
+	ItemType  ItemType  `json:"item_type"`
+	Title     string    `json:"title"`
+	PIC       string    `json:"pic"`
+	OldStart  time.Time `json:"old_start"`
+	OldEnd    time.Time `json:"old_end"`
+	NewStart  time.Time `json:"new_start"`
+	NewEnd    time.Time `json:"new_end"`
+	Unchanged bool      `json:"unchanged"`
+}
+
+// ScheduleResult represents the outcome of scheduling a backlog
+type ScheduleResult struct {
+	BacklogID string           `json:"backlog_id"`
+	Applied   bool             `json:"applied"`
+	Changes   []ScheduleChange `json:"changes"`
+}
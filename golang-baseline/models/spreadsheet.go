@@ -0,0 +1,45 @@
+package models
+
+// Spreadsheet import modes
+const (
+	// SheetUpsert updates rows matching an existing item by ID or external
+	// key and creates the others
+	SheetUpsert = "upsert"
+	// SheetCreate creates every row as a new item
+	SheetCreate = "create"
+)
+
+// SheetImportOptions controls a spreadsheet import
+type SheetImportOptions struct {
+	Mode string `json:"mode"`
+	// Preview validates the file and reports what would change without
+	// storing anything
+	Preview bool `json:"preview"`
+	// Mapping maps column headers of the file to the column names used by
+	// the export, such as {"Summary": "title"}. Unmapped headers are matched
+	// by name.
+	Mapping map[string]string `json:"mapping"`
+}
+
+// SheetRowResult reports the outcome of one spreadsheet row. Row is the
+// line number in the file, counting the header as line 1.
+type SheetRowResult struct {
+	Row    int      `json:"row"`
+	Type   ItemType `json:"type,omitempty"`
+	ID     string   `json:"id,omitempty"`
+	Action string   `json:"action,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SheetImportResult reports the outcome of a spreadsheet import. When any
+// row has errors, or in preview mode, nothing is stored.
+type SheetImportResult struct {
+	Mode    string            `json:"mode"`
+	Preview bool              `json:"preview"`
+	Columns map[string]string `json:"columns"`
+	Ignored []string          `json:"ignored,omitempty"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []SheetRowResult  `json:"rows"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// StoryTemplate captures a story and its subtasks so they can be created
+// again. Plan dates are stored as day offsets from the instantiation date.
+type StoryTemplate struct {
+	ID          string            `json:"id"`
+	WorkspaceID string            `json:"workspace_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Story       TemplateStory     `json:"story"`
+	SubTasks    []TemplateSubTask `json:"subtasks"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TemplateStory describes the story created from a template
+type TemplateStory struct {
+	Title        string              `json:"title"`
+	Description  string              `json:"description"`
+	EffortOrigin int                 `json:"effort_origin"`
+	PIC          string              `json:"pic"`
+	Priority     Priority            `json:"priority"`
+	Labels       []string            `json:"labels"`
+	DurationDays int                 `json:"duration_days"`
+	Checklist    []TemplateCheckItem `json:"checklist"`
+}
+
+// TemplateSubTask describes a subtask created from a template. OffsetDays
+// is counted from the start date the template is instantiated with.
+type TemplateSubTask struct {
+	Title        string              `json:"title"`
+	Description  string              `json:"description"`
+	Effort       int                 `json:"effort"`
+	PIC          string              `json:"pic"`
+	Priority     Priority            `json:"priority"`
+	Labels       []string            `json:"labels"`
+	OffsetDays   int                 `json:"offset_days"`
+	DurationDays int                 `json:"duration_days"`
+	Checklist    []TemplateCheckItem `json:"checklist"`
+}
+
+// TemplateCheckItem describes a checklist item created from a template
+type TemplateCheckItem struct {
+	Text     string `json:"text"`
+	Required bool   `json:"required"`
+}
+
+// CreateTemplateRequest represents the request to create a template
+type CreateTemplateRequest struct {
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	Story       TemplateStory     `json:"story" validate:"required"`
+	SubTasks    []TemplateSubTask `json:"subtasks"`
+}
+
+// CreateTemplateFromStoryRequest represents the request to capture an
+// existing story as a template
+type CreateTemplateFromStoryRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// InstantiateTemplateRequest represents the request to create a story from
+// a template. Template labels are resolved by name in the target backlog.
+type InstantiateTemplateRequest struct {
+	BacklogID    string       `json:"backlog_id" validate:"required"`
+	StartDate    time.Time    `json:"start_date" validate:"required"`
+	CustomFields CustomValues `json:"custom_fields"`
+}
+
+// CloneStoryRequest represents the request to deep-clone a story. The clone
+// goes to the same backlog unless BacklogID is set, and keeps its plan
+// dates unless StartDate is set.
+type CloneStoryRequest struct {
+	BacklogID string     `json:"backlog_id"`
+	StartDate *time.Time `json:"start_date"`
+}
+
+// CloneBacklogRequest represents the request to deep-clone a backlog. Plan
+// dates are shifted so the earliest one falls on StartDate when it is set.
+type CloneBacklogRequest struct {
+	Title     string     `json:"title"`
+	StartDate *time.Time `json:"start_date"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// User represents a person who can be mentioned, assigned or named as author
+type User struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Admin users manage the users, teams, roles, webhooks and settings
+	// of their workspace, and have every permission on its backlogs
+	Admin bool `json:"admin"`
+
+	// Roles are held on every backlog of the workspace. Users who sign in
+	// with single sign-on get them from their groups at every login.
+	Roles []string `json:"roles,omitempty"`
+
+	// SSOSubject identifies the account of users who sign in with single
+	// sign-on, as the issuer and subject of their ID tokens
+	SSOSubject string `json:"sso_subject,omitempty"`
+
+	// PasswordHash is the bcrypt hash of the password of users who log in
+	// locally. It is never serialised.
+	PasswordHash string `json:"-"`
+}
+
+// CreateUserRequest represents the request to register a user
+type CreateUserRequest struct {
+	Username    string `json:"username" validate:"required"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	// Password lets the user log in; users without one can only use API
+	// keys or tokens from another issuer
+	Password string `json:"password"`
+	Admin    bool   `json:"admin"`
+}
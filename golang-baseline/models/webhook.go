@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// Webhook is a subscription to events. Events may hold exact event names,
+// wildcards such as "story.*", or "*"; an empty list receives everything.
+// The secret is only returned when the webhook is created.
+type Webhook struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateWebhookRequest creates a webhook. A secret is generated when none
+// is given.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+	Description string   `json:"description"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url"`
+	Secret      *string   `json:"secret"`
+	Events      *[]string `json:"events"`
+	Active      *bool     `json:"active"`
+	Description *string   `json:"description"`
+}
+
+// WebhookPayload is the JSON body of a delivery
+type WebhookPayload struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// StatusChange is the data of status changed events
+type StatusChange struct {
+	From Status      `json:"from"`
+	To   Status      `json:"to"`
+	Item interface{} `json:"item"`
+}
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryRetrying  = "retrying"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, with every attempt
+type WebhookDelivery struct {
+	ID            string           `json:"id"`
+	WebhookID     string           `json:"webhook_id"`
+	EventID       string           `json:"event_id"`
+	Event         string           `json:"event"`
+	Status        string           `json:"status"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// WebhookAttempt is one request made for a delivery
+type WebhookAttempt struct {
+	Number     int       `json:"number"`
+	At         time.Time `json:"at"`
+	DurationMS int64     `json:"duration_ms"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
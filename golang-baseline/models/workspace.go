@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// DefaultWorkspace is the workspace that exists on every instance. Data
+// created before workspaces were introduced, and by callers that do not
+// choose one, belongs to it.
+const DefaultWorkspace = "default"
+
+// Workspace owns backlogs, users and teams. Data of one workspace is never
+// visible from another.
+type Workspace struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Settings  WorkspaceSettings `json:"settings"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WorkspaceSettings are the settings a workspace overrides. Custom fields
+// shared by every backlog of a workspace are managed on their own.
+type WorkspaceSettings struct {
+	Workflow WorkflowSettings `json:"workflow"`
+	Calendar Calendar         `json:"calendar"`
+}
+
+// WorkflowSettings are the workflow rules of a workspace. Empty policies
+// and a nil RequireChecklistForDone fall back to the server configuration.
+type WorkflowSettings struct {
+	BlockedStatusPolicy     string `json:"blocked_status_policy,omitempty"`
+	WIPLimitPolicy          string `json:"wip_limit_policy,omitempty"`
+	RequireChecklistForDone *bool  `json:"require_checklist_for_done,omitempty"`
+}
+
+// CreateWorkspaceRequest represents the request to create a workspace. The
+// calendar defaults to Monday to Friday without holidays.
+type CreateWorkspaceRequest struct {
+	Name     string           `json:"name" validate:"required"`
+	Workflow WorkflowSettings `json:"workflow"`
+	Calendar *Calendar        `json:"calendar"`
+}
+
+// UpdateWorkspaceRequest represents the request to rename a workspace or
+// replace its settings. Fields left out of the request are not changed.
+type UpdateWorkspaceRequest struct {
+	Name     *string           `json:"name"`
+	Workflow *WorkflowSettings `json:"workflow"`
+	Calendar *Calendar         `json:"calendar"`
+}
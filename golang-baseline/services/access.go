@@ -0,0 +1,566 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// builtInRoles are the roles every installation has
+var builtInRoles = []models.Role{
+	{ID: models.RoleOwner, Name: models.RoleOwner, Description: "Full control of the backlog",
+		Permissions: []string{models.PermView, models.PermComment, models.PermEdit, models.PermManage}, BuiltIn: true},
+	{ID: models.RoleEditor, Name: models.RoleEditor, Description: "Plans and updates work items",
+		Permissions: []string{models.PermView, models.PermComment, models.PermEdit}, BuiltIn: true},
+	{ID: models.RoleViewer, Name: models.RoleViewer, Description: "Reads the backlog",
+		Permissions: []string{models.PermView}, BuiltIn: true},
+}
+
+// Access checks. Requests without a principal are not checked: they come
+// from the application itself or are served while authentication is not
+// required.
+
+// isAdmin reports whether the request may do anything in its workspace.
+// Callers must hold the lock.
+func (s *Service) isAdmin() bool {
+	principal := s.request.Principal
+	if principal == nil || principal.Method == models.AuthBootstrap {
+		return true
+	}
+	user, exists := s.users[principal.UserID]
+	return exists && user.Admin
+}
+
+// requireAdmin refuses requests that are not made by an admin. Callers
+// must hold the lock.
+func (s *Service) requireAdmin() error {
+	if !s.isAdmin() {
+		return fmt.Errorf("%w: only admins may do this", ErrForbidden)
+	}
+	return nil
+}
+
+// requireManager refuses requests that may not manage any backlog of the
+// workspace. Epics, milestones and templates span backlogs, so managing
+// one of them is enough to create them. Callers must hold the lock.
+func (s *Service) requireManager() error {
+	if s.isAdmin() {
+		return nil
+	}
+	for id, backlog := range s.backlogs {
+		if backlog.WorkspaceID == s.workspace() && s.can(id, models.PermManage) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s permission on a backlog is required", ErrForbidden, models.PermManage)
+}
+
+// authorizeGroup refuses changes to an epic or milestone unless the
+// request may manage the backlogs of all its stories, or any backlog when
+// it has none. Callers must hold the lock.
+func (s *Service) authorizeGroup(storyIDs []string) error {
+	if len(storyIDs) == 0 {
+		return s.requireManager()
+	}
+	return s.authorizeStories(storyIDs, models.PermManage)
+}
+
+// can reports whether the request has a permission on a backlog, through
+// the roles of its user, or those granted to the user or its teams on the
+// backlog. Nobody has permissions on the backlogs of another workspace.
+// Callers must hold the lock.
+func (s *Service) can(backlogID, permission string) bool {
+	backlog, exists := s.backlogs[backlogID]
+	if !exists || backlog.WorkspaceID != s.workspace() {
+		return false
+	}
+	if s.isAdmin() {
+		return true
+	}
+	userID := s.request.Principal.UserID
+	user, exists := s.users[userID]
+	if !exists {
+		return false
+	}
+	for _, name := range user.Roles {
+		if role := s.role(name, backlog.WorkspaceID); role != nil && slices.Contains(role.Permissions, permission) {
+			return true
+		}
+	}
+	for _, grant := range s.grants {
+		if grant.BacklogID != backlogID {
+			continue
+		}
+		if grant.UserID != userID && (grant.TeamID == "" || !s.inTeam(grant.TeamID, userID)) {
+			continue
+		}
+		if role := s.role(grant.Role, backlog.WorkspaceID); role != nil && slices.Contains(role.Permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize refuses a request without a permission on a backlog. Missing
+// backlogs pass, so the caller reports them as not found. Callers must
+// hold the lock.
+func (s *Service) authorize(backlogID, permission string) error {
+	backlog, exists := s.backlogs[backlogID]
+	if !exists {
+		return nil
+	}
+	if backlog.WorkspaceID != s.workspace() {
+		return fmt.Errorf("%w: the backlog belongs to another workspace", ErrForbidden)
+	}
+	if !s.can(backlogID, permission) {
+		return fmt.Errorf("%w: %s permission on the backlog is required", ErrForbidden, permission)
+	}
+	return nil
+}
+
+// authorizeItem refuses a request without a permission on the backlog of a
+// story or subtask. Missing items pass. Callers must hold the lock.
+func (s *Service) authorizeItem(itemType models.ItemType, id, permission string) error {
+	backlogID, _ := s.itemScope(itemType, id)
+	if backlogID == "" {
+		return nil
+	}
+	return s.authorize(backlogID, permission)
+}
+
+// authorizeStories refuses a request without a permission on the backlog
+// of every story. Missing stories pass. Callers must hold the lock.
+func (s *Service) authorizeStories(ids []string, permission string) error {
+	for _, id := range ids {
+		if err := s.authorizeItem(models.ItemStory, id, permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inTeam reports whether a user is a member of a team. Callers must hold
+// the lock.
+func (s *Service) inTeam(teamID, userID string) bool {
+	team, exists := s.teams[teamID]
+	return exists && slices.Contains(team.Members, userID)
+}
+
+// role finds a built-in role, or a custom role of a workspace, by name.
+// Callers must hold the lock.
+func (s *Service) role(name, workspaceID string) *models.Role {
+	for i := range builtInRoles {
+		if strings.EqualFold(builtInRoles[i].Name, name) {
+			return &builtInRoles[i]
+		}
+	}
+	for _, role := range s.roles {
+		if role.WorkspaceID == workspaceID && strings.EqualFold(role.Name, name) {
+			return role
+		}
+	}
+	return nil
+}
+
+// grantOwner makes the user of the request owner of a new backlog. Callers
+// must hold the lock.
+func (s *Service) grantOwner(backlogID string) {
+	principal := s.request.Principal
+	if principal == nil || principal.UserID == "" {
+		return
+	}
+	grant := &models.Grant{
+		ID:        uuid.New().String(),
+		BacklogID: backlogID,
+		Role:      models.RoleOwner,
+		UserID:    principal.UserID,
+		GrantedBy: principal.Username,
+		CreatedAt: time.Now(),
+	}
+	s.grants[grant.ID] = grant
+	s.publish(models.EventGrantCreated, nil, s.snapshot(grant))
+}
+
+// validatePermissions checks and deduplicates the permissions of a role
+func validatePermissions(permissions []string) ([]string, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("%w: a role needs at least one permission", ErrInvalidRequest)
+	}
+	result := []string{}
+	for _, permission := range permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !slices.Contains(models.Permissions, permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q, expected one of %s",
+				ErrInvalidRequest, permission, strings.Join(models.Permissions, ", "))
+		}
+		if !slices.Contains(result, permission) {
+			result = append(result, permission)
+		}
+	}
+	return result, nil
+}
+
+// Role operations
+func (s *Service) CreateRole(req models.CreateRoleRequest) (*models.Role, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if s.role(name, s.workspace()) != nil {
+		return nil, fmt.Errorf("%w: role %q already exists", ErrInvalidRequest, name)
+	}
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Name:        name,
+		Description: req.Description,
+		Permissions: permissions,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	s.roles[role.ID] = role
+	s.publish(models.EventRoleCreated, nil, s.snapshot(role))
+	return roleView(role), nil
+}
+
+// GetRoles returns the built-in roles followed by the custom ones of the
+// workspace by name
+func (s *Service) GetRoles() []models.Role {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	custom := []models.Role{}
+	for _, role := range s.roles {
+		if role.WorkspaceID == s.workspace() {
+			custom = append(custom, *roleView(role))
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Name < custom[j].Name })
+	return append(append([]models.Role{}, builtInRoles...), custom...)
+}
+
+func (s *Service) UpdateRole(id string, req models.UpdateRoleRequest) (*models.Role, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	role, exists := s.roles[id]
+	if !exists || role.WorkspaceID != s.workspace() {
+		return nil, errors.New("role not found")
+	}
+	var permissions []string
+	if req.Permissions != nil {
+		var err error
+		if permissions, err = validatePermissions(*req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	before := s.snapshot(role)
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		role.Permissions = permissions
+	}
+	role.UpdatedAt = time.Now()
+	s.publish(models.EventRoleUpdated, before, s.snapshot(role))
+	return roleView(role), nil
+}
+
+// DeleteRole deletes a custom role that is no longer granted
+func (s *Service) DeleteRole(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return err
+	}
+	role, exists := s.roles[id]
+	if !exists || role.WorkspaceID != s.workspace() {
+		return errors.New("role not found")
+	}
+	for _, grant := range s.grants {
+		if strings.EqualFold(grant.Role, role.Name) && s.workspaceOf(grant.BacklogID) == role.WorkspaceID {
+			return fmt.Errorf("%w: role %q is still granted", ErrInvalidRequest, role.Name)
+		}
+	}
+
+	deleted := s.snapshot(role)
+	delete(s.roles, id)
+	s.publish(models.EventRoleDeleted, deleted, nil)
+	return nil
+}
+
+// Team operations
+func (s *Service) CreateTeam(req models.CreateTeamRequest) (*models.Team, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	members, err := s.validateMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Name:        name,
+		Members:     members,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	s.teams[team.ID] = team
+	s.publish(models.EventTeamCreated, nil, s.snapshot(team))
+	return teamView(team), nil
+}
+
+func (s *Service) GetTeam(id string) (*models.Team, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	team, exists := s.teams[id]
+	if !exists || team.WorkspaceID != s.workspace() {
+		return nil, errors.New("team not found")
+	}
+	return teamView(team), nil
+}
+
+func (s *Service) GetAllTeams() ([]*models.Team, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	teams := []*models.Team{}
+	for _, team := range s.teams {
+		if team.WorkspaceID == s.workspace() {
+			teams = append(teams, teamView(team))
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams, nil
+}
+
+func (s *Service) UpdateTeam(id string, req models.UpdateTeamRequest) (*models.Team, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	team, exists := s.teams[id]
+	if !exists || team.WorkspaceID != s.workspace() {
+		return nil, errors.New("team not found")
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidRequest)
+	}
+	var members []string
+	if req.Members != nil {
+		var err error
+		if members, err = s.validateMembers(*req.Members); err != nil {
+			return nil, err
+		}
+	}
+
+	before := s.snapshot(team)
+	if req.Name != nil {
+		team.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Members != nil {
+		team.Members = members
+	}
+	team.UpdatedAt = time.Now()
+	s.publish(models.EventTeamUpdated, before, s.snapshot(team))
+	return teamView(team), nil
+}
+
+// DeleteTeam deletes a team and the roles granted to it
+func (s *Service) DeleteTeam(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return err
+	}
+	team, exists := s.teams[id]
+	if !exists || team.WorkspaceID != s.workspace() {
+		return errors.New("team not found")
+	}
+
+	for grantID, grant := range s.grants {
+		if grant.TeamID == id {
+			deleted := s.snapshot(grant)
+			delete(s.grants, grantID)
+			s.publish(models.EventGrantDeleted, deleted, nil)
+		}
+	}
+	deleted := s.snapshot(team)
+	delete(s.teams, id)
+	s.publish(models.EventTeamDeleted, deleted, nil)
+	return nil
+}
+
+// roleView returns a copy of a role, which may be updated while the copy
+// is encoded
+func roleView(role *models.Role) *models.Role {
+	view := *role
+	view.Permissions = slices.Clone(role.Permissions)
+	return &view
+}
+
+// teamView returns a copy of a team, which may be updated while the copy
+// is encoded
+func teamView(team *models.Team) *models.Team {
+	view := *team
+	view.Members = slices.Clone(team.Members)
+	return &view
+}
+
+// validateMembers checks that team members are users of the workspace.
+// Callers must hold the lock.
+func (s *Service) validateMembers(members []string) ([]string, error) {
+	result := []string{}
+	for _, userID := range members {
+		if user, exists := s.users[userID]; !exists || user.WorkspaceID != s.workspace() {
+			return nil, fmt.Errorf("%w: user %s not found", ErrInvalidRequest, userID)
+		}
+		result = addMembers(result, []string{userID})
+	}
+	return result, nil
+}
+
+// Grant operations
+func (s *Service) CreateGrant(backlogID string, req models.CreateGrantRequest) (*models.Grant, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermManage); err != nil {
+		return nil, err
+	}
+	role := s.role(req.Role, s.workspaceOf(backlogID))
+	if role == nil {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidRequest, req.Role)
+	}
+	if (req.UserID == "") == (req.TeamID == "") {
+		return nil, fmt.Errorf("%w: grant a role to either a user or a team", ErrInvalidRequest)
+	}
+	if user, exists := s.users[req.UserID]; req.UserID != "" && (!exists || user.WorkspaceID != s.workspace()) {
+		return nil, fmt.Errorf("%w: user %s not found", ErrInvalidRequest, req.UserID)
+	}
+	if team, exists := s.teams[req.TeamID]; req.TeamID != "" && (!exists || team.WorkspaceID != s.workspace()) {
+		return nil, fmt.Errorf("%w: team %s not found", ErrInvalidRequest, req.TeamID)
+	}
+	for _, grant := range s.grants {
+		if grant.BacklogID == backlogID && grant.UserID == req.UserID && grant.TeamID == req.TeamID &&
+			strings.EqualFold(grant.Role, role.Name) {
+			return nil, fmt.Errorf("%w: the role is already granted", ErrInvalidRequest)
+		}
+	}
+
+	grant := &models.Grant{
+		ID:        uuid.New().String(),
+		BacklogID: backlogID,
+		Role:      role.Name,
+		UserID:    req.UserID,
+		TeamID:    req.TeamID,
+		GrantedBy: s.request.Actor,
+		CreatedAt: time.Now(),
+	}
+	s.grants[grant.ID] = grant
+	s.publish(models.EventGrantCreated, nil, s.snapshot(grant))
+	return grant, nil
+}
+
+// GetGrantsByBacklog returns the roles granted on a backlog, oldest first
+func (s *Service) GetGrantsByBacklog(backlogID string) ([]*models.Grant, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	grants := []*models.Grant{}
+	for _, grant := range s.grants {
+		if grant.BacklogID == backlogID {
+			grants = append(grants, grant)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].CreatedAt.Before(grants[j].CreatedAt) })
+	return grants, nil
+}
+
+// DeleteGrant revokes a role. The last owner of a backlog cannot be
+// removed, so someone can always manage it.
+func (s *Service) DeleteGrant(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	grant, exists := s.grants[id]
+	if !exists {
+		return errors.New("grant not found")
+	}
+	if err := s.authorize(grant.BacklogID, models.PermManage); err != nil {
+		return err
+	}
+	if strings.EqualFold(grant.Role, models.RoleOwner) {
+		owners := 0
+		for _, other := range s.grants {
+			if other.BacklogID == grant.BacklogID && strings.EqualFold(other.Role, models.RoleOwner) {
+				owners++
+			}
+		}
+		if owners == 1 {
+			return fmt.Errorf("%w: a backlog needs at least one owner", ErrInvalidRequest)
+		}
+	}
+
+	deleted := s.snapshot(grant)
+	delete(s.grants, id)
+	s.publish(models.EventGrantDeleted, deleted, nil)
+	return nil
+}
+
+// visibleBacklogs returns the IDs of the backlogs the request may view.
+// Callers must hold the lock.
+func (s *Service) visibleBacklogs() map[string]bool {
+	visible := make(map[string]bool, len(s.backlogs))
+	for id := range s.backlogs {
+		if s.can(id, models.PermView) {
+			visible[id] = true
+		}
+	}
+	return visible
+}
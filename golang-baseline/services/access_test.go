@@ -0,0 +1,51 @@
+package services
+
+import (
+	"golang-baseline/models"
+	"testing"
+)
+
+func TestRolesAndTeamsAreReturnedAsCopies(t *testing.T) {
+	s := newTestService(t, nil)
+	user, err := s.CreateUser(models.CreateUserRequest{Username: "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role, err := s.CreateRole(models.CreateRoleRequest{Name: "triager", Permissions: []string{models.PermView}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := s.GetRoles()
+	permissions := []string{models.PermView, models.PermComment}
+	description := "Sorts new work"
+	if _, err := s.UpdateRole(role.ID, models.UpdateRoleRequest{Description: &description, Permissions: &permissions}); err != nil {
+		t.Fatal(err)
+	}
+	if len(role.Permissions) != 1 || role.Description != "" {
+		t.Errorf("created role changed: %+v", role)
+	}
+	if custom := roles[len(roles)-1]; custom.Name != "triager" || len(custom.Permissions) != 1 {
+		t.Errorf("listed role changed: %+v", custom)
+	}
+
+	team, err := s.CreateTeam(models.CreateTeamRequest{Name: "Core"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetTeam(team.ID)
+	all, _ := s.GetAllTeams()
+	name, members := "Platform", []string{user.ID}
+	updated, err := s.UpdateTeam(team.ID, models.UpdateTeamRequest{Name: &name, Members: &members})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, before := range []*models.Team{team, got, all[0]} {
+		if before.Name != "Core" || len(before.Members) != 0 {
+			t.Errorf("returned team changed: %+v", before)
+		}
+	}
+	if updated.Name != "Platform" || len(updated.Members) != 1 {
+		t.Errorf("updated team = %+v", updated)
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"golang-baseline/storage"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sniffLen is the number of bytes inspected to detect a content type
+const sniffLen = 512
+
+// UploadRequest describes a file uploaded to a story or subtask. UploadedBy
+// defaults to the user of the request; only admins may name someone else.
+type UploadRequest struct {
+	FileName    string
+	ContentType string
+	UploadedBy  string
+	Content     io.Reader
+}
+
+// Attachment operations
+
+// MaxAttachmentSize returns the largest upload accepted, in bytes
+func (s *Service) MaxAttachmentSize() int64 {
+	return s.config.AttachmentMaxSize
+}
+
+// CreateAttachment stores an uploaded file and attaches it to a work item.
+// The content is written to the blob store before the lock is taken, so a
+// large upload does not hold up other requests.
+func (s *Service) CreateAttachment(itemType models.ItemType, itemID string, req UploadRequest) (*models.Attachment, error) {
+	s.mutex.RLock()
+	err := s.checkItemExists(itemType, itemID)
+	if err == nil {
+		err = s.authorizeItem(itemType, itemID, models.PermEdit)
+	}
+	uploadedBy := req.UploadedBy
+	if err == nil {
+		uploadedBy, err = s.uploader(req.UploadedBy)
+	}
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := filepath.Base(strings.ReplaceAll(req.FileName, "\\", "/"))
+	if fileName == "." || fileName == "/" || fileName == "" {
+		return nil, fmt.Errorf("%w: file name is required", ErrInvalidRequest)
+	}
+
+	// Look at the first bytes to settle the content type before storing
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(req.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := detectContentType(fileName, req.ContentType, head)
+	if !s.allowedContentType(contentType) {
+		return nil, fmt.Errorf("%w: files of type %s are not allowed", ErrInvalidAttachment, contentType)
+	}
+
+	maxSize := s.config.AttachmentMaxSize
+	content := io.MultiReader(bytes.NewReader(head), req.Content)
+	hash, size, err := s.blobs.Put(io.LimitReader(content, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if size > maxSize {
+		s.releaseBlob(hash)
+		return nil, fmt.Errorf("%w: files may not be larger than %d bytes", ErrAttachmentTooLarge, maxSize)
+	}
+	// The item may have been deleted, or the permission revoked, while the
+	// file was being stored
+	err = s.checkItemExists(itemType, itemID)
+	if err == nil {
+		err = s.authorizeItem(itemType, itemID, models.PermEdit)
+	}
+	if err != nil {
+		s.releaseBlob(hash)
+		return nil, err
+	}
+	// Deleting the last attachment sharing this content may have removed
+	// the blob while it was being stored
+	if exists, err := s.blobs.Exists(hash); err != nil || !exists {
+		return nil, errors.New("upload was interrupted, please retry")
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New().String(),
+		ItemType:    itemType,
+		ItemID:      itemID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		Hash:        hash,
+		UploadedBy:  uploadedBy,
+		CreatedAt:   time.Now(),
+	}
+
+	s.attachments[attachment.ID] = attachment
+	s.publish(models.EventAttachmentCreated, nil, s.snapshot(attachment))
+	return attachment, nil
+}
+
+// uploader returns the username an upload is recorded under: the user of
+// the request, or the member an admin names. Callers must hold the lock.
+func (s *Service) uploader(username string) (string, error) {
+	if username == "" {
+		return s.requestUsername(), nil
+	}
+	if !s.isAdmin() && !strings.EqualFold(username, s.requestUsername()) {
+		return "", fmt.Errorf("%w: files can only be uploaded as yourself", ErrForbidden)
+	}
+	member := s.memberByUsername(username)
+	if member == nil {
+		return "", fmt.Errorf("%w: uploaded_by %q is not a known user", ErrInvalidRequest, username)
+	}
+	return member.Username, nil
+}
+
+func (s *Service) GetAttachments(itemType models.ItemType, itemID string) ([]*models.Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.checkItemExists(itemType, itemID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeItem(itemType, itemID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	attachments := []*models.Attachment{}
+	for _, attachment := range s.attachments {
+		if attachment.ItemType == itemType && attachment.ItemID == itemID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+	})
+
+	return attachments, nil
+}
+
+func (s *Service) GetAttachment(id string) (*models.Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attachment, exists := s.attachments[id]
+	if !exists {
+		return nil, errors.New("attachment not found")
+	}
+	if err := s.authorizeItem(attachment.ItemType, attachment.ItemID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// OpenAttachment returns an attachment together with its content. The
+// caller must close the blob.
+func (s *Service) OpenAttachment(id string) (*models.Attachment, storage.Blob, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attachment, exists := s.attachments[id]
+	if !exists {
+		return nil, nil, errors.New("attachment not found")
+	}
+	if err := s.authorizeItem(attachment.ItemType, attachment.ItemID, models.PermView); err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.blobs.Open(attachment.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, blob, nil
+}
+
+func (s *Service) DeleteAttachment(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	attachment, exists := s.attachments[id]
+	if !exists {
+		return errors.New("attachment not found")
+	}
+	if err := s.authorizeItem(attachment.ItemType, attachment.ItemID, models.PermEdit); err != nil {
+		return err
+	}
+
+	deleted := s.snapshot(attachment)
+	delete(s.attachments, id)
+	s.releaseBlob(attachment.Hash)
+	s.publish(models.EventAttachmentDeleted, deleted, nil)
+	return nil
+}
+
+// deleteAttachmentsOf removes every attachment of a work item. Callers must
+// hold the lock.
+func (s *Service) deleteAttachmentsOf(itemType models.ItemType, itemID string) {
+	for id, attachment := range s.attachments {
+		if attachment.ItemType == itemType && attachment.ItemID == itemID {
+			deleted := s.snapshot(attachment)
+			delete(s.attachments, id)
+			s.releaseBlob(attachment.Hash)
+			s.publish(models.EventAttachmentDeleted, deleted, nil)
+		}
+	}
+}
+
+// releaseBlob deletes a blob once no attachment refers to it anymore.
+// Callers must hold the lock.
+func (s *Service) releaseBlob(hash string) {
+	for _, attachment := range s.attachments {
+		if attachment.Hash == hash {
+			return
+		}
+	}
+	// A blob that fails to delete is orphaned but harmless; the next upload
+	// of the same content reuses it
+	_ = s.blobs.Delete(hash)
+}
+
+// allowedContentType checks a content type against the configured list
+func (s *Service) allowedContentType(contentType string) bool {
+	for _, allowed := range s.config.AttachmentAllowedTypes {
+		if allowed == contentType || allowed == "*/*" {
+			return true
+		}
+		if family := strings.TrimSuffix(allowed, "*"); family != allowed && strings.HasPrefix(contentType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectContentType determines the media type of an upload. A declared
+// type is trusted unless it is generic; otherwise the file extension and
+// finally the content itself decide.
+func detectContentType(fileName, declared string, head []byte) string {
+	candidates := []string{declared, mime.TypeByExtension(filepath.Ext(fileName))}
+	for _, candidate := range candidates {
+		if mediaType, _, err := mime.ParseMediaType(candidate); err == nil && mediaType != "application/octet-stream" {
+			return mediaType
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
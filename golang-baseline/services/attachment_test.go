@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/models"
+	"strings"
+	"testing"
+)
+
+func TestUploadAsYourself(t *testing.T) {
+	s := newTestService(t, nil)
+	backlog, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Backlog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	story, err := s.CreateStory(models.CreateStoryRequest{BacklogID: backlog.ID, Title: "Story"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, user := asUser(t, s, "ada", false)
+	if _, err := s.CreateGrant(backlog.ID, models.CreateGrantRequest{Role: models.RoleEditor, UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateUser(models.CreateUserRequest{Username: "alan"}); err != nil {
+		t.Fatal(err)
+	}
+	upload := func(s *Service, uploadedBy string) (*models.Attachment, error) {
+		return s.CreateAttachment(models.ItemStory, story.ID, UploadRequest{
+			FileName:   "notes.txt",
+			UploadedBy: uploadedBy,
+			Content:    strings.NewReader("notes"),
+		})
+	}
+
+	if _, err := upload(editor, "alan"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("uploaded as another user: %v", err)
+	}
+	attachment, err := upload(editor, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.UploadedBy != "ada" {
+		t.Errorf("uploaded by %q", attachment.UploadedBy)
+	}
+
+	admin, _ := asUser(t, s, "grace", true)
+	if attachment, err := upload(admin, "ALAN"); err != nil || attachment.UploadedBy != "alan" {
+		t.Errorf("attachment = %+v, %v", attachment, err)
+	}
+	if _, err := upload(admin, "nobody"); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("uploaded as an unknown user: %v", err)
+	}
+}
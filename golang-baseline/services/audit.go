@@ -0,0 +1,157 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang-baseline/events"
+	"golang-baseline/models"
+	"sort"
+	"strings"
+)
+
+// Page sizes of the audit log query
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditActions maps the final part of event types to audit actions
+var auditActions = map[string]string{
+	"created":        models.AuditCreate,
+	"updated":        models.AuditUpdate,
+	"status_changed": models.AuditStatusChange,
+	"deleted":        models.AuditDelete,
+}
+
+// Audit operations
+
+// GetAuditLog returns the entries matching a filter, newest first
+func (s *Service) GetAuditLog(filter models.AuditFilter) []models.AuditEntry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+	return s.findAuditEntries(filter, limit)
+}
+
+// ExportAuditLog returns every entry matching a filter, oldest first, for
+// export. The limit of the filter is ignored.
+func (s *Service) ExportAuditLog(filter models.AuditFilter) []models.AuditEntry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries := s.findAuditEntries(filter, 0)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// findAuditEntries returns up to limit matching entries, newest first; a
+// limit of zero returns them all. Callers must hold the lock.
+func (s *Service) findAuditEntries(filter models.AuditFilter, limit int) []models.AuditEntry {
+	entries := []models.AuditEntry{}
+	admin := s.isAdmin()
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		entry := s.audit[i]
+		if entry.WorkspaceID != s.workspace() || (filter.Before > 0 && entry.ID >= filter.Before) {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+			// Entries are in time order, so the rest are older still
+			break
+		}
+		if !filter.Until.IsZero() && entry.Timestamp.After(filter.Until) {
+			continue
+		}
+		if (filter.Actor != "" && !strings.EqualFold(entry.Actor, filter.Actor)) ||
+			(filter.Action != "" && entry.Action != filter.Action) ||
+			(filter.Entity != "" && entry.Entity != filter.Entity) ||
+			(filter.EntityID != "" && entry.EntityID != filter.EntityID) ||
+			(filter.BacklogID != "" && entry.BacklogID != filter.BacklogID) ||
+			(filter.StoryID != "" && entry.StoryID != filter.StoryID) ||
+			(filter.RequestID != "" && entry.RequestID != filter.RequestID) {
+			continue
+		}
+		// Only admins see every change; others see those of the backlogs
+		// they manage
+		if !admin && (entry.BacklogID == "" || !s.can(entry.BacklogID, models.PermManage)) {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+	}
+	return entries
+}
+
+// recordAudit appends the entry of a change to the audit log. It runs as a
+// synchronous subscriber, so the publisher already holds the lock.
+func (s *Service) recordAudit(event events.Event) {
+	action := event.Type[strings.LastIndex(event.Type, ".")+1:]
+	if mapped, exists := auditActions[action]; exists {
+		action = mapped
+	}
+	actor := event.Actor
+	if actor == "" {
+		actor = models.ActorSystem
+	}
+
+	s.audit = append(s.audit, models.AuditEntry{
+		ID:          event.ID,
+		Timestamp:   event.OccurredAt,
+		Actor:       actor,
+		Action:      action,
+		Event:       event.Type,
+		Entity:      event.Aggregate,
+		EntityID:    event.AggregateID,
+		WorkspaceID: event.WorkspaceID,
+		BacklogID:   event.BacklogID,
+		StoryID:     event.StoryID,
+		Changes:     fieldChanges(event.Before, event.After),
+		RequestID:   event.RequestID,
+		SourceIP:    event.SourceIP,
+	})
+}
+
+// fieldChanges compares two JSON objects field by field. Nested values are
+// compared as a whole, and the update time is left out as every change
+// moves it.
+func fieldChanges(before, after json.RawMessage) []models.FieldChange {
+	var oldFields, newFields map[string]json.RawMessage
+	if len(before) > 0 {
+		json.Unmarshal(before, &oldFields)
+	}
+	if len(after) > 0 {
+		json.Unmarshal(after, &newFields)
+	}
+
+	fields := make([]string, 0, len(oldFields)+len(newFields))
+	for field := range oldFields {
+		fields = append(fields, field)
+	}
+	for field := range newFields {
+		if _, exists := oldFields[field]; !exists {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []models.FieldChange{}
+	for _, field := range fields {
+		if field == "updated_at" {
+			continue
+		}
+		oldValue, newValue := oldFields[field], newFields[field]
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+	return changes
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"golang-baseline/auth"
+	"golang-baseline/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// minPasswordLength is the shortest password accepted for local users
+const minPasswordLength = 8
+
+// dummyPasswordHash is checked when no password hash is stored for a
+// username, so a login takes as long whether or not the user exists
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := auth.HashPassword(uuid.New().String())
+	return hash
+})
+
+// Authentication operations
+
+// AuthRequired reports whether requests need credentials
+func (s *Service) AuthRequired() bool {
+	return s.config.AuthRequired
+}
+
+// Login checks the password of a local user and issues a bearer token
+func (s *Service) Login(req models.LoginRequest) (*models.LoginResponse, error) {
+	// Checking a password takes a while, so it is done without the lock
+	s.mutex.RLock()
+	var user models.User
+	if found := s.userByUsername(req.Username); found != nil {
+		user = *found
+	}
+	s.mutex.RUnlock()
+
+	if user.PasswordHash == "" {
+		auth.CheckPassword(dummyPasswordHash(), req.Password)
+		return nil, fmt.Errorf("%w: invalid username or password", ErrUnauthorized)
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		return nil, fmt.Errorf("%w: invalid username or password", ErrUnauthorized)
+	}
+
+	token, expiresAt, err := s.tokens.Issue(user.ID, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+		User:      &user,
+	}, nil
+}
+
+// Authenticate resolves the credential of a request, an API key or a JWT,
+// into the principal making it
+func (s *Service) Authenticate(credential string) (*models.Principal, error) {
+	if bootstrap := s.config.AuthBootstrapKey; bootstrap != "" &&
+		subtle.ConstantTimeCompare([]byte(credential), []byte(bootstrap)) == 1 {
+		return &models.Principal{Username: "bootstrap", Method: models.AuthBootstrap}, nil
+	}
+	if lookup, ok := auth.ParseAPIKey(credential); ok {
+		return s.authenticateAPIKey(lookup, credential)
+	}
+
+	claims, err := s.tokens.Verify(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if claims.Issuer == auth.LocalIssuer {
+		user, exists := s.users[claims.Subject]
+		if !exists {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, auth.ErrInvalidCredentials)
+		}
+		return &models.Principal{UserID: user.ID, Username: user.Username, Method: models.AuthToken,
+			WorkspaceID: user.WorkspaceID}, nil
+	}
+
+	// Tokens of another issuer name local users by username
+	principal := &models.Principal{Username: claims.Username, Method: models.AuthToken}
+	if principal.Username == "" {
+		principal.Username = claims.Subject
+	}
+	if user := s.userByUsername(principal.Username); user != nil {
+		principal.UserID, principal.Username, principal.WorkspaceID = user.ID, user.Username, user.WorkspaceID
+	}
+	return principal, nil
+}
+
+// authenticateAPIKey checks an API key and records its use
+func (s *Service) authenticateAPIKey(lookup, key string) (*models.Principal, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, apiKey := range s.apiKeys {
+		if apiKey.Lookup != lookup || !auth.CheckAPIKey(apiKey.Hash, key) {
+			continue
+		}
+		now := time.Now()
+		if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+			break
+		}
+		user, exists := s.users[apiKey.UserID]
+		if !exists {
+			break
+		}
+		apiKey.LastUsedAt = &now
+		return &models.Principal{UserID: user.ID, Username: user.Username, Method: models.AuthAPIKey, KeyID: apiKey.ID,
+			WorkspaceID: user.WorkspaceID}, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnauthorized, auth.ErrInvalidCredentials)
+}
+
+// API key operations. Keys belong to the user of the request.
+
+// CreateAPIKey creates an API key for the user of the request. The key is
+// only returned here; the server keeps its hash.
+func (s *Service) CreateAPIKey(req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	userID, err := s.requestUserID()
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if req.ExpiresInDays < 0 {
+		return nil, fmt.Errorf("%w: expires_in_days cannot be negative", ErrInvalidRequest)
+	}
+
+	key, lookup, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Lookup:    lookup,
+		Hash:      hash,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := apiKey.CreatedAt.AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	s.apiKeys[apiKey.ID] = apiKey
+	s.publish(models.EventAPIKeyCreated, nil, s.snapshot(apiKey))
+	return &models.CreatedAPIKey{APIKey: *apiKey, Key: key}, nil
+}
+
+// GetAPIKeys returns the API keys of the user of the request, oldest first
+func (s *Service) GetAPIKeys() ([]models.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	userID, err := s.requestUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.APIKey{}
+	for _, apiKey := range s.apiKeys {
+		if apiKey.UserID == userID {
+			keys = append(keys, *apiKey)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// DeleteAPIKey revokes an API key of the user of the request
+func (s *Service) DeleteAPIKey(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	userID, err := s.requestUserID()
+	if err != nil {
+		return err
+	}
+	apiKey, exists := s.apiKeys[id]
+	if !exists || apiKey.UserID != userID {
+		return errors.New("API key not found")
+	}
+
+	deleted := s.snapshot(apiKey)
+	delete(s.apiKeys, id)
+	s.publish(models.EventAPIKeyDeleted, deleted, nil)
+	return nil
+}
+
+// requestUserID returns the local user making the request. Callers must
+// hold the lock.
+func (s *Service) requestUserID() (string, error) {
+	principal := s.request.Principal
+	if principal == nil {
+		return "", fmt.Errorf("%w: log in to manage API keys", ErrUnauthorized)
+	}
+	if _, exists := s.users[principal.UserID]; !exists {
+		return "", fmt.Errorf("%w: API keys belong to a registered user", ErrInvalidRequest)
+	}
+	return principal.UserID, nil
+}
+
+// requestUsername returns the username of the local user making the
+// request, or an empty string. Callers must hold the lock.
+func (s *Service) requestUsername() string {
+	if principal := s.request.Principal; principal != nil {
+		if user, exists := s.users[principal.UserID]; exists {
+			return user.Username
+		}
+	}
+	return ""
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Baseline operations
+func (s *Service) CreateBaseline(backlogID string, req models.CreateBaselineRequest) (*models.Baseline, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	for _, existing := range s.baselines {
+		if existing.BacklogID == backlogID && existing.Name == name {
+			return nil, fmt.Errorf("%w: baseline %q already exists", ErrInvalidRequest, name)
+		}
+	}
+
+	baseline := &models.Baseline{
+		ID:        uuid.New().String(),
+		BacklogID: backlogID,
+		Name:      name,
+		Stories:   []models.BaselineStory{},
+		CreatedAt: time.Now(),
+	}
+	for _, story := range s.sortedStories(backlogID) {
+		subtasks := s.sortedSubTasks(story.ID)
+		entry := models.BaselineStory{
+			ID:        story.ID,
+			Title:     story.Title,
+			PlanStart: story.PlanStart,
+			PlanEnd:   story.PlanEnd,
+			Effort:    storyEffort(story, subtasks),
+			SubTasks:  []models.BaselineSubTask{},
+		}
+		for _, subtask := range subtasks {
+			entry.SubTasks = append(entry.SubTasks, models.BaselineSubTask{
+				ID:        subtask.ID,
+				Title:     subtask.Title,
+				PlanStart: subtask.PlanStart,
+				PlanEnd:   subtask.PlanEnd,
+				Effort:    subtask.Effort,
+			})
+		}
+		baseline.Stories = append(baseline.Stories, entry)
+	}
+
+	s.baselines[baseline.ID] = baseline
+	s.publish(models.EventBaselineCreated, nil, s.snapshot(baseline))
+	return baseline, nil
+}
+
+func (s *Service) GetBaselinesByBacklog(backlogID string) ([]*models.Baseline, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	baselines := []*models.Baseline{}
+	for _, baseline := range s.baselines {
+		if baseline.BacklogID == backlogID {
+			baselines = append(baselines, baseline)
+		}
+	}
+	sort.Slice(baselines, func(i, j int) bool {
+		return baselines[i].CreatedAt.Before(baselines[j].CreatedAt)
+	})
+
+	return baselines, nil
+}
+
+func (s *Service) GetBaseline(id string) (*models.Baseline, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	baseline, exists := s.baselines[id]
+	if !exists {
+		return nil, errors.New("baseline not found")
+	}
+	if err := s.authorize(baseline.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	return baseline, nil
+}
+
+func (s *Service) DeleteBaseline(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	baseline, exists := s.baselines[id]
+	if !exists {
+		return errors.New("baseline not found")
+	}
+	if err := s.authorize(baseline.BacklogID, models.PermEdit); err != nil {
+		return err
+	}
+
+	deleted := s.snapshot(baseline)
+	delete(s.baselines, id)
+	s.publish(models.EventBaselineDeleted, deleted, nil)
+	return nil
+}
+
+// CompareBaseline reports how the current plan, or the actual dates when
+// against is "actual", deviate from a baseline
+func (s *Service) CompareBaseline(id, against string) (*models.BaselineComparison, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	baseline, exists := s.baselines[id]
+	if !exists {
+		return nil, errors.New("baseline not found")
+	}
+	if err := s.authorize(baseline.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
+	if against == "" {
+		against = models.CompareAgainstPlan
+	}
+	if against != models.CompareAgainstPlan && against != models.CompareAgainstActual {
+		return nil, fmt.Errorf("%w: against must be %q or %q", ErrInvalidRequest,
+			models.CompareAgainstPlan, models.CompareAgainstActual)
+	}
+
+	comparison := &models.BaselineComparison{
+		BaselineID:     baseline.ID,
+		BacklogID:      baseline.BacklogID,
+		Against:        against,
+		Stories:        []models.StoryDeviation{},
+		AddedStories:   []models.BaselineStory{},
+		RemovedStories: []models.BaselineStory{},
+	}
+
+	inBaseline := make(map[string]bool, len(baseline.Stories))
+	baselineEffort, currentEffort := 0, 0
+	for _, planned := range baseline.Stories {
+		inBaseline[planned.ID] = true
+		baselineEffort += planned.Effort
+
+		story, exists := s.stories[planned.ID]
+		if !exists || story.BacklogID != baseline.BacklogID {
+			comparison.RemovedStories = append(comparison.RemovedStories, planned)
+			continue
+		}
+
+		subtasks := s.sortedSubTasks(story.ID)
+		effort := storyEffort(story, subtasks)
+		currentEffort += effort
+
+		deviation := models.StoryDeviation{
+			ID:              story.ID,
+			Title:           story.Title,
+			BaselineStart:   planned.PlanStart,
+			BaselineEnd:     planned.PlanEnd,
+			Effort:          compareEffort(planned.Effort, effort),
+			AddedSubTasks:   []string{},
+			RemovedSubTasks: []string{},
+		}
+		if against == models.CompareAgainstActual {
+			deviation.CurrentStart, deviation.CurrentEnd = story.ActualStart, story.ActualEnd
+		} else {
+			deviation.CurrentStart, deviation.CurrentEnd = optionalTime(story.PlanStart), optionalTime(story.PlanEnd)
+		}
+		deviation.StartShiftDays = shiftDays(planned.PlanStart, deviation.CurrentStart)
+		deviation.EndShiftDays = shiftDays(planned.PlanEnd, deviation.CurrentEnd)
+
+		plannedSubTasks := make(map[string]bool, len(planned.SubTasks))
+		for _, subtask := range planned.SubTasks {
+			plannedSubTasks[subtask.ID] = true
+			if current, exists := s.subtasks[subtask.ID]; !exists || current.StoryID != story.ID {
+				deviation.RemovedSubTasks = append(deviation.RemovedSubTasks, subtask.ID)
+			}
+		}
+		for _, subtask := range subtasks {
+			if !plannedSubTasks[subtask.ID] {
+				deviation.AddedSubTasks = append(deviation.AddedSubTasks, subtask.ID)
+			}
+		}
+
+		comparison.Stories = append(comparison.Stories, deviation)
+	}
+
+	for _, story := range s.sortedStories(baseline.BacklogID) {
+		if inBaseline[story.ID] {
+			continue
+		}
+		subtasks := s.sortedSubTasks(story.ID)
+		effort := storyEffort(story, subtasks)
+		currentEffort += effort
+		comparison.AddedStories = append(comparison.AddedStories, models.BaselineStory{
+			ID:        story.ID,
+			Title:     story.Title,
+			PlanStart: story.PlanStart,
+			PlanEnd:   story.PlanEnd,
+			Effort:    effort,
+			SubTasks:  []models.BaselineSubTask{},
+		})
+	}
+	comparison.TotalEffort = compareEffort(baselineEffort, currentEffort)
+
+	return comparison, nil
+}
+
+// sortedStories returns the stories of a backlog in plan order. Callers
+// must hold the lock.
+func (s *Service) sortedStories(backlogID string) []*models.Story {
+	var stories []*models.Story
+	for _, story := range s.stories {
+		if story.BacklogID == backlogID {
+			stories = append(stories, story)
+		}
+	}
+	sort.Slice(stories, func(i, j int) bool {
+		return lessByPlan(stories[i].PlanStart, stories[i].CreatedAt, stories[i].ID,
+			stories[j].PlanStart, stories[j].CreatedAt, stories[j].ID)
+	})
+	return stories
+}
+
+// sortedSubTasks returns the subtasks of a story in plan order. Callers
+// must hold the lock.
+func (s *Service) sortedSubTasks(storyID string) []*models.SubTask {
+	var subtasks []*models.SubTask
+	for _, subtask := range s.subtasks {
+		if subtask.StoryID == storyID {
+			subtasks = append(subtasks, subtask)
+		}
+	}
+	sort.Slice(subtasks, func(i, j int) bool {
+		return lessByPlan(subtasks[i].PlanStart, subtasks[i].CreatedAt, subtasks[i].ID,
+			subtasks[j].PlanStart, subtasks[j].CreatedAt, subtasks[j].ID)
+	})
+	return subtasks
+}
+
+// storyEffort returns the effort of a story: the sum of its subtasks when it
+// has any, its original estimate otherwise
+func storyEffort(story *models.Story, subtasks []*models.SubTask) int {
+	if len(subtasks) == 0 {
+		return story.EffortOrigin
+	}
+	total := 0
+	for _, subtask := range subtasks {
+		total += subtask.Effort
+	}
+	return total
+}
+
+func compareEffort(baseline, current int) models.EffortComparison {
+	comparison := models.EffortComparison{
+		Baseline: baseline,
+		Current:  current,
+		Growth:   current - baseline,
+	}
+	if baseline != 0 {
+		comparison.GrowthPercent = math.Round(float64(current-baseline)/float64(baseline)*10000) / 100
+	}
+	return comparison
+}
+
+// optionalTime returns nil for the zero time
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// shiftDays returns how many days current is later than baseline, or nil
+// when either date is unknown
+func shiftDays(baseline time.Time, current *time.Time) *int {
+	if baseline.IsZero() || current == nil {
+		return nil
+	}
+	days := int(math.Round(current.Sub(baseline).Hours() / 24))
+	return &days
+}
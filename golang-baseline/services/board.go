@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// boardStatuses are the board columns in workflow order
+var boardStatuses = []models.Status{
+	models.StatusTodo,
+	models.StatusInProgress,
+	models.StatusBlocked,
+	models.StatusDone,
+}
+
+// defaultBoardSort puts the most urgent cards at the top of each column
+const defaultBoardSort = "-" + models.SortPriority
+
+// Board operations
+
+// GetBoard builds the Kanban board of a backlog. Cards are ordered by the
+// filter sort, most urgent first by default, and grouped into swimlanes by
+// PIC, label or epic when a swimlane grouping is given.
+func (s *Service) GetBoard(backlogID, swimlane string, filter models.ListFilter) (*models.Board, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	backlog, exists := s.backlogs[backlogID]
+	if !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+	switch swimlane {
+	case models.SwimlaneNone, models.SwimlanePIC, models.SwimlaneLabel, models.SwimlaneEpic:
+	default:
+		return nil, fmt.Errorf("%w: swimlane must be pic, label or epic", ErrInvalidRequest)
+	}
+	if filter.Sort == "" {
+		filter.Sort = defaultBoardSort
+	}
+	if err := s.validateSort(backlogID, filter.Sort); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.Status]int)
+	var stories []*models.Story
+	for _, story := range s.stories {
+		if story.BacklogID != backlogID {
+			continue
+		}
+		counts[story.Status]++
+		if s.matchesFilter(storyListItem(story), filter) {
+			stories = append(stories, story)
+		}
+	}
+	sort.Slice(stories, func(i, j int) bool {
+		return lessBySort(filter.Sort, storyListItem(stories[i]), storyListItem(stories[j]))
+	})
+
+	board := &models.Board{BacklogID: backlogID, Swimlane: swimlane, Columns: []models.BoardColumn{}}
+	columns := make(map[models.Status]*models.BoardColumn)
+	for _, status := range boardStatuses {
+		limit := backlog.WIPLimits[status]
+		board.Columns = append(board.Columns, models.BoardColumn{
+			Status:    status,
+			WIPLimit:  limit,
+			Count:     counts[status],
+			OverLimit: limit > 0 && counts[status] > limit,
+			Cards:     []models.BoardCard{},
+		})
+	}
+	for i := range board.Columns {
+		columns[board.Columns[i].Status] = &board.Columns[i]
+	}
+
+	lanes := make(map[string]*models.BoardSwimlane)
+	for _, story := range stories {
+		card := s.boardCard(story)
+		if column, exists := columns[story.Status]; exists {
+			column.Cards = append(column.Cards, card)
+		}
+		if swimlane == models.SwimlaneNone {
+			continue
+		}
+		for _, key := range s.swimlaneKeys(story, swimlane) {
+			lane, exists := lanes[key]
+			if !exists {
+				lane = s.newSwimlane(swimlane, key)
+				lanes[key] = lane
+			}
+			for i := range lane.Columns {
+				if lane.Columns[i].Status == story.Status {
+					lane.Columns[i].Cards = append(lane.Columns[i].Cards, card)
+				}
+			}
+		}
+	}
+
+	// Lanes are ordered by name, with the lane of ungrouped stories last
+	for _, lane := range lanes {
+		board.Swimlanes = append(board.Swimlanes, *lane)
+	}
+	sort.Slice(board.Swimlanes, func(i, j int) bool {
+		a, b := board.Swimlanes[i], board.Swimlanes[j]
+		if (a.Key == "") != (b.Key == "") {
+			return b.Key == ""
+		}
+		if cmp := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); cmp != 0 {
+			return cmp < 0
+		}
+		return a.Key < b.Key
+	})
+
+	return board, nil
+}
+
+// UpdateWIPLimits replaces the WIP limits of a backlog
+func (s *Service) UpdateWIPLimits(backlogID string, req models.UpdateWIPLimitsRequest) (models.WIPLimits, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	backlog, exists := s.backlogs[backlogID]
+	if !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermManage); err != nil {
+		return nil, err
+	}
+
+	limits := models.WIPLimits{}
+	for status, limit := range req.Limits {
+		if !status.IsValid() {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidRequest, status)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("%w: WIP limit of %s cannot be negative", ErrInvalidRequest, status)
+		}
+		if limit > 0 {
+			limits[status] = limit
+		}
+	}
+
+	before := s.snapshot(backlog)
+	backlog.WIPLimits = limits
+	backlog.UpdatedAt = time.Now()
+	s.publish(models.EventBacklogUpdated, before, s.snapshot(backlog))
+	return limits, nil
+}
+
+// wipLimitBreach describes the WIP limit a story would break by moving to
+// a status, or returns an empty string when it stays within the limit.
+// pending stories are counted as already in the status. Callers must hold
+// the lock.
+func (s *Service) wipLimitBreach(story *models.Story, status models.Status, pending int) string {
+	backlog, exists := s.backlogs[story.BacklogID]
+	if !exists || story.Status == status {
+		return ""
+	}
+	limit := backlog.WIPLimits[status]
+	if limit <= 0 {
+		return ""
+	}
+
+	count := pending
+	for _, other := range s.stories {
+		if other.BacklogID == story.BacklogID && other.Status == status {
+			count++
+		}
+	}
+	if count < limit {
+		return ""
+	}
+	return fmt.Sprintf("%s already holds %d of %d stories allowed", status, count, limit)
+}
+
+// boardCard summarizes a story for the board. Callers must hold the lock.
+func (s *Service) boardCard(story *models.Story) models.BoardCard {
+	card := models.BoardCard{
+		ID:             story.ID,
+		Title:          story.Title,
+		PIC:            story.PIC,
+		Priority:       story.Priority,
+		Labels:         story.Labels,
+		Blocked:        len(s.unfinishedBlockers(models.ItemStory, story.ID)) > 0,
+		CommentCount:   s.commentCount(models.ItemStory, story.ID),
+		ChecklistTotal: len(story.Checklist),
+		PlanStart:      story.PlanStart,
+		PlanEnd:        story.PlanEnd,
+	}
+	if epic := s.epicOfStory(story.ID); epic != nil {
+		card.EpicID = epic.ID
+	}
+	for _, item := range story.Checklist {
+		if item.Checked {
+			card.ChecklistDone++
+		}
+	}
+	for _, subtask := range s.subtasks {
+		if subtask.StoryID == story.ID {
+			card.SubTasks++
+			if subtask.Status == models.StatusDone {
+				card.SubTasksDone++
+			}
+		}
+	}
+	return card
+}
+
+// swimlaneKeys returns the lanes a story belongs to. A story with several
+// labels appears in the lane of each. Callers must hold the lock.
+func (s *Service) swimlaneKeys(story *models.Story, swimlane string) []string {
+	switch swimlane {
+	case models.SwimlanePIC:
+		return []string{story.PIC}
+	case models.SwimlaneLabel:
+		if len(story.Labels) == 0 {
+			return []string{""}
+		}
+		return story.Labels
+	case models.SwimlaneEpic:
+		if epic := s.epicOfStory(story.ID); epic != nil {
+			return []string{epic.ID}
+		}
+		return []string{""}
+	}
+	return nil
+}
+
+// newSwimlane creates an empty lane for a grouping key. Callers must hold
+// the lock.
+func (s *Service) newSwimlane(swimlane, key string) *models.BoardSwimlane {
+	lane := &models.BoardSwimlane{Key: key, Name: key}
+	switch swimlane {
+	case models.SwimlanePIC:
+		if key == "" {
+			lane.Name = "Unassigned"
+		}
+	case models.SwimlaneLabel:
+		lane.Name = "No label"
+		if label, exists := s.labels[key]; exists {
+			lane.Name = label.Name
+		}
+	case models.SwimlaneEpic:
+		lane.Name = "No epic"
+		if epic, exists := s.epics[key]; exists {
+			lane.Name = epic.Title
+		}
+	}
+	for _, status := range boardStatuses {
+		lane.Columns = append(lane.Columns, models.BoardLaneCell{Status: status, Cards: []models.BoardCard{}})
+	}
+	return lane
+}
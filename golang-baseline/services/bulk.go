@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"time"
+)
+
+// bulkStep is a validated change to one item of a bulk request
+type bulkStep struct {
+	warnings []string
+	apply    func()
+}
+
+// Bulk operations
+
+// Bulk applies one action to many stories or subtasks. In atomic mode every
+// item is validated before any is changed, and nothing changes if one
+// fails. In best-effort mode each item is applied on its own and failures
+// are reported alongside the successes.
+func (s *Service) Bulk(req models.BulkRequest) (*models.BulkResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if req.Mode == "" {
+		req.Mode = models.BulkAtomic
+	}
+	if req.Mode != models.BulkAtomic && req.Mode != models.BulkBestEffort {
+		return nil, fmt.Errorf("%w: mode must be atomic or best_effort", ErrInvalidRequest)
+	}
+	if req.ItemType != models.ItemStory && req.ItemType != models.ItemSubTask {
+		return nil, fmt.Errorf("%w: unknown item type %q", ErrInvalidRequest, req.ItemType)
+	}
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("%w: ids is required", ErrInvalidRequest)
+	}
+	switch req.Action {
+	case models.BulkStatus:
+		if !req.Status.IsValid() {
+			return nil, ErrInvalidStatus
+		}
+	case models.BulkAssign:
+		if req.PIC == nil {
+			return nil, fmt.Errorf("%w: pic is required", ErrInvalidRequest)
+		}
+	case models.BulkMove:
+		if req.TargetID == "" {
+			return nil, fmt.Errorf("%w: target_id is required", ErrInvalidRequest)
+		}
+	case models.BulkLabel:
+		if len(req.Add) == 0 && len(req.Remove) == 0 {
+			return nil, fmt.Errorf("%w: add or remove is required", ErrInvalidRequest)
+		}
+	case models.BulkDelete:
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidRequest, req.Action)
+	}
+
+	result := &models.BulkResult{Action: req.Action, Mode: req.Mode, Results: []models.BulkItemResult{}}
+	steps := make(map[int]bulkStep)
+	// pending counts the stories an atomic status change moves into each
+	// backlog, so WIP limits see the whole batch
+	var pending map[string]int
+	if req.Mode == models.BulkAtomic {
+		pending = make(map[string]int)
+	}
+
+	for _, id := range addMembers(nil, req.IDs) {
+		item := models.BulkItemResult{ID: id}
+		step, err := s.prepareBulkStep(req, id, pending)
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Warnings = step.warnings
+			if req.Mode == models.BulkBestEffort {
+				step.apply()
+				item.Success = true
+			} else {
+				steps[len(result.Results)] = step
+			}
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	if req.Mode == models.BulkAtomic {
+		if len(steps) < len(result.Results) {
+			for i := range result.Results {
+				if _, valid := steps[i]; valid {
+					result.Results[i].Error = "not applied because other items failed"
+				}
+			}
+			result.Failed = len(result.Results)
+			return result, fmt.Errorf("%w: %d of %d item(s) failed validation", ErrBulkFailed,
+				len(result.Results)-len(steps), len(result.Results))
+		}
+		for i := range result.Results {
+			steps[i].apply()
+			result.Results[i].Success = true
+		}
+	}
+
+	for _, item := range result.Results {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// prepareBulkStep validates the bulk action for one item and returns the
+// change to make. Callers must hold the lock.
+func (s *Service) prepareBulkStep(req models.BulkRequest, id string, pending map[string]int) (bulkStep, error) {
+	var story *models.Story
+	var subtask *models.SubTask
+	backlogID := ""
+	if req.ItemType == models.ItemStory {
+		var exists bool
+		if story, exists = s.stories[id]; !exists {
+			return bulkStep{}, errors.New("story not found")
+		}
+		backlogID = story.BacklogID
+	} else {
+		var exists bool
+		if subtask, exists = s.subtasks[id]; !exists {
+			return bulkStep{}, errors.New("subtask not found")
+		}
+		backlogID = s.backlogOfSubTask(subtask)
+	}
+	if err := s.authorize(backlogID, models.PermEdit); err != nil {
+		return bulkStep{}, err
+	}
+
+	switch req.Action {
+	case models.BulkStatus:
+		if story != nil {
+			key := backlogID + "/" + string(req.Status)
+			warnings, err := s.checkStoryStatus(story, req.Status, pending[key])
+			if err != nil {
+				return bulkStep{}, err
+			}
+			if pending != nil && story.Status != req.Status {
+				pending[key]++
+			}
+			return bulkStep{warnings: warnings, apply: func() { s.setStoryStatus(story, req.Status) }}, nil
+		}
+		warnings, err := s.checkSubTaskStatus(subtask, req.Status)
+		if err != nil {
+			return bulkStep{}, err
+		}
+		return bulkStep{warnings: warnings, apply: func() { s.setSubTaskStatus(subtask, req.Status) }}, nil
+
+	case models.BulkAssign:
+		pic := *req.PIC
+		if story != nil {
+			return bulkStep{apply: s.publishing(req.ItemType, id, func() { story.PIC, story.UpdatedAt = pic, time.Now() })}, nil
+		}
+		return bulkStep{apply: s.publishing(req.ItemType, id, func() { subtask.PIC, subtask.UpdatedAt = pic, time.Now() })}, nil
+
+	case models.BulkMove:
+		if story != nil {
+			if _, exists := s.backlogs[req.TargetID]; !exists {
+				return bulkStep{}, errors.New("target backlog not found")
+			}
+			if err := s.authorize(req.TargetID, models.PermEdit); err != nil {
+				return bulkStep{}, err
+			}
+			return bulkStep{apply: s.publishing(req.ItemType, id, func() { s.moveStory(story, req.TargetID) })}, nil
+		}
+		target, exists := s.stories[req.TargetID]
+		if !exists {
+			return bulkStep{}, errors.New("target story not found")
+		}
+		if err := s.authorize(target.BacklogID, models.PermEdit); err != nil {
+			return bulkStep{}, err
+		}
+		return bulkStep{apply: s.publishing(req.ItemType, id, func() { s.moveSubTask(subtask, target) })}, nil
+
+	case models.BulkLabel:
+		add, err := s.resolveLabels(backlogID, req.Add)
+		if err != nil {
+			return bulkStep{}, err
+		}
+		remove, err := s.resolveLabels(backlogID, req.Remove)
+		if err != nil {
+			return bulkStep{}, err
+		}
+		if story != nil {
+			return bulkStep{apply: s.publishing(req.ItemType, id, func() {
+				story.Labels, story.UpdatedAt = relabel(story.Labels, add, remove), time.Now()
+			})}, nil
+		}
+		return bulkStep{apply: s.publishing(req.ItemType, id, func() {
+			subtask.Labels, subtask.UpdatedAt = relabel(subtask.Labels, add, remove), time.Now()
+		})}, nil
+
+	default:
+		if story != nil {
+			return bulkStep{apply: func() { s.deleteStory(id) }}, nil
+		}
+		return bulkStep{apply: func() { s.deleteSubTask(id) }}, nil
+	}
+}
+
+// moveStory moves a story and its subtasks to another backlog. Labels are
+// matched by name and custom values kept only where the target backlog has
+// a matching field. Callers must hold the lock.
+func (s *Service) moveStory(story *models.Story, backlogID string) {
+	if story.BacklogID == backlogID {
+		return
+	}
+	from := story.BacklogID
+	story.Labels = s.cloneLabels(story.Labels, from, backlogID, nil)
+	story.CustomFields = s.cloneCustomValues(story.CustomFields, from, backlogID)
+	story.BacklogID = backlogID
+	story.UpdatedAt = time.Now()
+	for _, subtask := range s.subtasks {
+		if subtask.StoryID == story.ID {
+			before := s.snapshot(s.subTaskView(subtask))
+			subtask.Labels = s.cloneLabels(subtask.Labels, from, backlogID, nil)
+			subtask.CustomFields = s.cloneCustomValues(subtask.CustomFields, from, backlogID)
+			subtask.UpdatedAt = time.Now()
+			s.publish(models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(subtask)))
+		}
+	}
+}
+
+// moveSubTask moves a subtask to another story, translating its labels and
+// custom values when the story is in another backlog. Callers must hold
+// the lock.
+func (s *Service) moveSubTask(subtask *models.SubTask, story *models.Story) {
+	from := s.backlogOfSubTask(subtask)
+	subtask.Labels = s.cloneLabels(subtask.Labels, from, story.BacklogID, nil)
+	subtask.CustomFields = s.cloneCustomValues(subtask.CustomFields, from, story.BacklogID)
+	subtask.StoryID = story.ID
+	subtask.UpdatedAt = time.Now()
+}
+
+// relabel adds and removes label IDs, keeping the order of the labels that
+// stay
+func relabel(labels, add, remove []string) []string {
+	result := addMembers(append([]string{}, labels...), add)
+	for _, id := range remove {
+		result, _ = removeMember(result, id)
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Checklist operations
+func (s *Service) AddChecklistItem(itemType models.ItemType, itemID string, req models.CreateChecklistItemRequest) (*models.ChecklistItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checklist, updatedAt, err := s.checklistOf(itemType, itemID)
+	if err == nil {
+		err = s.authorizeItem(itemType, itemID, models.PermEdit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidRequest)
+	}
+
+	item := models.ChecklistItem{
+		ID:        uuid.New().String(),
+		Text:      text,
+		Required:  req.Required,
+		CreatedAt: time.Now(),
+	}
+
+	before := s.itemSnapshot(itemType, itemID)
+	*checklist = append(*checklist, item)
+	*updatedAt = time.Now()
+	s.publishItemUpdated(itemType, itemID, before)
+	return &item, nil
+}
+
+// UpdateChecklistItem edits a checklist item. Checking an item records who
+// checked it, the user of the request unless an admin names someone else,
+// and when; unchecking clears both.
+func (s *Service) UpdateChecklistItem(itemType models.ItemType, itemID, checkID string, req models.UpdateChecklistItemRequest) (*models.ChecklistItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checklist, updatedAt, err := s.checklistOf(itemType, itemID)
+	if err == nil {
+		err = s.authorizeItem(itemType, itemID, models.PermEdit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := checklistIndex(*checklist, checkID)
+	if index < 0 {
+		return nil, errors.New("checklist item not found")
+	}
+	if req.Text != nil && strings.TrimSpace(*req.Text) == "" {
+		return nil, fmt.Errorf("%w: text cannot be empty", ErrInvalidRequest)
+	}
+	var checker *models.User
+	if req.Checked != nil && *req.Checked {
+		if req.CheckedBy == "" {
+			req.CheckedBy = s.requestUsername()
+		}
+		if !s.isAdmin() && !strings.EqualFold(req.CheckedBy, s.requestUsername()) {
+			return nil, fmt.Errorf("%w: items can only be checked as yourself", ErrForbidden)
+		}
+		if checker = s.memberByUsername(req.CheckedBy); checker == nil {
+			return nil, fmt.Errorf("%w: checked_by must name a known user", ErrInvalidRequest)
+		}
+	}
+
+	before := s.itemSnapshot(itemType, itemID)
+	// The item is changed in a copy of the checklist, as views of the work
+	// item may still be encoded after the lock is released
+	updated := append([]models.ChecklistItem{}, *checklist...)
+	item := &updated[index]
+	now := time.Now()
+	if req.Text != nil {
+		item.Text = strings.TrimSpace(*req.Text)
+	}
+	if req.Required != nil {
+		item.Required = *req.Required
+	}
+	if req.Checked != nil && *req.Checked != item.Checked {
+		item.Checked = *req.Checked
+		if item.Checked {
+			item.CheckedBy = checker.Username
+			item.CheckedAt = &now
+		} else {
+			item.CheckedBy = ""
+			item.CheckedAt = nil
+		}
+	}
+	*checklist = updated
+	*updatedAt = now
+	s.publishItemUpdated(itemType, itemID, before)
+
+	result := *item
+	return &result, nil
+}
+
+func (s *Service) DeleteChecklistItem(itemType models.ItemType, itemID, checkID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checklist, updatedAt, err := s.checklistOf(itemType, itemID)
+	if err == nil {
+		err = s.authorizeItem(itemType, itemID, models.PermEdit)
+	}
+	if err != nil {
+		return err
+	}
+	index := checklistIndex(*checklist, checkID)
+	if index < 0 {
+		return errors.New("checklist item not found")
+	}
+
+	before := s.itemSnapshot(itemType, itemID)
+	*checklist = append((*checklist)[:index:index], (*checklist)[index+1:]...)
+	*updatedAt = time.Now()
+	s.publishItemUpdated(itemType, itemID, before)
+	return nil
+}
+
+// checklistOf returns the checklist and update time of a work item so they
+// can be changed in place. Callers must hold the lock.
+func (s *Service) checklistOf(itemType models.ItemType, itemID string) (*[]models.ChecklistItem, *time.Time, error) {
+	switch itemType {
+	case models.ItemStory:
+		if story, exists := s.stories[itemID]; exists {
+			return &story.Checklist, &story.UpdatedAt, nil
+		}
+		return nil, nil, errors.New("story not found")
+	case models.ItemSubTask:
+		if subtask, exists := s.subtasks[itemID]; exists {
+			return &subtask.Checklist, &subtask.UpdatedAt, nil
+		}
+		return nil, nil, errors.New("subtask not found")
+	}
+	return nil, nil, fmt.Errorf("%w: unknown item type %q", ErrInvalidRequest, itemType)
+}
+
+func checklistIndex(checklist []models.ChecklistItem, id string) int {
+	for i, item := range checklist {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// uncheckedRequired counts the required checklist items not checked yet
+func uncheckedRequired(checklist []models.ChecklistItem) int {
+	count := 0
+	for _, item := range checklist {
+		if item.Required && !item.Checked {
+			count++
+		}
+	}
+	return count
+}
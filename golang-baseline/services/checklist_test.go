@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/models"
+	"testing"
+)
+
+func TestCheckChecklistItemAsYourself(t *testing.T) {
+	s := newTestService(t, nil)
+	backlog, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Backlog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	story, err := s.CreateStory(models.CreateStoryRequest{BacklogID: backlog.ID, Title: "Story"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := s.AddChecklistItem(models.ItemStory, story.ID, models.CreateChecklistItemRequest{Text: "Review", Required: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, user := asUser(t, s, "ada", false)
+	if _, err := s.CreateGrant(backlog.ID, models.CreateGrantRequest{Role: models.RoleEditor, UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateUser(models.CreateUserRequest{Username: "alan"}); err != nil {
+		t.Fatal(err)
+	}
+
+	checked := true
+	if _, err := editor.UpdateChecklistItem(models.ItemStory, story.ID, item.ID, models.UpdateChecklistItemRequest{Checked: &checked, CheckedBy: "alan"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("checked as another user: %v", err)
+	}
+	updated, err := editor.UpdateChecklistItem(models.ItemStory, story.ID, item.ID, models.UpdateChecklistItemRequest{Checked: &checked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Checked || updated.CheckedBy != "ada" || updated.CheckedAt == nil {
+		t.Errorf("item = %+v", updated)
+	}
+
+	// Admins may record who checked an item
+	admin, _ := asUser(t, s, "grace", true)
+	unchecked := false
+	if _, err := admin.UpdateChecklistItem(models.ItemStory, story.ID, item.ID, models.UpdateChecklistItemRequest{Checked: &unchecked}); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := admin.UpdateChecklistItem(models.ItemStory, story.ID, item.ID, models.UpdateChecklistItemRequest{Checked: &checked, CheckedBy: "alan"}); err != nil || updated.CheckedBy != "alan" {
+		t.Errorf("item = %+v, %v", updated, err)
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mentionPattern matches @username mentions that are not part of a word
+// such as an e-mail address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([a-zA-Z0-9._-]+)`)
+
+// Comment operations
+func (s *Service) CreateComment(itemType models.ItemType, itemID string, req models.CreateCommentRequest) (*models.Comment, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.checkItemExists(itemType, itemID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeItem(itemType, itemID, models.PermComment); err != nil {
+		return nil, err
+	}
+	if req.Author == "" {
+		req.Author = s.requestUsername()
+	}
+	if !s.isAdmin() && !strings.EqualFold(req.Author, s.requestUsername()) {
+		return nil, fmt.Errorf("%w: comments can only be written as yourself", ErrForbidden)
+	}
+	author := s.memberByUsername(req.Author)
+	if author == nil {
+		return nil, fmt.Errorf("%w: author %q is not a known user", ErrInvalidRequest, req.Author)
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidRequest)
+	}
+	if req.ParentID != "" {
+		parent, exists := s.comments[req.ParentID]
+		if !exists || parent.ItemType != itemType || parent.ItemID != itemID {
+			return nil, fmt.Errorf("%w: parent comment not found on this item", ErrInvalidRequest)
+		}
+	}
+
+	comment := &models.Comment{
+		ID:        uuid.New().String(),
+		ItemType:  itemType,
+		ItemID:    itemID,
+		ParentID:  req.ParentID,
+		Author:    author.Username,
+		Body:      req.Body,
+		Mentions:  s.resolveMentions(req.Body),
+		Edits:     []models.CommentEdit{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	s.comments[comment.ID] = comment
+	s.publish(models.EventCommentCreated, nil, s.snapshot(comment))
+	return commentView(comment), nil
+}
+
+// GetComments returns the comments of a work item as threads, oldest first
+func (s *Service) GetComments(itemType models.ItemType, itemID string) ([]models.Comment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.checkItemExists(itemType, itemID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeItem(itemType, itemID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*models.Comment)
+	for _, comment := range s.comments {
+		if comment.ItemType == itemType && comment.ItemID == itemID {
+			children[comment.ParentID] = append(children[comment.ParentID], comment)
+		}
+	}
+	for _, replies := range children {
+		sort.Slice(replies, func(i, j int) bool { return replies[i].CreatedAt.Before(replies[j].CreatedAt) })
+	}
+
+	var thread func(parentID string) []models.Comment
+	thread = func(parentID string) []models.Comment {
+		comments := []models.Comment{}
+		for _, comment := range children[parentID] {
+			view := commentView(comment)
+			view.Replies = thread(comment.ID)
+			comments = append(comments, *view)
+		}
+		return comments
+	}
+
+	return thread(""), nil
+}
+
+func (s *Service) GetComment(id string) (*models.Comment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	comment, exists := s.comments[id]
+	if !exists {
+		return nil, errors.New("comment not found")
+	}
+	if err := s.authorizeItem(comment.ItemType, comment.ItemID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	return commentView(comment), nil
+}
+
+// UpdateComment replaces the body of a comment, keeping the previous body
+// in its edit history
+func (s *Service) UpdateComment(id string, req models.UpdateCommentRequest) (*models.Comment, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	comment, exists := s.comments[id]
+	if !exists || comment.Deleted {
+		return nil, errors.New("comment not found")
+	}
+	if err := s.authorizeCommentChange(comment); err != nil {
+		return nil, err
+	}
+	if req.Editor == "" {
+		req.Editor = s.requestUsername()
+	}
+	if !s.isAdmin() && !strings.EqualFold(req.Editor, s.requestUsername()) {
+		return nil, fmt.Errorf("%w: comments can only be edited as yourself", ErrForbidden)
+	}
+	editor := s.memberByUsername(req.Editor)
+	if editor == nil {
+		return nil, fmt.Errorf("%w: editor %q is not a known user", ErrInvalidRequest, req.Editor)
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidRequest)
+	}
+
+	before := s.snapshot(comment)
+	now := time.Now()
+	comment.Edits = append(comment.Edits, models.CommentEdit{
+		Body:     comment.Body,
+		EditedBy: editor.Username,
+		EditedAt: now,
+	})
+	comment.Body = req.Body
+	comment.Mentions = s.resolveMentions(req.Body)
+	comment.UpdatedAt = now
+
+	s.publish(models.EventCommentUpdated, before, s.snapshot(comment))
+	return commentView(comment), nil
+}
+
+// DeleteComment removes a comment. Comments with replies are blanked and
+// marked deleted instead so the thread stays intact.
+func (s *Service) DeleteComment(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	comment, exists := s.comments[id]
+	if !exists || comment.Deleted {
+		return errors.New("comment not found")
+	}
+	if err := s.authorizeCommentChange(comment); err != nil {
+		return err
+	}
+
+	before := s.snapshot(comment)
+	for _, other := range s.comments {
+		if other.ParentID == id {
+			comment.Deleted = true
+			comment.Body = ""
+			comment.Mentions = []string{}
+			comment.Edits = []models.CommentEdit{}
+			comment.UpdatedAt = time.Now()
+			s.publish(models.EventCommentUpdated, before, s.snapshot(comment))
+			return nil
+		}
+	}
+
+	delete(s.comments, id)
+	s.publish(models.EventCommentDeleted, before, nil)
+	return nil
+}
+
+// commentView returns a copy of a comment that stays valid after the lock
+// is released, as edits append to the history of the stored comment
+func commentView(comment *models.Comment) *models.Comment {
+	view := *comment
+	view.Mentions = append([]string{}, comment.Mentions...)
+	view.Edits = append([]models.CommentEdit{}, comment.Edits...)
+	return &view
+}
+
+// resolveMentions returns the IDs of the known users mentioned in a body.
+// Callers must hold the lock.
+func (s *Service) resolveMentions(body string) []string {
+	mentions := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// A trailing dot usually ends the sentence rather than the name
+		username := strings.TrimRight(match[1], ".")
+		if user := s.memberByUsername(username); user != nil {
+			mentions = addMembers(mentions, []string{user.ID})
+		}
+	}
+	return mentions
+}
+
+// commentCount counts the visible comments of a work item. Callers must
+// hold the lock.
+func (s *Service) commentCount(itemType models.ItemType, itemID string) int {
+	count := 0
+	for _, comment := range s.comments {
+		if comment.ItemType == itemType && comment.ItemID == itemID && !comment.Deleted {
+			count++
+		}
+	}
+	return count
+}
+
+// checkItemExists verifies that a story or subtask exists. Callers must
+// hold the lock.
+func (s *Service) checkItemExists(itemType models.ItemType, id string) error {
+	switch itemType {
+	case models.ItemStory:
+		if _, exists := s.stories[id]; !exists {
+			return errors.New("story not found")
+		}
+	case models.ItemSubTask:
+		if _, exists := s.subtasks[id]; !exists {
+			return errors.New("subtask not found")
+		}
+	default:
+		return fmt.Errorf("%w: unknown item type %q", ErrInvalidRequest, itemType)
+	}
+	return nil
+}
+
+// authorizeCommentChange lets the author of a comment, who still has the
+// comment permission, and managers of the backlog change it. Callers must
+// hold the lock.
+func (s *Service) authorizeCommentChange(comment *models.Comment) error {
+	backlogID, _ := s.itemScope(comment.ItemType, comment.ItemID)
+	if backlogID == "" || s.can(backlogID, models.PermManage) {
+		return nil
+	}
+	if strings.EqualFold(comment.Author, s.requestUsername()) {
+		return s.authorize(backlogID, models.PermComment)
+	}
+	return fmt.Errorf("%w: only the author or a manager of the backlog may change a comment", ErrForbidden)
+}
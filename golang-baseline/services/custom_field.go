@@ -0,0 +1,412 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Custom field operations
+func (s *Service) CreateCustomField(backlogID string, req models.CreateCustomFieldRequest) (*models.CustomField, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermManage); err != nil {
+		return nil, err
+	}
+	if s.customFieldByKey(backlogID, req.Key) != nil {
+		return nil, fmt.Errorf("%w: custom field %q already exists", ErrInvalidRequest, req.Key)
+	}
+	return s.addCustomField(s.workspaceOf(backlogID), backlogID, req)
+}
+
+// CreateWorkspaceCustomField creates a field that applies to every backlog
+// of a workspace. Its key may not be in use by any of them.
+func (s *Service) CreateWorkspaceCustomField(workspaceID string, req models.CreateCustomFieldRequest) (*models.CustomField, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.visibleWorkspace(workspaceID); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	for _, field := range s.customFields {
+		if field.WorkspaceID == workspaceID && field.Key == req.Key {
+			return nil, fmt.Errorf("%w: custom field %q already exists", ErrInvalidRequest, req.Key)
+		}
+	}
+	return s.addCustomField(workspaceID, "", req)
+}
+
+// addCustomField validates and stores a new field of a workspace, or of one
+// of its backlogs. Callers must hold the lock.
+func (s *Service) addCustomField(workspaceID, backlogID string, req models.CreateCustomFieldRequest) (*models.CustomField, error) {
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		return nil, fmt.Errorf("%w: key must be lower case letters, digits and underscores", ErrInvalidRequest)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown field type %q", ErrInvalidRequest, req.Type)
+	}
+	options, err := validateFieldOptions(req.Type, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	field := &models.CustomField{
+		ID:          uuid.New().String(),
+		WorkspaceID: workspaceID,
+		BacklogID:   backlogID,
+		Key:         req.Key,
+		Name:        strings.TrimSpace(req.Name),
+		Type:        req.Type,
+		Options:     options,
+		Required:    req.Required,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	s.customFields[field.ID] = field
+	s.publish(models.EventCustomFieldCreated, nil, s.snapshot(field))
+	return field, nil
+}
+
+func (s *Service) GetCustomFieldsByBacklog(backlogID string) ([]*models.CustomField, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	fields := []*models.CustomField{}
+	for _, field := range s.customFields {
+		if s.fieldApplies(field, backlogID) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	return fields, nil
+}
+
+// GetWorkspaceCustomFields returns the fields that apply to every backlog
+// of a workspace
+func (s *Service) GetWorkspaceCustomFields(workspaceID string) ([]*models.CustomField, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, err := s.visibleWorkspace(workspaceID); err != nil {
+		return nil, err
+	}
+
+	fields := []*models.CustomField{}
+	for _, field := range s.customFields {
+		if field.BacklogID == "" && field.WorkspaceID == workspaceID {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	return fields, nil
+}
+
+// UpdateCustomField renames a field or changes its options or required
+// flag. Values are stored under the immutable key, so renaming keeps them.
+// Removing an enum option that is still in use is rejected.
+func (s *Service) UpdateCustomField(id string, req models.UpdateCustomFieldRequest) (*models.CustomField, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	field, exists := s.customFields[id]
+	if !exists || field.WorkspaceID != s.workspace() {
+		return nil, errors.New("custom field not found")
+	}
+	if err := s.authorizeCustomField(field); err != nil {
+		return nil, err
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidRequest)
+	}
+
+	var options []string
+	if req.Options != nil {
+		var err error
+		if options, err = validateFieldOptions(field.Type, *req.Options); err != nil {
+			return nil, err
+		}
+		kept := make(map[string]bool, len(options))
+		for _, option := range options {
+			kept[option] = true
+		}
+		for _, value := range s.customValuesOf(field) {
+			if text, _ := value.(string); !kept[text] {
+				return nil, fmt.Errorf("%w: option %q is still in use", ErrInvalidRequest, text)
+			}
+		}
+	}
+
+	before := s.snapshot(field)
+	if req.Name != nil {
+		field.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Options != nil {
+		field.Options = options
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	field.UpdatedAt = time.Now()
+
+	s.publish(models.EventCustomFieldUpdated, before, s.snapshot(field))
+	return field, nil
+}
+
+// DeleteCustomField removes a field and erases its values from every story
+// and subtask it applies to
+func (s *Service) DeleteCustomField(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	field, exists := s.customFields[id]
+	if !exists || field.WorkspaceID != s.workspace() {
+		return errors.New("custom field not found")
+	}
+	if err := s.authorizeCustomField(field); err != nil {
+		return err
+	}
+
+	for _, story := range s.stories {
+		if _, set := story.CustomFields[field.Key]; set && s.fieldApplies(field, story.BacklogID) {
+			before := s.snapshot(s.storyView(story))
+			story.CustomFields = withoutCustomValue(story.CustomFields, field.Key)
+			s.publish(models.EventStoryUpdated, before, s.snapshot(s.storyView(story)))
+		}
+	}
+	for _, subtask := range s.subtasks {
+		if _, set := subtask.CustomFields[field.Key]; set && s.fieldApplies(field, s.backlogOfSubTask(subtask)) {
+			before := s.snapshot(s.subTaskView(subtask))
+			subtask.CustomFields = withoutCustomValue(subtask.CustomFields, field.Key)
+			s.publish(models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(subtask)))
+		}
+	}
+
+	deleted := s.snapshot(field)
+	delete(s.customFields, id)
+	s.publish(models.EventCustomFieldDeleted, deleted, nil)
+	return nil
+}
+
+// withoutCustomValue returns a copy of the custom values of an item without
+// the value of key. Values are never changed in place, as views of the item
+// may still be encoded after the lock is released.
+func withoutCustomValue(values models.CustomValues, key string) models.CustomValues {
+	result := make(models.CustomValues, len(values))
+	for k, v := range values {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// authorizeCustomField refuses a change to a field without the manage
+// permission on its backlog; workspace fields need a workspace admin.
+// Callers must hold the lock.
+func (s *Service) authorizeCustomField(field *models.CustomField) error {
+	if field.BacklogID == "" {
+		return s.requireAdmin()
+	}
+	return s.authorize(field.BacklogID, models.PermManage)
+}
+
+// fieldApplies reports whether a field applies to a backlog, either as a
+// field of the backlog or of its workspace. Callers must hold the lock.
+func (s *Service) fieldApplies(field *models.CustomField, backlogID string) bool {
+	if field.BacklogID != "" {
+		return field.BacklogID == backlogID
+	}
+	return backlogID != "" && field.WorkspaceID == s.workspaceOf(backlogID)
+}
+
+// customFieldByKey finds a field that applies to a backlog by key. Callers
+// must hold the lock.
+func (s *Service) customFieldByKey(backlogID, key string) *models.CustomField {
+	for _, field := range s.customFields {
+		if field.Key == key && s.fieldApplies(field, backlogID) {
+			return field
+		}
+	}
+	return nil
+}
+
+// customValuesOf returns every value stored for a field. Callers must hold
+// the lock.
+func (s *Service) customValuesOf(field *models.CustomField) []interface{} {
+	var values []interface{}
+	for _, story := range s.stories {
+		if value, exists := story.CustomFields[field.Key]; exists && s.fieldApplies(field, story.BacklogID) {
+			values = append(values, value)
+		}
+	}
+	for _, subtask := range s.subtasks {
+		if value, exists := subtask.CustomFields[field.Key]; exists && s.fieldApplies(field, s.backlogOfSubTask(subtask)) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// applyCustomValues validates custom values against the fields of a backlog
+// and returns the resulting values. Changes are merged into current; a nil
+// value clears a field. New items must set every required field, while
+// existing items only may not clear one, so items created before a field
+// became required stay editable. Callers must hold the lock.
+func (s *Service) applyCustomValues(backlogID string, current, changes models.CustomValues, creating bool) (models.CustomValues, error) {
+	result := models.CustomValues{}
+	for key, value := range current {
+		result[key] = value
+	}
+
+	for key, value := range changes {
+		field := s.customFieldByKey(backlogID, key)
+		if field == nil {
+			return nil, fmt.Errorf("%w: unknown custom field %q", ErrInvalidRequest, key)
+		}
+		if value == nil {
+			if field.Required {
+				return nil, fmt.Errorf("%w: custom field %q is required", ErrInvalidRequest, key)
+			}
+			delete(result, key)
+			continue
+		}
+		normalized, err := s.normalizeCustomValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = normalized
+	}
+
+	for _, field := range s.customFields {
+		if !creating || !field.Required || !s.fieldApplies(field, backlogID) {
+			continue
+		}
+		if _, exists := result[field.Key]; !exists {
+			return nil, fmt.Errorf("%w: custom field %q is required", ErrInvalidRequest, field.Key)
+		}
+	}
+	return result, nil
+}
+
+// normalizeCustomValue checks a value against the field type and converts
+// it to its stored form. Callers must hold the lock.
+func (s *Service) normalizeCustomValue(field *models.CustomField, value interface{}) (interface{}, error) {
+	invalid := func(expected string) error {
+		return fmt.Errorf("%w: custom field %q must be %s", ErrInvalidRequest, field.Key, expected)
+	}
+
+	switch field.Type {
+	case models.FieldNumber:
+		switch number := value.(type) {
+		case float64:
+			return number, nil
+		case int:
+			return float64(number), nil
+		case string:
+			if parsed, err := strconv.ParseFloat(number, 64); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, invalid("a number")
+	case models.FieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("a YYYY-MM-DD date")
+		}
+		if date, err := time.Parse(models.DateLayout, text); err == nil {
+			return date.Format(models.DateLayout), nil
+		}
+		if date, err := time.Parse(time.RFC3339, text); err == nil {
+			return date.Format(models.DateLayout), nil
+		}
+		return nil, invalid("a YYYY-MM-DD date")
+	case models.FieldEnum:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("one of " + strings.Join(field.Options, ", "))
+		}
+		for _, option := range field.Options {
+			if option == text {
+				return text, nil
+			}
+		}
+		return nil, invalid("one of " + strings.Join(field.Options, ", "))
+	case models.FieldUser:
+		text, _ := value.(string)
+		user := s.memberByUsername(strings.TrimSpace(text))
+		if user == nil {
+			return nil, invalid("the username of a known user")
+		}
+		return user.Username, nil
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("text")
+		}
+		return text, nil
+	}
+}
+
+// validateFieldOptions checks the options of an enum field. Other field
+// types take no options.
+func validateFieldOptions(fieldType models.CustomFieldType, options []string) ([]string, error) {
+	if fieldType != models.FieldEnum {
+		if len(options) > 0 {
+			return nil, fmt.Errorf("%w: only enum fields have options", ErrInvalidRequest)
+		}
+		return nil, nil
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%w: enum fields need at least one option", ErrInvalidRequest)
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if strings.TrimSpace(option) == "" || seen[option] {
+			return nil, fmt.Errorf("%w: enum options must be unique and non-empty", ErrInvalidRequest)
+		}
+		seen[option] = true
+	}
+	return append([]string{}, options...), nil
+}
+
+// formatCustomValue renders a stored custom value as text for filtering
+func formatCustomValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
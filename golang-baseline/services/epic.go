@@ -0,0 +1,472 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Epic operations
+func (s *Service) CreateEpic(req models.CreateEpicRequest) (*models.Epic, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireManager(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidRequest)
+	}
+
+	epic := &models.Epic{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Title:       req.Title,
+		Description: req.Description,
+		StoryIDs:    []string{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	s.epics[epic.ID] = epic
+	view := s.epicView(epic)
+	s.publish(models.EventEpicCreated, nil, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) GetEpic(id string) (*models.Epic, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	epic, exists := s.epics[id]
+	if !exists || epic.WorkspaceID != s.workspace() {
+		return nil, errors.New("epic not found")
+	}
+
+	return s.epicView(epic), nil
+}
+
+func (s *Service) GetAllEpics() ([]*models.Epic, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	epics := []*models.Epic{}
+	for _, epic := range s.epics {
+		if epic.WorkspaceID == s.workspace() {
+			epics = append(epics, s.epicView(epic))
+		}
+	}
+	sort.Slice(epics, func(i, j int) bool { return epics[i].CreatedAt.Before(epics[j].CreatedAt) })
+
+	return epics, nil
+}
+
+func (s *Service) UpdateEpic(id string, req models.UpdateEpicRequest) (*models.Epic, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	epic, exists := s.epics[id]
+	if !exists || epic.WorkspaceID != s.workspace() {
+		return nil, errors.New("epic not found")
+	}
+	if err := s.authorizeGroup(epic.StoryIDs); err != nil {
+		return nil, err
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidRequest)
+	}
+
+	before := s.snapshot(s.epicView(epic))
+	if req.Title != nil {
+		epic.Title = *req.Title
+	}
+	if req.Description != nil {
+		epic.Description = *req.Description
+	}
+	epic.UpdatedAt = time.Now()
+
+	view := s.epicView(epic)
+	s.publish(models.EventEpicUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) DeleteEpic(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	epic, exists := s.epics[id]
+	if !exists || epic.WorkspaceID != s.workspace() {
+		return errors.New("epic not found")
+	}
+	if err := s.authorizeGroup(epic.StoryIDs); err != nil {
+		return err
+	}
+
+	deleted := s.snapshot(s.epicView(epic))
+	delete(s.epics, id)
+	s.publish(models.EventEpicDeleted, deleted, nil)
+	return nil
+}
+
+// AddStoriesToEpic adds stories to an epic. A story belongs to at most one
+// epic, so stories that already belong to another epic are rejected.
+func (s *Service) AddStoriesToEpic(id string, req models.MembershipRequest) (*models.Epic, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	epic, exists := s.epics[id]
+	if !exists || epic.WorkspaceID != s.workspace() {
+		return nil, errors.New("epic not found")
+	}
+	if err := s.checkStoriesExist(req.StoryIDs); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeStories(req.StoryIDs, models.PermEdit); err != nil {
+		return nil, err
+	}
+	for _, storyID := range req.StoryIDs {
+		if other := s.epicOfStory(storyID); other != nil && other.ID != id {
+			return nil, fmt.Errorf("%w: story %s already belongs to epic %q", ErrInvalidRequest, storyID, other.Title)
+		}
+	}
+
+	before := s.snapshot(s.epicView(epic))
+	epic.StoryIDs = addMembers(epic.StoryIDs, req.StoryIDs)
+	epic.UpdatedAt = time.Now()
+
+	view := s.epicView(epic)
+	s.publish(models.EventEpicUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) RemoveStoryFromEpic(id, storyID string) (*models.Epic, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	epic, exists := s.epics[id]
+	if !exists || epic.WorkspaceID != s.workspace() {
+		return nil, errors.New("epic not found")
+	}
+	if err := s.authorizeItem(models.ItemStory, storyID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	members, removed := removeMember(epic.StoryIDs, storyID)
+	if !removed {
+		return nil, errors.New("story is not part of this epic")
+	}
+	before := s.snapshot(s.epicView(epic))
+	epic.StoryIDs = members
+	epic.UpdatedAt = time.Now()
+
+	view := s.epicView(epic)
+	s.publish(models.EventEpicUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+// epicOfStory returns the epic a story belongs to, if any. Callers must
+// hold the lock.
+func (s *Service) epicOfStory(storyID string) *models.Epic {
+	for _, epic := range s.epics {
+		for _, member := range epic.StoryIDs {
+			if member == storyID {
+				return epic
+			}
+		}
+	}
+	return nil
+}
+
+// Milestone operations
+func (s *Service) CreateMilestone(req models.CreateMilestoneRequest) (*models.Milestone, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireManager(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidRequest)
+	}
+	if req.TargetDate.IsZero() {
+		return nil, fmt.Errorf("%w: target date is required", ErrInvalidRequest)
+	}
+
+	milestone := &models.Milestone{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Title:       req.Title,
+		Description: req.Description,
+		TargetDate:  req.TargetDate,
+		StoryIDs:    []string{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	s.milestones[milestone.ID] = milestone
+	view := s.milestoneView(milestone)
+	s.publish(models.EventMilestoneCreated, nil, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) GetMilestone(id string) (*models.Milestone, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return nil, errors.New("milestone not found")
+	}
+
+	return s.milestoneView(milestone), nil
+}
+
+func (s *Service) GetAllMilestones() ([]*models.Milestone, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	milestones := []*models.Milestone{}
+	for _, milestone := range s.milestones {
+		if milestone.WorkspaceID == s.workspace() {
+			milestones = append(milestones, s.milestoneView(milestone))
+		}
+	}
+	sort.Slice(milestones, func(i, j int) bool {
+		return milestones[i].TargetDate.Before(milestones[j].TargetDate)
+	})
+
+	return milestones, nil
+}
+
+func (s *Service) UpdateMilestone(id string, req models.UpdateMilestoneRequest) (*models.Milestone, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return nil, errors.New("milestone not found")
+	}
+	if err := s.authorizeGroup(milestone.StoryIDs); err != nil {
+		return nil, err
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidRequest)
+	}
+	if req.TargetDate != nil && req.TargetDate.IsZero() {
+		return nil, fmt.Errorf("%w: target date cannot be empty", ErrInvalidRequest)
+	}
+
+	before := s.snapshot(s.milestoneView(milestone))
+	if req.Title != nil {
+		milestone.Title = *req.Title
+	}
+	if req.Description != nil {
+		milestone.Description = *req.Description
+	}
+	if req.TargetDate != nil {
+		milestone.TargetDate = *req.TargetDate
+	}
+	milestone.UpdatedAt = time.Now()
+
+	view := s.milestoneView(milestone)
+	s.publish(models.EventMilestoneUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) DeleteMilestone(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return errors.New("milestone not found")
+	}
+	if err := s.authorizeGroup(milestone.StoryIDs); err != nil {
+		return err
+	}
+
+	deleted := s.snapshot(s.milestoneView(milestone))
+	delete(s.milestones, id)
+	s.publish(models.EventMilestoneDeleted, deleted, nil)
+	return nil
+}
+
+func (s *Service) AddStoriesToMilestone(id string, req models.MembershipRequest) (*models.Milestone, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return nil, errors.New("milestone not found")
+	}
+	if err := s.checkStoriesExist(req.StoryIDs); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeStories(req.StoryIDs, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	before := s.snapshot(s.milestoneView(milestone))
+	milestone.StoryIDs = addMembers(milestone.StoryIDs, req.StoryIDs)
+	milestone.UpdatedAt = time.Now()
+
+	view := s.milestoneView(milestone)
+	s.publish(models.EventMilestoneUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+func (s *Service) RemoveStoryFromMilestone(id, storyID string) (*models.Milestone, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return nil, errors.New("milestone not found")
+	}
+	if err := s.authorizeItem(models.ItemStory, storyID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	members, removed := removeMember(milestone.StoryIDs, storyID)
+	if !removed {
+		return nil, errors.New("story is not part of this milestone")
+	}
+	before := s.snapshot(s.milestoneView(milestone))
+	milestone.StoryIDs = members
+	milestone.UpdatedAt = time.Now()
+
+	view := s.milestoneView(milestone)
+	s.publish(models.EventMilestoneUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+// GetReleaseReadiness reports the blocked and open stories of a milestone.
+// Stories the request may not view are left out of the report, but still
+// keep the milestone from being ready.
+func (s *Service) GetReleaseReadiness(id string) (*models.ReleaseReadiness, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	milestone, exists := s.milestones[id]
+	if !exists || milestone.WorkspaceID != s.workspace() {
+		return nil, errors.New("milestone not found")
+	}
+
+	readiness := &models.ReleaseReadiness{
+		MilestoneID:    milestone.ID,
+		Title:          milestone.Title,
+		TargetDate:     milestone.TargetDate,
+		DaysRemaining:  int(math.Ceil(time.Until(milestone.TargetDate).Hours() / 24)),
+		Progress:       s.progress(milestone.StoryIDs),
+		BlockedStories: []models.ReadinessItem{},
+		OpenStories:    []models.ReadinessItem{},
+	}
+
+	hidden := false
+	for _, storyID := range milestone.StoryIDs {
+		story, exists := s.stories[storyID]
+		if !exists || story.Status == models.StatusDone {
+			continue
+		}
+		if !s.can(story.BacklogID, models.PermView) {
+			hidden = true
+			continue
+		}
+		item := models.ReadinessItem{
+			ID:        story.ID,
+			BacklogID: story.BacklogID,
+			Title:     story.Title,
+			Status:    story.Status,
+			PIC:       story.PIC,
+			BlockedBy: s.unfinishedBlockers(models.ItemStory, story.ID),
+		}
+		if story.Status == models.StatusBlocked || len(item.BlockedBy) > 0 {
+			readiness.BlockedStories = append(readiness.BlockedStories, item)
+		} else {
+			readiness.OpenStories = append(readiness.OpenStories, item)
+		}
+	}
+	readiness.Ready = !hidden && len(readiness.BlockedStories) == 0 && len(readiness.OpenStories) == 0
+
+	return readiness, nil
+}
+
+// progress rolls up the status counts of the stories of a set the request
+// may view. Callers must hold the lock.
+func (s *Service) progress(storyIDs []string) models.Progress {
+	progress := models.Progress{StatusCount: make(map[models.Status]int)}
+	for _, storyID := range storyIDs {
+		if story, exists := s.stories[storyID]; exists && s.can(story.BacklogID, models.PermView) {
+			progress.TotalStories++
+			progress.StatusCount[story.Status]++
+		}
+	}
+	if progress.TotalStories > 0 {
+		done := float64(progress.StatusCount[models.StatusDone])
+		progress.PercentDone = math.Round(done/float64(progress.TotalStories)*10000) / 100
+	}
+	return progress
+}
+
+// epicView returns a copy of an epic with its progress populated. Callers
+// must hold the lock.
+func (s *Service) epicView(epic *models.Epic) *models.Epic {
+	view := *epic
+	view.StoryIDs = append([]string{}, epic.StoryIDs...)
+	view.Progress = s.progress(epic.StoryIDs)
+	return &view
+}
+
+// milestoneView returns a copy of a milestone with its progress populated.
+// Callers must hold the lock.
+func (s *Service) milestoneView(milestone *models.Milestone) *models.Milestone {
+	view := *milestone
+	view.StoryIDs = append([]string{}, milestone.StoryIDs...)
+	view.Progress = s.progress(milestone.StoryIDs)
+	return &view
+}
+
+// checkStoriesExist verifies that every story ID refers to an existing
+// story. Callers must hold the lock.
+func (s *Service) checkStoriesExist(storyIDs []string) error {
+	if len(storyIDs) == 0 {
+		return fmt.Errorf("%w: story_ids is required", ErrInvalidRequest)
+	}
+	for _, storyID := range storyIDs {
+		if _, exists := s.stories[storyID]; !exists {
+			return fmt.Errorf("story %s not found", storyID)
+		}
+	}
+	return nil
+}
+
+// addMembers appends the IDs that are not members yet
+func addMembers(members, ids []string) []string {
+	present := make(map[string]bool, len(members))
+	for _, member := range members {
+		present[member] = true
+	}
+	for _, id := range ids {
+		if !present[id] {
+			present[id] = true
+			members = append(members, id)
+		}
+	}
+	return members
+}
+
+// removeMember removes an ID and reports whether it was a member
+func removeMember(members []string, id string) ([]string, bool) {
+	for i, member := range members {
+		if member == id {
+			return append(members[:i:i], members[i+1:]...), true
+		}
+	}
+	return members, false
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/models"
+	"testing"
+	"time"
+)
+
+func TestEpicAndMilestonePermissions(t *testing.T) {
+	s := newTestService(t, nil)
+	mine, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Mine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stories []*models.Story
+	for _, backlogID := range []string{mine.ID, other.ID} {
+		story, err := s.CreateStory(models.CreateStoryRequest{BacklogID: backlogID, Title: "Story"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stories = append(stories, story)
+	}
+
+	manager, user := asUser(t, s, "manager", false)
+	if _, err := s.CreateGrant(mine.ID, models.CreateGrantRequest{Role: models.RoleOwner, UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	viewer, _ := asUser(t, s, "viewer", false)
+
+	// Only users managing a backlog create epics, milestones and templates
+	if _, err := viewer.CreateEpic(models.CreateEpicRequest{Title: "Epic"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("viewer created an epic: %v", err)
+	}
+	if _, err := viewer.CreateMilestone(models.CreateMilestoneRequest{Title: "Release", TargetDate: time.Now()}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("viewer created a milestone: %v", err)
+	}
+	if _, err := viewer.CreateTemplate(models.CreateTemplateRequest{Name: "Template", Story: models.TemplateStory{Title: "Story"}}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("viewer created a template: %v", err)
+	}
+	epic, err := manager.CreateEpic(models.CreateEpicRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	milestone, err := manager.CreateMilestone(models.CreateMilestoneRequest{Title: "Release", TargetDate: time.Now().AddDate(0, 0, 7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes need the manage permission on the backlog of every story
+	storyIDs := models.MembershipRequest{StoryIDs: []string{stories[0].ID, stories[1].ID}}
+	if _, err := s.AddStoriesToEpic(epic.ID, storyIDs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddStoriesToMilestone(milestone.ID, storyIDs); err != nil {
+		t.Fatal(err)
+	}
+	title := "Renamed"
+	if _, err := manager.UpdateEpic(epic.ID, models.UpdateEpicRequest{Title: &title}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("epic updated: %v", err)
+	}
+	if err := manager.DeleteEpic(epic.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("epic deleted: %v", err)
+	}
+	if _, err := manager.UpdateMilestone(milestone.ID, models.UpdateMilestoneRequest{Title: &title}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("milestone updated: %v", err)
+	}
+	if err := manager.DeleteMilestone(milestone.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("milestone deleted: %v", err)
+	}
+
+	// Progress and readiness only cover the stories the request may view
+	view, err := manager.GetEpic(epic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Progress.TotalStories != 1 {
+		t.Errorf("progress = %+v", view.Progress)
+	}
+	readiness, err := manager.GetReleaseReadiness(milestone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readiness.Progress.TotalStories != 1 || len(readiness.OpenStories) != 1 || readiness.OpenStories[0].ID != stories[0].ID || readiness.Ready {
+		t.Errorf("readiness = %+v", readiness)
+	}
+	if readiness, _ := s.GetReleaseReadiness(milestone.ID); readiness.Progress.TotalStories != 2 || len(readiness.OpenStories) != 2 {
+		t.Errorf("readiness = %+v", readiness)
+	}
+
+	if _, err := s.RemoveStoryFromEpic(epic.ID, stories[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.UpdateEpic(epic.ID, models.UpdateEpicRequest{Title: &title}); err != nil {
+		t.Errorf("epic not updated: %v", err)
+	}
+	if err := manager.DeleteEpic(epic.ID); err != nil {
+		t.Errorf("epic not deleted: %v", err)
+	}
+}
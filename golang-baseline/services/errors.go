@@ -0,0 +1,29 @@
+package services
+
+import (
+	"errors"
+)
+
+// Sentinel errors returned by the service so handlers can choose the
+// appropriate HTTP status code with errors.Is
+var (
+	ErrInvalidStatus       = errors.New("invalid status")
+	ErrInvalidLink         = errors.New("invalid link")
+	ErrDuplicateLink       = errors.New("link already exists")
+	ErrDependencyCycle     = errors.New("link would create a dependency cycle")
+	ErrBlocked             = errors.New("work item is blocked")
+	ErrChecklistIncomplete = errors.New("required checklist items are not checked")
+	ErrWIPLimit            = errors.New("WIP limit reached")
+	ErrInvalidCalendar     = errors.New("invalid calendar")
+	ErrInvalidSchedule     = errors.New("invalid schedule request")
+	ErrInvalidRequest      = errors.New("invalid request")
+	ErrBulkFailed          = errors.New("bulk operation not applied")
+
+	ErrInvalidAttachment  = errors.New("invalid attachment")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+
+	ErrUpstream = errors.New("upstream service failed")
+
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang-baseline/events"
+	"golang-baseline/models"
+)
+
+// Domain event operations
+
+// Subscribe registers a handler called synchronously for every event
+// matching the patterns, in the order of the changes. The handler runs
+// while the service lock is held: it must be quick and must not call back
+// into the service.
+func (s *Service) Subscribe(name string, handler events.Handler, patterns ...string) {
+	s.bus.Subscribe(name, handler, patterns...)
+}
+
+// SubscribeAsync registers a handler called on background workers for
+// every event matching the patterns. Events of one aggregate arrive in the
+// order of the changes; the handler may call the service.
+func (s *Service) SubscribeAsync(name string, handler events.Handler, patterns ...string) {
+	s.bus.SubscribeAsync(name, s.config.EventWorkers, handler, patterns...)
+}
+
+// StartEventBus starts the workers of the asynchronous subscribers. The
+// returned function stops them once the events already published are
+// handled.
+func (s *Service) StartEventBus() func() {
+	return s.bus.Start()
+}
+
+// snapshot is the state of an aggregate at one moment. It is serialised
+// when taken, so later changes to the aggregate do not leak into it.
+type snapshot struct {
+	workspaceID string
+	aggregate   string
+	aggregateID string
+	backlogID   string
+	storyID     string
+	body        json.RawMessage
+}
+
+// snapshot captures an aggregate. Callers must hold the lock.
+func (s *Service) snapshot(item interface{}) *snapshot {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return nil
+	}
+	snap := &snapshot{body: body}
+	snap.aggregate, snap.aggregateID, snap.backlogID, snap.storyID = s.eventSubject(item)
+	snap.workspaceID = s.eventWorkspace(item, snap.backlogID)
+	return snap
+}
+
+// publish publishes a change of an aggregate from its before snapshot to
+// its after snapshot; before is nil for creations and after for deletions.
+// Callers must hold the lock, which keeps events in the order of changes.
+func (s *Service) publish(eventType string, before, after *snapshot) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	if subject == nil {
+		return
+	}
+
+	event := events.Event{
+		Type:        eventType,
+		WorkspaceID: subject.workspaceID,
+		Aggregate:   subject.aggregate,
+		AggregateID: subject.aggregateID,
+		BacklogID:   subject.backlogID,
+		StoryID:     subject.storyID,
+		Actor:       s.request.Actor,
+		RequestID:   s.request.RequestID,
+		SourceIP:    s.request.SourceIP,
+	}
+	if before != nil {
+		event.Before = before.body
+	}
+	if after != nil {
+		event.After = after.body
+	}
+	s.bus.Publish(event)
+}
+
+// publishIfChanged publishes an update only when the snapshots differ, for
+// changes that may turn out to leave the aggregate as it was. Callers must
+// hold the lock.
+func (s *Service) publishIfChanged(eventType string, before, after *snapshot) {
+	if before != nil && after != nil && bytes.Equal(before.body, after.body) {
+		return
+	}
+	s.publish(eventType, before, after)
+}
+
+// publishSaved publishes the created or the updated event of an item.
+// Callers must hold the lock.
+func (s *Service) publishSaved(createdEvent, updatedEvent string, before, after *snapshot) {
+	if before == nil {
+		s.publish(createdEvent, nil, after)
+		return
+	}
+	s.publish(updatedEvent, before, after)
+}
+
+// itemSnapshot captures a story or subtask. Callers must hold the lock.
+func (s *Service) itemSnapshot(itemType models.ItemType, id string) *snapshot {
+	switch itemType {
+	case models.ItemStory:
+		if story, exists := s.stories[id]; exists {
+			return s.snapshot(s.storyView(story))
+		}
+	case models.ItemSubTask:
+		if subtask, exists := s.subtasks[id]; exists {
+			return s.snapshot(s.subTaskView(subtask))
+		}
+	}
+	return nil
+}
+
+// publishItemUpdated publishes the update of a story or subtask since
+// before was taken. Callers must hold the lock.
+func (s *Service) publishItemUpdated(itemType models.ItemType, id string, before *snapshot) {
+	event := models.EventStoryUpdated
+	if itemType == models.ItemSubTask {
+		event = models.EventSubTaskUpdated
+	}
+	s.publish(event, before, s.itemSnapshot(itemType, id))
+}
+
+// publishing wraps a change of a story or subtask so that applying it
+// publishes the update. Callers must hold the lock when applying it.
+func (s *Service) publishing(itemType models.ItemType, id string, change func()) func() {
+	return func() {
+		before := s.itemSnapshot(itemType, id)
+		change()
+		s.publishItemUpdated(itemType, id, before)
+	}
+}
+
+// eventSubject returns the aggregate an item belongs to, and the backlog
+// and story it concerns for filtering. Callers must hold the lock.
+func (s *Service) eventSubject(item interface{}) (string, string, string, string) {
+	switch item := item.(type) {
+	case *models.Backlog:
+		return "backlog", item.ID, item.ID, ""
+	case models.Story:
+		return "story", item.ID, item.BacklogID, item.ID
+	case models.SubTask:
+		return "subtask", item.ID, s.backlogOfSubTask(&item), item.StoryID
+	case *models.Label:
+		return "label", item.ID, item.BacklogID, ""
+	case *models.CustomField:
+		return "custom_field", item.ID, item.BacklogID, ""
+	case *models.Link:
+		backlogID, storyID := s.itemScope(item.ItemType, item.SourceID)
+		return "link", item.ID, backlogID, storyID
+	case *models.Comment:
+		backlogID, storyID := s.itemScope(item.ItemType, item.ItemID)
+		return "comment", item.ID, backlogID, storyID
+	case *models.Attachment:
+		backlogID, storyID := s.itemScope(item.ItemType, item.ItemID)
+		return "attachment", item.ID, backlogID, storyID
+	case *models.Epic:
+		return "epic", item.ID, "", ""
+	case *models.Milestone:
+		return "milestone", item.ID, "", ""
+	case *models.Baseline:
+		return "baseline", item.ID, item.BacklogID, ""
+	case *models.StoryTemplate:
+		return "template", item.ID, "", ""
+	case *models.Recurrence:
+		return "recurrence", item.ID, item.BacklogID, ""
+	case models.Calendar:
+		return "calendar", "calendar", "", ""
+	case *models.Capacity:
+		return "capacity", item.PIC, "", ""
+	case *models.JiraConflict:
+		backlogID, storyID := s.itemScope(item.ItemType, item.ItemID)
+		return "jira_conflict", item.ID, backlogID, storyID
+	case *models.User:
+		return "user", item.ID, "", ""
+	case *models.Webhook:
+		return "webhook", item.ID, "", ""
+	case *models.APIKey:
+		return "api_key", item.ID, "", ""
+	case *models.Role:
+		return "role", item.ID, "", ""
+	case *models.Team:
+		return "team", item.ID, "", ""
+	case *models.Grant:
+		return "grant", item.ID, item.BacklogID, ""
+	case *models.Workspace:
+		return "workspace", item.ID, "", ""
+	}
+	return "", "", "", ""
+}
+
+// eventWorkspace returns the workspace an item belongs to: the workspace of
+// its backlog, the one it names itself, or the workspace of the request.
+// Callers must hold the lock.
+func (s *Service) eventWorkspace(item interface{}, backlogID string) string {
+	if workspaceID := s.workspaceOf(backlogID); workspaceID != "" {
+		return workspaceID
+	}
+	var workspaceID string
+	switch item := item.(type) {
+	case *models.Workspace:
+		workspaceID = item.ID
+	case *models.CustomField:
+		workspaceID = item.WorkspaceID
+	case *models.Epic:
+		workspaceID = item.WorkspaceID
+	case *models.Milestone:
+		workspaceID = item.WorkspaceID
+	case *models.StoryTemplate:
+		workspaceID = item.WorkspaceID
+	case *models.User:
+		workspaceID = item.WorkspaceID
+	case *models.Webhook:
+		workspaceID = item.WorkspaceID
+	case *models.Role:
+		workspaceID = item.WorkspaceID
+	case *models.Team:
+		workspaceID = item.WorkspaceID
+	}
+	if workspaceID == "" {
+		workspaceID = s.workspace()
+	}
+	return workspaceID
+}
+
+// itemScope returns the backlog and story of a work item. Callers must
+// hold the lock.
+func (s *Service) itemScope(itemType models.ItemType, id string) (string, string) {
+	switch itemType {
+	case models.ItemStory:
+		if story, exists := s.stories[id]; exists {
+			return story.BacklogID, story.ID
+		}
+	case models.ItemSubTask:
+		if subtask, exists := s.subtasks[id]; exists {
+			return s.backlogOfSubTask(subtask), subtask.StoryID
+		}
+	}
+	return "", ""
+}
+
+// changeData returns the data of an event as the feed and webhooks show
+// it: the item after the change, or before it when it was deleted. Status
+// changes carry both statuses.
+func changeData(event events.Event) (json.RawMessage, error) {
+	if event.Type == models.EventStoryStatusChanged || event.Type == models.EventSubTaskStatusChanged {
+		var before, after struct {
+			Status models.Status `json:"status"`
+		}
+		if err := event.Decode(&before, &after); err != nil {
+			return nil, err
+		}
+		return json.Marshal(models.StatusChange{From: before.Status, To: after.Status, Item: event.After})
+	}
+	if len(event.After) > 0 {
+		return event.After, nil
+	}
+	return event.Before, nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"golang-baseline/events"
+	"golang-baseline/feed"
+	"golang-baseline/models"
+	"time"
+)
+
+// Change feed operations
+
+// SubscribeFeed subscribes to the change feed. With resume set, events
+// after lastID are replayed first. Only the events of the request's
+// workspace and of backlogs the request may view are delivered.
+func (s *Service) SubscribeFeed(filter feed.Filter, lastID uint64, resume bool) *feed.Subscription {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	filter.WorkspaceID = s.workspace()
+	// The feed publishes under the lock, so the check always holds it
+	filter.Allow = func(backlogID string) bool { return s.can(backlogID, models.PermView) }
+	return s.feed.Subscribe(filter, lastID, resume)
+}
+
+// streamEvent publishes a change to the change feed. It subscribes
+// synchronously, so feed IDs follow the order of the changes.
+func (s *Service) streamEvent(event events.Event) {
+	data, err := changeData(event)
+	if err != nil {
+		return
+	}
+	s.feed.Publish(event.Type, event.WorkspaceID, event.BacklogID, event.StoryID, data)
+}
+
+// defaultFeedHeartbeat is used when the configured heartbeat is not positive
+const defaultFeedHeartbeat = 15 * time.Second
+
+// FeedHeartbeat returns the time between heartbeats on idle feed
+// connections. It is always positive, as the feed handlers tick with it.
+func (s *Service) FeedHeartbeat() time.Duration {
+	if s.config.FeedHeartbeat <= 0 {
+		return defaultFeedHeartbeat
+	}
+	return time.Duration(s.config.FeedHeartbeat) * time.Second
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"golang-baseline/config"
+	"testing"
+	"time"
+)
+
+func TestFeedHeartbeat(t *testing.T) {
+	for seconds, want := range map[int]time.Duration{30: 30 * time.Second, 0: 15 * time.Second, -5: 15 * time.Second} {
+		s := newTestService(t, func(cfg *config.Config) { cfg.FeedHeartbeat = seconds })
+		if got := s.FeedHeartbeat(); got != want {
+			t.Errorf("FeedHeartbeat() with %d seconds = %s, want %s", seconds, got, want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"golang-baseline/models"
+	"strings"
+	"time"
+)
+
+// listItem holds the values list endpoints filter and sort on
+type listItem struct {
+	id          string
+	createdAt   time.Time
+	planStart   time.Time
+	title       string
+	description string
+	priority    models.Priority
+	status      models.Status
+	labels      []string
+	custom      models.CustomValues
+}
+
+func storyListItem(story *models.Story) listItem {
+	return listItem{
+		id:          story.ID,
+		createdAt:   story.CreatedAt,
+		planStart:   story.PlanStart,
+		title:       story.Title,
+		description: story.Description,
+		priority:    story.Priority,
+		status:      story.Status,
+		labels:      story.Labels,
+		custom:      story.CustomFields,
+	}
+}
+
+func subTaskListItem(subtask *models.SubTask) listItem {
+	return listItem{
+		id:          subtask.ID,
+		createdAt:   subtask.CreatedAt,
+		planStart:   subtask.PlanStart,
+		title:       subtask.Title,
+		description: subtask.Description,
+		priority:    subtask.Priority,
+		status:      subtask.Status,
+		labels:      subtask.Labels,
+		custom:      subtask.CustomFields,
+	}
+}
+
+// matchesFilter reports whether an item passes every criterion of a filter.
+// Callers must hold the lock.
+func (s *Service) matchesFilter(item listItem, filter models.ListFilter) bool {
+	if len(filter.Priorities) > 0 {
+		found := false
+		for _, p := range filter.Priorities {
+			if p == item.priority {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, ref := range filter.Labels {
+		found := false
+		for _, id := range item.labels {
+			if id == ref {
+				found = true
+				break
+			}
+			if label, exists := s.labels[id]; exists && strings.EqualFold(label.Name, ref) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for key, want := range filter.CustomFields {
+		value, exists := item.custom[key]
+		if !exists || !strings.EqualFold(formatCustomValue(value), want) {
+			return false
+		}
+	}
+
+	if query := strings.ToLower(strings.TrimSpace(filter.Query)); query != "" {
+		if !strings.Contains(strings.ToLower(item.title), query) &&
+			!strings.Contains(strings.ToLower(item.description), query) &&
+			!customValuesContain(item.custom, query) {
+			return false
+		}
+	}
+	return true
+}
+
+// customValuesContain reports whether any textual custom value contains the
+// lower-case query
+func customValuesContain(values models.CustomValues, query string) bool {
+	for _, value := range values {
+		if text, ok := value.(string); ok && strings.Contains(strings.ToLower(text), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusOrder places statuses in workflow order for sorting
+var statusOrder = map[models.Status]int{
+	models.StatusTodo:       1,
+	models.StatusInProgress: 2,
+	models.StatusBlocked:    3,
+	models.StatusDone:       4,
+}
+
+// validateSort checks that a sort expression uses a known key. Custom field
+// keys must be defined on the backlog. Callers must hold the lock.
+func (s *Service) validateSort(backlogID, sortBy string) error {
+	key := strings.TrimPrefix(sortBy, "-")
+	switch key {
+	case "", models.SortCreatedAt, models.SortPriority, models.SortPlanStart, models.SortTitle, models.SortStatus:
+		return nil
+	}
+	if strings.HasPrefix(key, models.SortCustomFieldPrefix) {
+		if s.customFieldByKey(backlogID, strings.TrimPrefix(key, models.SortCustomFieldPrefix)) != nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot sort by %q", ErrInvalidRequest, sortBy)
+}
+
+// lessBySort compares two items on a sort expression, falling back to
+// creation order so results are stable
+func lessBySort(sortBy string, a, b listItem) bool {
+	descending := strings.HasPrefix(sortBy, "-")
+	key := strings.TrimPrefix(sortBy, "-")
+	cmp := 0
+	switch key {
+	case models.SortPriority:
+		cmp = a.priority.Rank() - b.priority.Rank()
+	case models.SortPlanStart:
+		cmp = compareTimes(a.planStart, b.planStart)
+	case models.SortTitle:
+		cmp = strings.Compare(strings.ToLower(a.title), strings.ToLower(b.title))
+	case models.SortStatus:
+		cmp = statusOrder[a.status] - statusOrder[b.status]
+	default:
+		if strings.HasPrefix(key, models.SortCustomFieldPrefix) {
+			field := strings.TrimPrefix(key, models.SortCustomFieldPrefix)
+			cmp = compareCustomValues(a.custom[field], b.custom[field])
+		}
+	}
+	if cmp == 0 {
+		if cmp = compareTimes(a.createdAt, b.createdAt); cmp == 0 {
+			cmp = strings.Compare(a.id, b.id)
+		}
+	}
+	if descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// compareCustomValues orders custom values, numbers numerically and
+// everything else by text. Items without a value come first.
+func compareCustomValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(formatCustomValue(a)), strings.ToLower(formatCustomValue(b)))
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// taskNode is a schedulable unit of work: a subtask, or a story without
+// subtasks. Stories with subtasks are summaries of their children.
+type taskNode struct {
+	id       string
+	itemType models.ItemType
+	story    *models.Story
+	subtask  *models.SubTask
+	preds    []string
+	succs    []string
+}
+
+// dependencyGraph is the precedence graph of the leaf tasks in a backlog.
+// Blocking links between stories are expanded to every pair of their leaves.
+type dependencyGraph struct {
+	stories  []*models.Story
+	children map[string][]*models.SubTask
+	nodes    map[string]*taskNode
+	order    []string
+	edges    []models.GanttEdge
+}
+
+// leaves returns the IDs of the leaf tasks that make up a story
+func (g *dependencyGraph) leaves(storyID string) []string {
+	children := g.children[storyID]
+	if len(children) == 0 {
+		return []string{storyID}
+	}
+	ids := make([]string, len(children))
+	for i, subtask := range children {
+		ids[i] = subtask.ID
+	}
+	return ids
+}
+
+// buildDependencyGraph builds the dependency graph of a backlog and orders
+// its leaves topologically. Callers must hold the lock.
+func (s *Service) buildDependencyGraph(backlogID string) (*dependencyGraph, error) {
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+
+	g := &dependencyGraph{
+		children: make(map[string][]*models.SubTask),
+		nodes:    make(map[string]*taskNode),
+	}
+	g.stories = s.sortedStories(backlogID)
+	inBacklog := make(map[string]bool)
+	for _, story := range g.stories {
+		inBacklog[story.ID] = true
+		if subtasks := s.sortedSubTasks(story.ID); len(subtasks) > 0 {
+			g.children[story.ID] = subtasks
+		}
+	}
+
+	var leafOrder []string
+	for _, story := range g.stories {
+		children := g.children[story.ID]
+		if len(children) == 0 {
+			g.nodes[story.ID] = &taskNode{id: story.ID, itemType: models.ItemStory, story: story}
+			leafOrder = append(leafOrder, story.ID)
+			continue
+		}
+		for _, subtask := range children {
+			g.nodes[subtask.ID] = &taskNode{id: subtask.ID, itemType: models.ItemSubTask, subtask: subtask}
+			leafOrder = append(leafOrder, subtask.ID)
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	addEdge := func(from, to string) {
+		key := [2]string{from, to}
+		if from == to || seen[key] {
+			return
+		}
+		seen[key] = true
+		g.nodes[from].succs = append(g.nodes[from].succs, to)
+		g.nodes[to].preds = append(g.nodes[to].preds, from)
+	}
+
+	links := make([]*models.Link, 0, len(s.links))
+	for _, link := range s.links {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.Before(links[j].CreatedAt) })
+
+	for _, link := range links {
+		blocker, blocked, ok := link.Blocking()
+		if !ok {
+			continue
+		}
+		switch link.ItemType {
+		case models.ItemStory:
+			if !inBacklog[blocker] || !inBacklog[blocked] {
+				continue
+			}
+			for _, from := range g.leaves(blocker) {
+				for _, to := range g.leaves(blocked) {
+					addEdge(from, to)
+				}
+			}
+		case models.ItemSubTask:
+			if g.nodes[blocker] == nil || g.nodes[blocked] == nil {
+				continue
+			}
+			addEdge(blocker, blocked)
+		}
+		g.edges = append(g.edges, models.GanttEdge{
+			FromID:   blocker,
+			ToID:     blocked,
+			ItemType: link.ItemType,
+			Type:     models.LinkBlocks,
+		})
+	}
+
+	// Kahn's algorithm, seeded in plan order so the result is stable
+	inDegree := make(map[string]int, len(g.nodes))
+	for id, node := range g.nodes {
+		inDegree[id] = len(node.preds)
+	}
+	queue := []string{}
+	for _, id := range leafOrder {
+		if inDegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		g.order = append(g.order, id)
+		for _, next := range g.nodes[id].succs {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(g.order) != len(g.nodes) {
+		return nil, ErrDependencyCycle
+	}
+
+	return g, nil
+}
+
+// lessByPlan orders work items by plan start, then creation time, then ID.
+// Items without a plan start come last.
+func lessByPlan(startA, createdA time.Time, idA string, startB, createdB time.Time, idB string) bool {
+	if startA.IsZero() != startB.IsZero() {
+		return !startA.IsZero()
+	}
+	if !startA.Equal(startB) {
+		return startA.Before(startB)
+	}
+	if !createdA.Equal(createdB) {
+		return createdA.Before(createdB)
+	}
+	return idA < idB
+}
+
+// durationDays returns the length of a task in days, taken from its plan
+// dates when they are set and from its effort otherwise
+func (s *Service) durationDays(planStart, planEnd time.Time, effort int) int {
+	if !planStart.IsZero() && planEnd.After(planStart) {
+		return int(math.Ceil(planEnd.Sub(planStart).Hours() / 24))
+	}
+	if effort > 0 && s.config.WorkHoursPerDay > 0 {
+		return int(math.Ceil(float64(effort) / float64(s.config.WorkHoursPerDay)))
+	}
+	return 1
+}
+
+// startOfDay truncates a time to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// GetGanttChart computes the timeline of a backlog, including the earliest
+// and latest schedule of each task, its slack and the critical path
+func (s *Service) GetGanttChart(backlogID string) (*models.GanttChart, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	g, err := s.buildDependencyGraph(backlogID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The project starts on the earliest planned date in the backlog
+	var projectStart time.Time
+	consider := func(t time.Time) {
+		if !t.IsZero() && (projectStart.IsZero() || t.Before(projectStart)) {
+			projectStart = t
+		}
+	}
+	for _, story := range g.stories {
+		consider(story.PlanStart)
+		for _, subtask := range g.children[story.ID] {
+			consider(subtask.PlanStart)
+		}
+	}
+	if projectStart.IsZero() {
+		projectStart = time.Now()
+	}
+	projectStart = startOfDay(projectStart)
+	offset := func(t time.Time) int {
+		return int(startOfDay(t.In(projectStart.Location())).Sub(projectStart) / day)
+	}
+	date := func(days int) time.Time {
+		return projectStart.AddDate(0, 0, days)
+	}
+
+	// Forward pass: a task starts once its predecessors have finished and
+	// never before its own planned start
+	duration := make(map[string]int, len(g.nodes))
+	es := make(map[string]int, len(g.nodes))
+	ef := make(map[string]int, len(g.nodes))
+	projectEnd := 0
+	for _, id := range g.order {
+		node := g.nodes[id]
+		var planStart time.Time
+		if node.subtask != nil {
+			planStart = node.subtask.PlanStart
+			duration[id] = s.durationDays(node.subtask.PlanStart, node.subtask.PlanEnd, node.subtask.Effort)
+		} else {
+			planStart = node.story.PlanStart
+			duration[id] = s.durationDays(node.story.PlanStart, node.story.PlanEnd, node.story.EffortOrigin)
+		}
+		start := 0
+		if !planStart.IsZero() {
+			start = offset(planStart)
+		}
+		for _, pred := range node.preds {
+			if ef[pred] > start {
+				start = ef[pred]
+			}
+		}
+		es[id] = start
+		ef[id] = start + duration[id]
+		if ef[id] > projectEnd {
+			projectEnd = ef[id]
+		}
+	}
+
+	// Backward pass
+	ls := make(map[string]int, len(g.nodes))
+	lf := make(map[string]int, len(g.nodes))
+	for i := len(g.order) - 1; i >= 0; i-- {
+		id := g.order[i]
+		finish := projectEnd
+		for _, succ := range g.nodes[id].succs {
+			if ls[succ] < finish {
+				finish = ls[succ]
+			}
+		}
+		lf[id] = finish
+		ls[id] = finish - duration[id]
+	}
+
+	chart := &models.GanttChart{
+		BacklogID:     backlogID,
+		ProjectStart:  projectStart,
+		ProjectFinish: date(projectEnd),
+		Tasks:         []models.GanttTask{},
+		Edges:         g.edges,
+		CriticalPath:  []string{},
+	}
+	if chart.Edges == nil {
+		chart.Edges = []models.GanttEdge{}
+	}
+
+	leafTask := func(id string) models.GanttTask {
+		return models.GanttTask{
+			DurationDays:   duration[id],
+			EarliestStart:  date(es[id]),
+			EarliestFinish: date(ef[id]),
+			LatestStart:    date(ls[id]),
+			LatestFinish:   date(lf[id]),
+			SlackDays:      ls[id] - es[id],
+			Critical:       ls[id] == es[id],
+		}
+	}
+
+	for _, story := range g.stories {
+		var task models.GanttTask
+		children := g.children[story.ID]
+		if len(children) == 0 {
+			task = leafTask(story.ID)
+		} else {
+			// Summary tasks span their subtasks
+			task.Summary = true
+			first := true
+			minES, maxEF, minLS, maxLF, slack := 0, 0, 0, 0, 0
+			for _, subtask := range children {
+				id := subtask.ID
+				if first || es[id] < minES {
+					minES = es[id]
+				}
+				if first || ef[id] > maxEF {
+					maxEF = ef[id]
+				}
+				if first || ls[id] < minLS {
+					minLS = ls[id]
+				}
+				if first || lf[id] > maxLF {
+					maxLF = lf[id]
+				}
+				if first || ls[id]-es[id] < slack {
+					slack = ls[id] - es[id]
+				}
+				first = false
+			}
+			task.DurationDays = maxEF - minES
+			task.EarliestStart = date(minES)
+			task.EarliestFinish = date(maxEF)
+			task.LatestStart = date(minLS)
+			task.LatestFinish = date(maxLF)
+			task.SlackDays = slack
+			task.Critical = slack == 0
+		}
+		task.ID = story.ID
+		task.ItemType = models.ItemStory
+		task.Title = story.Title
+		task.PIC = story.PIC
+		task.Status = story.Status
+		task.Plan = planBar(story.PlanStart, story.PlanEnd)
+		task.Actual = models.GanttBar{Start: story.ActualStart, End: story.ActualEnd}
+		chart.Tasks = append(chart.Tasks, task)
+
+		for _, subtask := range children {
+			task := leafTask(subtask.ID)
+			task.ID = subtask.ID
+			task.ItemType = models.ItemSubTask
+			task.ParentID = story.ID
+			task.Title = subtask.Title
+			task.PIC = subtask.PIC
+			task.Status = subtask.Status
+			task.Plan = planBar(subtask.PlanStart, subtask.PlanEnd)
+			task.Actual = models.GanttBar{Start: subtask.ActualStart, End: subtask.ActualEnd}
+			chart.Tasks = append(chart.Tasks, task)
+		}
+	}
+
+	// Walk back from the task that finishes last along the predecessors
+	// that drive each start date
+	current := ""
+	for _, id := range g.order {
+		if ef[id] == projectEnd && ls[id] == es[id] {
+			current = id
+			break
+		}
+	}
+	var path []string
+	for current != "" {
+		path = append(path, current)
+		next := ""
+		for _, pred := range g.nodes[current].preds {
+			if ef[pred] == es[current] && ls[pred] == es[pred] {
+				next = pred
+				break
+			}
+		}
+		current = next
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		chart.CriticalPath = append(chart.CriticalPath, path[i])
+	}
+
+	return chart, nil
+}
+
+// planBar converts plan dates into a bar, leaving unset dates empty
+func planBar(start, end time.Time) models.GanttBar {
+	var bar models.GanttBar
+	if !start.IsZero() {
+		bar.Start = &start
+	}
+	if !end.IsZero() {
+		bar.End = &end
+	}
+	return bar
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"fmt"
+	"golang-baseline/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// importStoryPlan is a validated story of an import document together with
+// the stored story it updates, if any
+type importStoryPlan struct {
+	doc          models.ImportStory
+	path         string
+	existing     *models.Story
+	customFields models.CustomValues
+	subtasks     []importSubTaskPlan
+}
+
+// importSubTaskPlan is a validated subtask of an import document together
+// with the stored subtask it updates, if any
+type importSubTaskPlan struct {
+	doc          models.ImportSubTask
+	path         string
+	existing     *models.SubTask
+	customFields models.CustomValues
+}
+
+// Import operations
+
+// ImportBacklog creates or updates a whole backlog tree. The document is
+// validated in full first and nothing is stored unless it is valid, so an
+// import never leaves a half-built backlog. Items whose external key
+// matches an earlier import are updated, including subtasks that moved to
+// another story; other items are created. Status changes are checked
+// against the workflow rules like any other status change.
+func (s *Service) ImportBacklog(doc models.ImportDocument) (*models.ImportResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var backlog *models.Backlog
+	backlogID := ""
+	if doc.ExternalKey != "" {
+		if backlog = s.backlogByExternalKey(doc.ExternalKey); backlog != nil {
+			backlogID = backlog.ID
+		}
+	}
+	// An import may add labels to an existing backlog, so it takes the
+	// manage permission
+	if err := s.authorize(backlogID, models.PermManage); err != nil {
+		return nil, err
+	}
+
+	var issues, warnings []models.ImportIssue
+	report := func(path string, err error) {
+		issues = append(issues, models.ImportIssue{
+			Path:  path,
+			Error: strings.TrimPrefix(err.Error(), ErrInvalidRequest.Error()+": "),
+		})
+	}
+
+	if strings.TrimSpace(doc.Title) == "" {
+		report("title", fmt.Errorf("title is required"))
+	}
+
+	// Stories and subtasks may use the labels of the backlog and those
+	// declared in the document
+	labelNames := make(map[string]bool)
+	for _, label := range s.labels {
+		if backlogID != "" && label.BacklogID == backlogID {
+			labelNames[strings.ToLower(label.Name)] = true
+		}
+	}
+	declared := make(map[string]bool)
+	for i, label := range doc.Labels {
+		name := strings.ToLower(strings.TrimSpace(label.Name))
+		switch {
+		case name == "":
+			report(fmt.Sprintf("labels[%d].name", i), fmt.Errorf("name is required"))
+		case declared[name]:
+			report(fmt.Sprintf("labels[%d].name", i), fmt.Errorf("label %q is declared twice", label.Name))
+		}
+		declared[name] = true
+		labelNames[name] = true
+	}
+
+	keys := make(map[string]string)
+	checkItem := func(path, key, title string, status models.Status, priority models.Priority, labels []string) {
+		if key != "" {
+			if other, duplicate := keys[key]; duplicate {
+				report(path+".external_key", fmt.Errorf("external key %q is also used by %s", key, other))
+			}
+			keys[key] = path
+		}
+		if strings.TrimSpace(title) == "" {
+			report(path+".title", fmt.Errorf("title is required"))
+		}
+		if status != "" && !status.IsValid() {
+			report(path+".status", fmt.Errorf("unknown status %q", status))
+		}
+		if priority != "" && !priority.IsValid() {
+			report(path+".priority", fmt.Errorf("unknown priority %q", priority))
+		}
+		for _, name := range labels {
+			if !labelNames[strings.ToLower(name)] {
+				report(path+".labels", fmt.Errorf("label %q is neither in the backlog nor declared in the document", name))
+			}
+		}
+	}
+
+	// Status changes follow the workflow rules, as if made one by one
+	warn := func(path string, messages []string) {
+		for _, message := range messages {
+			warnings = append(warnings, models.ImportIssue{Path: path, Error: message})
+		}
+	}
+	pending := make(map[models.Status]int)
+
+	var plans []importStoryPlan
+	for i, story := range doc.Stories {
+		plan := importStoryPlan{doc: story, path: fmt.Sprintf("stories[%d]", i)}
+		checkItem(plan.path, story.ExternalKey, story.Title, story.Status, story.Priority, story.Labels)
+		if backlogID != "" && story.ExternalKey != "" {
+			plan.existing = s.storyByExternalKey(backlogID, story.ExternalKey)
+		}
+		if story.Status.IsValid() {
+			// A new story starts in TODO, without blockers or checklist
+			current := plan.existing
+			if current == nil {
+				current = &models.Story{BacklogID: backlogID, Status: models.StatusTodo}
+			}
+			messages, err := s.checkStoryStatus(current, story.Status, pending[story.Status])
+			if err != nil {
+				report(plan.path+".status", err)
+			}
+			warn(plan.path+".status", messages)
+			if current.Status != story.Status {
+				pending[story.Status]++
+			}
+		}
+		var current models.CustomValues
+		if plan.existing != nil {
+			current = plan.existing.CustomFields
+		}
+		customFields, err := s.applyCustomValues(backlogID, current, story.CustomFields, plan.existing == nil)
+		if err != nil {
+			report(plan.path+".custom_fields", err)
+		}
+		plan.customFields = customFields
+
+		for j, subtask := range story.SubTasks {
+			subtaskPlan := importSubTaskPlan{doc: subtask, path: fmt.Sprintf("%s.subtasks[%d]", plan.path, j)}
+			checkItem(subtaskPlan.path, subtask.ExternalKey, subtask.Title, subtask.Status, subtask.Priority, subtask.Labels)
+			if backlogID != "" && subtask.ExternalKey != "" {
+				subtaskPlan.existing = s.subTaskByExternalKey(backlogID, subtask.ExternalKey)
+			}
+			// A new subtask has no blockers, so only existing ones are checked
+			if subtaskPlan.existing != nil && subtask.Status.IsValid() {
+				messages, err := s.checkSubTaskStatus(subtaskPlan.existing, subtask.Status)
+				if err != nil {
+					report(subtaskPlan.path+".status", err)
+				}
+				warn(subtaskPlan.path+".status", messages)
+			}
+			var current models.CustomValues
+			if subtaskPlan.existing != nil {
+				current = subtaskPlan.existing.CustomFields
+			}
+			customFields, err := s.applyCustomValues(backlogID, current, subtask.CustomFields, subtaskPlan.existing == nil)
+			if err != nil {
+				report(subtaskPlan.path+".custom_fields", err)
+			}
+			subtaskPlan.customFields = customFields
+			plan.subtasks = append(plan.subtasks, subtaskPlan)
+		}
+		plans = append(plans, plan)
+	}
+
+	if len(issues) > 0 {
+		return &models.ImportResult{Errors: issues}, fmt.Errorf("%w: %d problem(s) in the document", ErrInvalidRequest, len(issues))
+	}
+
+	// The document is valid; from here on nothing can fail
+	now := time.Now()
+	result := &models.ImportResult{Mapping: []models.ImportMapping{}, Warnings: warnings}
+	record := func(kind, path, key, id string, created bool) {
+		action := models.ImportUpdated
+		if created {
+			action = models.ImportCreated
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.Mapping = append(result.Mapping, models.ImportMapping{
+			Kind: kind, Path: path, ExternalKey: key, ID: id, Action: action,
+		})
+	}
+
+	var backlogBefore *snapshot
+	if backlog == nil {
+		backlog = &models.Backlog{
+			ID:          uuid.New().String(),
+			WorkspaceID: s.workspace(),
+			ExternalKey: doc.ExternalKey,
+			WIPLimits:   models.WIPLimits{},
+			Stories:     []models.Story{},
+			CreatedAt:   now,
+		}
+		s.backlogs[backlog.ID] = backlog
+		record("backlog", "", doc.ExternalKey, backlog.ID, true)
+	} else {
+		backlogBefore = s.snapshot(backlog)
+		record("backlog", "", doc.ExternalKey, backlog.ID, false)
+	}
+	backlog.Title = doc.Title
+	backlog.Description = doc.Description
+	backlog.UpdatedAt = now
+	result.BacklogID = backlog.ID
+	s.publishSaved(models.EventBacklogCreated, models.EventBacklogUpdated, backlogBefore, s.snapshot(backlog))
+	if backlogBefore == nil {
+		s.grantOwner(backlog.ID)
+	}
+
+	for _, item := range doc.Labels {
+		if label := s.labelByName(backlog.ID, strings.TrimSpace(item.Name)); label != nil {
+			if item.Color != "" {
+				before := s.snapshot(label)
+				label.Color = item.Color
+				label.UpdatedAt = now
+				s.publishIfChanged(models.EventLabelUpdated, before, s.snapshot(label))
+			}
+			continue
+		}
+		label := &models.Label{
+			ID:        uuid.New().String(),
+			BacklogID: backlog.ID,
+			Name:      strings.TrimSpace(item.Name),
+			Color:     item.Color,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		s.labels[label.ID] = label
+		s.publish(models.EventLabelCreated, nil, s.snapshot(label))
+	}
+
+	for _, plan := range plans {
+		story := plan.existing
+		var before *snapshot
+		if story != nil {
+			before = s.snapshot(s.storyView(story))
+		} else {
+			story = &models.Story{
+				ID:          uuid.New().String(),
+				BacklogID:   backlog.ID,
+				ExternalKey: plan.doc.ExternalKey,
+				Status:      models.StatusTodo,
+				Priority:    models.PriorityMedium,
+				Checklist:   []models.ChecklistItem{},
+				SubTasks:    []models.SubTask{},
+				CreatedAt:   now,
+			}
+			s.stories[story.ID] = story
+		}
+		story.Title = plan.doc.Title
+		story.Description = plan.doc.Description
+		story.JiraURL = plan.doc.JiraURL
+		story.EffortOrigin = plan.doc.EffortOrigin
+		story.PIC = plan.doc.PIC
+		story.PlanStart = plan.doc.PlanStart
+		story.PlanEnd = plan.doc.PlanEnd
+		if plan.doc.Priority != "" {
+			story.Priority = plan.doc.Priority
+		}
+		story.Labels = s.labelIDs(backlog.ID, plan.doc.Labels)
+		story.CustomFields = plan.customFields
+		story.UpdatedAt = now
+		record("story", plan.path, plan.doc.ExternalKey, story.ID, plan.existing == nil)
+		s.publishSaved(models.EventStoryCreated, models.EventStoryUpdated, before, s.snapshot(s.storyView(story)))
+		if plan.doc.Status != "" {
+			s.setStoryStatus(story, plan.doc.Status)
+		}
+
+		for _, subtaskPlan := range plan.subtasks {
+			subtask := subtaskPlan.existing
+			var before *snapshot
+			if subtask != nil {
+				before = s.snapshot(s.subTaskView(subtask))
+			} else {
+				subtask = &models.SubTask{
+					ID:          uuid.New().String(),
+					ExternalKey: subtaskPlan.doc.ExternalKey,
+					Status:      models.StatusTodo,
+					Priority:    models.PriorityMedium,
+					Checklist:   []models.ChecklistItem{},
+					CreatedAt:   now,
+				}
+				s.subtasks[subtask.ID] = subtask
+			}
+			subtask.StoryID = story.ID
+			subtask.Title = subtaskPlan.doc.Title
+			subtask.Description = subtaskPlan.doc.Description
+			subtask.Effort = subtaskPlan.doc.Effort
+			subtask.JiraURL = subtaskPlan.doc.JiraURL
+			subtask.PIC = subtaskPlan.doc.PIC
+			subtask.PlanStart = subtaskPlan.doc.PlanStart
+			subtask.PlanEnd = subtaskPlan.doc.PlanEnd
+			if subtaskPlan.doc.Priority != "" {
+				subtask.Priority = subtaskPlan.doc.Priority
+			}
+			subtask.Labels = s.labelIDs(backlog.ID, subtaskPlan.doc.Labels)
+			subtask.CustomFields = subtaskPlan.customFields
+			subtask.UpdatedAt = now
+			record("subtask", subtaskPlan.path, subtaskPlan.doc.ExternalKey, subtask.ID, subtaskPlan.existing == nil)
+			s.publishSaved(models.EventSubTaskCreated, models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(subtask)))
+			if subtaskPlan.doc.Status != "" {
+				s.setSubTaskStatus(subtask, subtaskPlan.doc.Status)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// backlogByExternalKey finds a backlog of the request's workspace by the
+// external key it was imported with. Callers must hold the lock.
+func (s *Service) backlogByExternalKey(key string) *models.Backlog {
+	for _, backlog := range s.backlogs {
+		if backlog.ExternalKey == key && backlog.WorkspaceID == s.workspace() {
+			return backlog
+		}
+	}
+	return nil
+}
+
+// storyByExternalKey finds a story of a backlog by the external key it was
+// imported with. Callers must hold the lock.
+func (s *Service) storyByExternalKey(backlogID, key string) *models.Story {
+	for _, story := range s.stories {
+		if story.BacklogID == backlogID && story.ExternalKey == key {
+			return story
+		}
+	}
+	return nil
+}
+
+// subTaskByExternalKey finds a subtask anywhere in a backlog by the
+// external key it was imported with. Callers must hold the lock.
+func (s *Service) subTaskByExternalKey(backlogID, key string) *models.SubTask {
+	for _, subtask := range s.subtasks {
+		if subtask.ExternalKey == key && s.backlogOfSubTask(subtask) == backlogID {
+			return subtask
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/config"
+	"golang-baseline/models"
+	"testing"
+)
+
+func TestImportFollowsWorkflowRules(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) { cfg.RequireChecklistForDone = true })
+	doc := models.ImportDocument{ExternalKey: "B1", Title: "Backlog", Stories: []models.ImportStory{{ExternalKey: "S1", Title: "Story"}}}
+	result, err := s.ImportBacklog(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storyID := result.Mapping[1].ID
+	if _, err := s.AddChecklistItem(models.ItemStory, storyID, models.CreateChecklistItemRequest{Text: "Review", Required: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The required checklist item keeps the story from being closed
+	doc.Stories[0].Status = models.StatusDone
+	result, err = s.ImportBacklog(doc)
+	if !errors.Is(err, ErrInvalidRequest) || len(result.Errors) != 1 || result.Errors[0].Path != "stories[0].status" {
+		t.Fatalf("result = %+v, error = %v", result, err)
+	}
+	if story, _ := s.GetStory(storyID); story.Status != models.StatusTodo {
+		t.Errorf("status = %s", story.Status)
+	}
+
+	doc.Stories[0].Status = models.StatusInProgress
+	if _, err := s.ImportBacklog(doc); err != nil {
+		t.Fatal(err)
+	}
+	if story, _ := s.GetStory(storyID); story.Status != models.StatusInProgress || story.ActualStart == nil {
+		t.Errorf("story = %+v", story)
+	}
+}
@@ -0,0 +1,873 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-baseline/jira"
+	"golang-baseline/models"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errJiraNotConfigured = fmt.Errorf("%w: Jira is not configured", ErrInvalidRequest)
+
+// jiraSyncTarget is a linked item as it was before Jira was called.
+// Skipped explains why an item pointing at an issue is not synced.
+type jiraSyncTarget struct {
+	workspace  string
+	link       models.JiraLink
+	local      models.JiraValues
+	conflicted map[string]bool
+	skipped    string
+}
+
+// jiraSyncPlan sorts the fields of a linked item by what a sync does with
+// them
+type jiraSyncPlan struct {
+	agreed    models.JiraValues
+	push      models.JiraValues
+	pull      models.JiraValues
+	conflicts []string
+}
+
+// Jira operations
+
+// ImportJiraProject imports the issues of a Jira project into a backlog.
+// Issues become stories and Jira subtasks become subtasks of their parent.
+// Importing a project again updates the same backlog; titles, descriptions
+// and labels follow Jira, while status, assignee and dates of items that
+// are already linked are left to the sync so conflicts can be detected.
+func (s *Service) ImportJiraProject(ctx context.Context, req models.JiraImportRequest) (*models.JiraImportResult, error) {
+	if s.jira == nil {
+		return nil, errJiraNotConfigured
+	}
+	s.mutex.RLock()
+	err := s.requireAdmin()
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ProjectKey) == "" {
+		return nil, fmt.Errorf("%w: project_key is required", ErrInvalidRequest)
+	}
+
+	project, err := s.jira.GetProject(ctx, strings.TrimSpace(req.ProjectKey))
+	if err != nil {
+		return nil, jiraError(err, "Jira project not found")
+	}
+	issues, err := s.jira.SearchIssues(ctx, fmt.Sprintf("project = %q ORDER BY key ASC", project.Key), s.jiraIssueFields())
+	if err != nil {
+		return nil, jiraError(err, "")
+	}
+
+	doc := models.ImportDocument{
+		ExternalKey: "jira:" + project.Key,
+		Title:       project.Name,
+		Description: project.Description,
+	}
+	if doc.Title == "" {
+		doc.Title = project.Key
+	}
+	result := &models.JiraImportResult{ProjectKey: project.Key}
+
+	// Linked items keep their current values of the synchronised fields
+	local := s.linkedJiraValues(doc.ExternalKey)
+	remote := make(map[string]models.JiraValues, len(issues))
+	values := func(issue jira.Issue) models.JiraValues {
+		remote[issue.Key] = s.jiraRemoteValues(&issue)
+		if current, linked := local[issue.Key]; linked {
+			return current
+		}
+		return remote[issue.Key]
+	}
+
+	labels := make(map[string]bool)
+	declare := func(names []string) {
+		for _, name := range names {
+			if !labels[strings.ToLower(name)] {
+				labels[strings.ToLower(name)] = true
+				doc.Labels = append(doc.Labels, models.ImportLabel{Name: name})
+			}
+		}
+	}
+
+	// Stories first, so subtasks find their parent whatever the order
+	stories := make(map[string]int)
+	for _, issue := range issues {
+		if issue.Fields.IssueType.Subtask {
+			continue
+		}
+		v := values(issue)
+		declare(issue.Fields.Labels)
+		stories[issue.Key] = len(doc.Stories)
+		doc.Stories = append(doc.Stories, models.ImportStory{
+			ExternalKey:  issue.Key,
+			Title:        issue.Fields.Summary,
+			Description:  issue.Fields.Description,
+			JiraURL:      s.jira.IssueURL(issue.Key),
+			EffortOrigin: issue.Fields.OriginalEstimate / 3600,
+			PIC:          v[models.JiraFieldPIC],
+			PlanStart:    parseJiraDate(v[models.JiraFieldPlanStart]),
+			PlanEnd:      parseJiraDate(v[models.JiraFieldPlanEnd]),
+			Status:       models.Status(v[models.JiraFieldStatus]),
+			Priority:     jiraPriority(issue.Fields.Priority),
+			Labels:       issue.Fields.Labels,
+		})
+	}
+	for _, issue := range issues {
+		if !issue.Fields.IssueType.Subtask {
+			continue
+		}
+		parent := ""
+		if issue.Fields.Parent != nil {
+			parent = issue.Fields.Parent.Key
+		}
+		i, exists := stories[parent]
+		if !exists {
+			result.Skipped = append(result.Skipped, models.ImportIssue{
+				Path:  issue.Key,
+				Error: fmt.Sprintf("parent %q is not an issue of project %s", parent, project.Key),
+			})
+			continue
+		}
+		v := values(issue)
+		declare(issue.Fields.Labels)
+		doc.Stories[i].SubTasks = append(doc.Stories[i].SubTasks, models.ImportSubTask{
+			ExternalKey: issue.Key,
+			Title:       issue.Fields.Summary,
+			Description: issue.Fields.Description,
+			Effort:      issue.Fields.OriginalEstimate / 3600,
+			JiraURL:     s.jira.IssueURL(issue.Key),
+			PIC:         v[models.JiraFieldPIC],
+			PlanStart:   parseJiraDate(v[models.JiraFieldPlanStart]),
+			PlanEnd:     parseJiraDate(v[models.JiraFieldPlanEnd]),
+			Status:      models.Status(v[models.JiraFieldStatus]),
+			Priority:    jiraPriority(issue.Fields.Priority),
+			Labels:      issue.Fields.Labels,
+		})
+	}
+
+	imported, err := s.ImportBacklog(doc)
+	if imported != nil {
+		result.ImportResult = *imported
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// New links start from the values just imported
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	for _, mapping := range imported.Mapping {
+		itemType := models.ItemType(mapping.Kind)
+		if itemType != models.ItemStory && itemType != models.ItemSubTask {
+			continue
+		}
+		if _, linked := s.jiraLinks[mapping.ID]; linked {
+			continue
+		}
+		s.jiraLinks[mapping.ID] = &models.JiraLink{
+			ItemType: itemType,
+			ItemID:   mapping.ID,
+			IssueKey: mapping.ExternalKey,
+			Synced:   remote[mapping.ExternalKey],
+			SyncedAt: &now,
+		}
+	}
+	return result, nil
+}
+
+// SyncJira synchronises status, assignee and plan dates of every story and
+// subtask whose JiraURL points at the configured site. A field changed on
+// one side only since the last sync is copied to the other; a field changed
+// on both sides is reported as a conflict and left alone until resolved.
+// Only the items of the workspace of the request are synchronised, unless
+// it may manage every workspace. Each workspace keeps a report of its
+// latest sync; the one of the workspace of the request is returned.
+func (s *Service) SyncJira(ctx context.Context) (*models.JiraSyncReport, error) {
+	if s.jira == nil {
+		return nil, errJiraNotConfigured
+	}
+	s.mutex.RLock()
+	err := s.requireAdmin()
+	workspaceID, scope := s.workspace(), s.workspace()
+	if s.isInstanceAdmin() {
+		scope = ""
+	}
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	s.jiraSyncing.Lock()
+	defer s.jiraSyncing.Unlock()
+
+	started := time.Now()
+	reports := map[string]*models.JiraSyncReport{}
+	reportOf := func(workspaceID string) *models.JiraSyncReport {
+		if reports[workspaceID] == nil {
+			reports[workspaceID] = &models.JiraSyncReport{
+				StartedAt: started,
+				Changes:   []models.JiraChange{},
+				Conflicts: []models.JiraConflict{},
+			}
+		}
+		return reports[workspaceID]
+	}
+	reportOf(workspaceID)
+	// Jira is called without holding the lock; the results are applied
+	// only where the item did not change in the meantime
+	for _, target := range s.jiraSyncTargets(scope) {
+		report := reportOf(target.workspace)
+		report.Checked++
+		if target.skipped != "" {
+			report.Errors = append(report.Errors, models.JiraSyncError{IssueKey: target.link.IssueKey, Error: target.skipped})
+			continue
+		}
+		issue, err := s.jira.GetIssue(ctx, target.link.IssueKey, s.jiraIssueFields())
+		if err != nil {
+			s.applyJiraSync(report, target, nil, jiraSyncPlan{}, nil, err)
+			continue
+		}
+		remote := s.jiraRemoteValues(issue)
+		plan := planJiraSync(target, remote)
+		pushed, err := s.pushJira(ctx, target.link.IssueKey, plan.push)
+		s.applyJiraSync(report, target, remote, plan, pushed, err)
+	}
+	finished := time.Now()
+
+	s.mutex.Lock()
+	if scope == "" {
+		s.jiraReports = make(map[string]*models.JiraSyncReport, len(reports))
+	}
+	for workspaceID, report := range reports {
+		report.FinishedAt = finished
+		s.jiraReports[workspaceID] = report
+	}
+	s.mutex.Unlock()
+	return reports[workspaceID], nil
+}
+
+// StartJiraSync runs a sync right away and then every interval in the
+// background. The returned function stops it.
+func (s *Service) StartJiraSync(interval time.Duration) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.SyncJira(context.Background())
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.SyncJira(context.Background())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// GetJiraSyncReport returns the report of the latest sync run of the
+// workspace
+func (s *Service) GetJiraSyncReport() (*models.JiraSyncReport, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	if s.jira == nil {
+		return nil, errJiraNotConfigured
+	}
+	report, exists := s.jiraReports[s.workspace()]
+	if !exists {
+		return nil, errors.New("no Jira sync has run yet")
+	}
+	return report, nil
+}
+
+func (s *Service) GetJiraLinks() ([]models.JiraLink, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	links := []models.JiraLink{}
+	for _, link := range s.jiraLinks {
+		if s.jiraWorkspace(link.ItemType, link.ItemID) == s.workspace() {
+			links = append(links, *link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].IssueKey < links[j].IssueKey })
+	return links, nil
+}
+
+func (s *Service) GetJiraConflicts() ([]models.JiraConflict, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	conflicts := []models.JiraConflict{}
+	for _, conflict := range s.jiraConflicts {
+		if s.jiraWorkspace(conflict.ItemType, conflict.ItemID) == s.workspace() {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if !conflicts[i].DetectedAt.Equal(conflicts[j].DetectedAt) {
+			return conflicts[i].DetectedAt.Before(conflicts[j].DetectedAt)
+		}
+		return conflicts[i].ID < conflicts[j].ID
+	})
+	return conflicts, nil
+}
+
+// ResolveJiraConflict picks the side that wins a conflict. The winning
+// value is copied to the other side by the next sync.
+func (s *Service) ResolveJiraConflict(id string, req models.ResolveJiraConflictRequest) (*models.JiraLink, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	conflict, exists := s.jiraConflicts[id]
+	if !exists || s.jiraWorkspace(conflict.ItemType, conflict.ItemID) != s.workspace() {
+		return nil, errors.New("conflict not found")
+	}
+	link, exists := s.jiraLinks[conflict.ItemID]
+	if !exists {
+		deleted := s.snapshot(conflict)
+		delete(s.jiraConflicts, id)
+		s.publish(models.EventJiraConflictDeleted, deleted, nil)
+		return nil, errors.New("the item is no longer linked to Jira")
+	}
+
+	// Recording the losing value as synced makes the winner look like the
+	// only side that changed
+	switch req.Keep {
+	case models.JiraKeepLocal:
+		link.Synced[conflict.Field] = conflict.Remote
+	case models.JiraKeepRemote:
+		link.Synced[conflict.Field] = conflict.Local
+	default:
+		return nil, fmt.Errorf("%w: keep must be local or remote", ErrInvalidRequest)
+	}
+	deleted := s.snapshot(conflict)
+	delete(s.jiraConflicts, id)
+	s.publish(models.EventJiraConflictDeleted, deleted, nil)
+
+	result := *link
+	return &result, nil
+}
+
+// jiraSyncTargets refreshes the links from the JiraURL of every item of a
+// workspace, or of every workspace when it is empty, and returns a
+// snapshot of them. Only one item may be linked to an issue, or the items
+// would overwrite each other in Jira: the item linked first keeps it, or
+// else the oldest, and the others are skipped.
+func (s *Service) jiraSyncTargets(workspaceID string) []jiraSyncTarget {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// candidate is an item whose JiraURL points at an issue
+	type candidate struct {
+		itemType  models.ItemType
+		id        string
+		key       string
+		workspace string
+		local     models.JiraValues
+		createdAt time.Time
+	}
+	var candidates []candidate
+	track := func(itemType models.ItemType, id, jiraURL string, local models.JiraValues, createdAt time.Time) {
+		if key, ok := s.jira.IssueKey(jiraURL); ok {
+			candidates = append(candidates, candidate{itemType, id, key, s.jiraWorkspace(itemType, id), local, createdAt})
+		}
+	}
+	for _, story := range s.stories {
+		track(models.ItemStory, story.ID, story.JiraURL, storyJiraValues(story), story.CreatedAt)
+	}
+	for _, subtask := range s.subtasks {
+		track(models.ItemSubTask, subtask.ID, subtask.JiraURL, subTaskJiraValues(subtask), subtask.CreatedAt)
+	}
+	linked := func(c candidate) bool {
+		link, exists := s.jiraLinks[c.id]
+		return exists && link.IssueKey == c.key
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.key != b.key {
+			return a.key < b.key
+		}
+		if linked(a) != linked(b) {
+			return linked(a)
+		}
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.Before(b.createdAt)
+		}
+		return a.id < b.id
+	})
+
+	// Issues are claimed across workspaces, so a workspace cannot take
+	// over an issue another one already syncs
+	var targets []jiraSyncTarget
+	claimed := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		taken := claimed[c.key]
+		claimed[c.key] = true
+		if workspaceID != "" && c.workspace != workspaceID {
+			continue
+		}
+		if taken {
+			targets = append(targets, jiraSyncTarget{
+				workspace: c.workspace,
+				link:      models.JiraLink{ItemType: c.itemType, ItemID: c.id, IssueKey: c.key},
+				skipped:   fmt.Sprintf("%s %s is not synced: another item is already linked to the issue", c.itemType, c.id),
+			})
+			continue
+		}
+
+		link, exists := s.jiraLinks[c.id]
+		if !exists || link.IssueKey != c.key {
+			link = &models.JiraLink{ItemType: c.itemType, ItemID: c.id, IssueKey: c.key, Synced: models.JiraValues{}}
+			s.jiraLinks[c.id] = link
+			s.dropJiraConflicts(c.id)
+		}
+		seen[c.id] = true
+
+		target := jiraSyncTarget{workspace: c.workspace, link: *link, local: c.local, conflicted: make(map[string]bool)}
+		target.link.Synced = make(models.JiraValues, len(link.Synced))
+		for field, value := range link.Synced {
+			target.link.Synced[field] = value
+		}
+		for _, conflict := range s.jiraConflicts {
+			if conflict.ItemID == c.id {
+				target.conflicted[conflict.Field] = true
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	// Items that were deleted, unlinked or lost their issue are forgotten.
+	// Items of other workspaces are left to their own syncs.
+	for id, link := range s.jiraLinks {
+		itemWorkspace := s.jiraWorkspace(link.ItemType, link.ItemID)
+		if !seen[id] && (workspaceID == "" || itemWorkspace == workspaceID || itemWorkspace == "") {
+			delete(s.jiraLinks, id)
+			s.dropJiraConflicts(id)
+		}
+	}
+	return targets
+}
+
+// jiraWorkspace returns the workspace of a linked item, or "" once the item
+// is gone. Callers must hold the lock.
+func (s *Service) jiraWorkspace(itemType models.ItemType, itemID string) string {
+	backlogID, _ := s.itemScope(itemType, itemID)
+	return s.workspaceOf(backlogID)
+}
+
+// planJiraSync compares each field with the value both sides last agreed on
+func planJiraSync(target jiraSyncTarget, remote models.JiraValues) jiraSyncPlan {
+	plan := jiraSyncPlan{agreed: models.JiraValues{}, push: models.JiraValues{}, pull: models.JiraValues{}}
+	for _, field := range models.JiraSyncFields {
+		if target.conflicted[field] {
+			continue
+		}
+		local, synced, theirs := target.local[field], target.link.Synced[field], remote[field]
+		switch {
+		case local == theirs:
+			plan.agreed[field] = local
+		case theirs == synced:
+			plan.push[field] = local
+		case local == synced:
+			plan.pull[field] = theirs
+		default:
+			plan.conflicts = append(plan.conflicts, field)
+		}
+	}
+	return plan
+}
+
+// pushJira writes local values to an issue and returns those that were
+// written before any error
+func (s *Service) pushJira(ctx context.Context, key string, values models.JiraValues) (models.JiraValues, error) {
+	pushed := models.JiraValues{}
+	if status, ok := values[models.JiraFieldStatus]; ok {
+		if err := s.transitionJira(ctx, key, models.Status(status)); err != nil {
+			return pushed, err
+		}
+		pushed[models.JiraFieldStatus] = status
+	}
+
+	fields := make(map[string]interface{})
+	if pic, ok := values[models.JiraFieldPIC]; ok {
+		fields["assignee"] = nil
+		if pic != "" {
+			users, err := s.jira.FindUsers(ctx, pic)
+			if err != nil {
+				return pushed, jiraError(err, "")
+			}
+			var user *jira.User
+			for i := range users {
+				if users[i].Matches(pic) {
+					user = &users[i]
+					break
+				}
+			}
+			if user == nil {
+				return pushed, fmt.Errorf("no Jira user matches %q", pic)
+			}
+			fields["assignee"] = user.Ref()
+		}
+	}
+	if date, ok := values[models.JiraFieldPlanStart]; ok {
+		fields[s.config.JiraStartDateField] = jiraFieldValue(date)
+	}
+	if date, ok := values[models.JiraFieldPlanEnd]; ok {
+		fields["duedate"] = jiraFieldValue(date)
+	}
+	if len(fields) == 0 {
+		return pushed, nil
+	}
+	if err := s.jira.UpdateFields(ctx, key, fields); err != nil {
+		return pushed, jiraError(err, "")
+	}
+	for _, field := range []string{models.JiraFieldPIC, models.JiraFieldPlanStart, models.JiraFieldPlanEnd} {
+		if value, ok := values[field]; ok {
+			pushed[field] = value
+		}
+	}
+	return pushed, nil
+}
+
+// transitionJira moves an issue to a Jira status that maps to status
+func (s *Service) transitionJira(ctx context.Context, key string, status models.Status) error {
+	transitions, err := s.jira.Transitions(ctx, key)
+	if err != nil {
+		return jiraError(err, "")
+	}
+	for _, transition := range transitions {
+		if s.jiraStatus(&transition.To) == status {
+			return jiraError(s.jira.Transition(ctx, key, transition.ID), "")
+		}
+	}
+	return fmt.Errorf("no transition of %s leads to a status mapped to %s", key, status)
+}
+
+// applyJiraSync records the outcome of syncing one item: pulled values are
+// written, agreed and pushed values become the synced values and new
+// conflicts are stored
+func (s *Service) applyJiraSync(report *models.JiraSyncReport, target jiraSyncTarget, remote models.JiraValues, plan jiraSyncPlan, pushed models.JiraValues, syncErr error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	link, exists := s.jiraLinks[target.link.ItemID]
+	if !exists || link.IssueKey != target.link.IssueKey {
+		return
+	}
+	if syncErr != nil {
+		link.LastError = syncErr.Error()
+		report.Errors = append(report.Errors, models.JiraSyncError{IssueKey: link.IssueKey, Error: syncErr.Error()})
+	} else {
+		link.LastError = ""
+	}
+	if remote == nil {
+		return
+	}
+
+	local := s.jiraLocalValues(link)
+	change := func(field, direction, from, to string) {
+		report.Changes = append(report.Changes, models.JiraChange{
+			ItemType:  link.ItemType,
+			ItemID:    link.ItemID,
+			IssueKey:  link.IssueKey,
+			Field:     field,
+			Direction: direction,
+			From:      from,
+			To:        to,
+		})
+	}
+
+	now := time.Now()
+	for _, field := range models.JiraSyncFields {
+		if value, ok := plan.agreed[field]; ok {
+			link.Synced[field] = value
+		}
+		if value, ok := pushed[field]; ok {
+			link.Synced[field] = value
+			change(field, models.JiraPush, remote[field], value)
+		}
+		// An item edited while Jira was being called is left for the next
+		// sync to compare again
+		if value, ok := plan.pull[field]; ok && local[field] == target.local[field] {
+			s.setJiraValue(link, field, value)
+			link.Synced[field] = value
+			change(field, models.JiraPull, local[field], value)
+		}
+	}
+	for _, field := range plan.conflicts {
+		conflict := &models.JiraConflict{
+			ID:         uuid.New().String(),
+			ItemType:   link.ItemType,
+			ItemID:     link.ItemID,
+			IssueKey:   link.IssueKey,
+			Field:      field,
+			Synced:     target.link.Synced[field],
+			Local:      target.local[field],
+			Remote:     remote[field],
+			DetectedAt: now,
+		}
+		s.jiraConflicts[conflict.ID] = conflict
+		s.publish(models.EventJiraConflictCreated, nil, s.snapshot(conflict))
+		report.Conflicts = append(report.Conflicts, *conflict)
+	}
+	link.SyncedAt = &now
+}
+
+// setJiraValue writes a value pulled from Jira. The status changed in Jira
+// already, so workflow rules are not checked again. Callers must hold the
+// lock.
+func (s *Service) setJiraValue(link *models.JiraLink, field, value string) {
+	switch link.ItemType {
+	case models.ItemStory:
+		story, exists := s.stories[link.ItemID]
+		if !exists {
+			return
+		}
+		if field == models.JiraFieldStatus {
+			s.setStoryStatus(story, models.Status(value))
+			return
+		}
+		before := s.snapshot(s.storyView(story))
+		switch field {
+		case models.JiraFieldPIC:
+			story.PIC = value
+		case models.JiraFieldPlanStart:
+			story.PlanStart = parseJiraDate(value)
+		case models.JiraFieldPlanEnd:
+			story.PlanEnd = parseJiraDate(value)
+		}
+		story.UpdatedAt = time.Now()
+		s.publish(models.EventStoryUpdated, before, s.snapshot(s.storyView(story)))
+	case models.ItemSubTask:
+		subtask, exists := s.subtasks[link.ItemID]
+		if !exists {
+			return
+		}
+		if field == models.JiraFieldStatus {
+			s.setSubTaskStatus(subtask, models.Status(value))
+			return
+		}
+		before := s.snapshot(s.subTaskView(subtask))
+		switch field {
+		case models.JiraFieldPIC:
+			subtask.PIC = value
+		case models.JiraFieldPlanStart:
+			subtask.PlanStart = parseJiraDate(value)
+		case models.JiraFieldPlanEnd:
+			subtask.PlanEnd = parseJiraDate(value)
+		}
+		subtask.UpdatedAt = time.Now()
+		s.publish(models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(subtask)))
+	}
+}
+
+// linkedJiraValues returns the synchronised values of the linked items of
+// a backlog by issue key
+func (s *Service) linkedJiraValues(backlogKey string) map[string]models.JiraValues {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make(map[string]models.JiraValues)
+	backlog := s.backlogByExternalKey(backlogKey)
+	if backlog == nil {
+		return values
+	}
+	for _, link := range s.jiraLinks {
+		current := s.jiraLocalValues(link)
+		if current == nil {
+			continue
+		}
+		if link.ItemType == models.ItemStory && s.stories[link.ItemID].BacklogID == backlog.ID {
+			values[link.IssueKey] = current
+		}
+		if link.ItemType == models.ItemSubTask && s.backlogOfSubTask(s.subtasks[link.ItemID]) == backlog.ID {
+			values[link.IssueKey] = current
+		}
+	}
+	return values
+}
+
+// jiraLocalValues returns the synchronised values of a linked item, or nil
+// when it no longer exists. Callers must hold the lock.
+func (s *Service) jiraLocalValues(link *models.JiraLink) models.JiraValues {
+	if link.ItemType == models.ItemStory {
+		if story, exists := s.stories[link.ItemID]; exists {
+			return storyJiraValues(story)
+		}
+		return nil
+	}
+	if subtask, exists := s.subtasks[link.ItemID]; exists {
+		return subTaskJiraValues(subtask)
+	}
+	return nil
+}
+
+// dropJiraConflicts removes the conflicts of an item. Callers must hold
+// the lock.
+func (s *Service) dropJiraConflicts(itemID string) {
+	for id, conflict := range s.jiraConflicts {
+		if conflict.ItemID == itemID {
+			delete(s.jiraConflicts, id)
+		}
+	}
+}
+
+// jiraIssueFields lists the issue fields read from Jira
+func (s *Service) jiraIssueFields() []string {
+	return []string{
+		"summary", "description", "status", "assignee", "priority", "issuetype",
+		"parent", "labels", "duedate", "timeoriginalestimate", "updated",
+		s.config.JiraStartDateField,
+	}
+}
+
+// jiraRemoteValues returns the synchronised values of an issue
+func (s *Service) jiraRemoteValues(issue *jira.Issue) models.JiraValues {
+	pic := ""
+	if assignee := issue.Fields.Assignee; assignee != nil {
+		pic = assignee.DisplayName
+		if pic == "" {
+			pic = assignee.Name
+		}
+	}
+	return models.JiraValues{
+		models.JiraFieldStatus:    string(s.jiraStatus(issue.Fields.Status)),
+		models.JiraFieldPIC:       pic,
+		models.JiraFieldPlanStart: jiraDay(issue.Fields.String(s.config.JiraStartDateField)),
+		models.JiraFieldPlanEnd:   jiraDay(issue.Fields.DueDate),
+	}
+}
+
+// jiraStatus maps a Jira status onto a local status, by name through the
+// configured status map and otherwise by its status category
+func (s *Service) jiraStatus(status *jira.Status) models.Status {
+	if status == nil {
+		return models.StatusTodo
+	}
+	for _, entry := range s.config.JiraStatusMap {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), status.Name) {
+			continue
+		}
+		if mapped := models.Status(strings.ToUpper(strings.TrimSpace(value))); mapped.IsValid() {
+			return mapped
+		}
+	}
+	switch status.StatusCategory.Key {
+	case jira.CategoryInProgress:
+		return models.StatusInProgress
+	case jira.CategoryDone:
+		return models.StatusDone
+	}
+	return models.StatusTodo
+}
+
+// jiraPriority maps the default Jira priority schemes onto local priorities
+func jiraPriority(priority *jira.Priority) models.Priority {
+	if priority == nil {
+		return ""
+	}
+	switch strings.ToLower(priority.Name) {
+	case "highest", "blocker", "critical":
+		return models.PriorityCritical
+	case "high", "major":
+		return models.PriorityHigh
+	case "low", "lowest", "minor", "trivial":
+		return models.PriorityLow
+	}
+	return models.PriorityMedium
+}
+
+// jiraError wraps an error of the Jira client. A 404 becomes notFound when
+// one is given, so handlers answer 404 rather than 502.
+func jiraError(err error, notFound string) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *jira.Error
+	if notFound != "" && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return errors.New(notFound)
+	}
+	return fmt.Errorf("%w: %v", ErrUpstream, err)
+}
+
+func storyJiraValues(story *models.Story) models.JiraValues {
+	return models.JiraValues{
+		models.JiraFieldStatus:    string(story.Status),
+		models.JiraFieldPIC:       story.PIC,
+		models.JiraFieldPlanStart: jiraDate(story.PlanStart),
+		models.JiraFieldPlanEnd:   jiraDate(story.PlanEnd),
+	}
+}
+
+func subTaskJiraValues(subtask *models.SubTask) models.JiraValues {
+	return models.JiraValues{
+		models.JiraFieldStatus:    string(subtask.Status),
+		models.JiraFieldPIC:       subtask.PIC,
+		models.JiraFieldPlanStart: jiraDate(subtask.PlanStart),
+		models.JiraFieldPlanEnd:   jiraDate(subtask.PlanEnd),
+	}
+}
+
+// jiraDate renders a plan date the way Jira stores date fields
+func jiraDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(models.DateLayout)
+}
+
+// jiraDay keeps the date part of a Jira date or date-time value
+func jiraDay(value string) string {
+	if len(value) > len(models.DateLayout) {
+		return value[:len(models.DateLayout)]
+	}
+	return value
+}
+
+func parseJiraDate(value string) time.Time {
+	t, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// jiraFieldValue clears a Jira field for an empty value
+func jiraFieldValue(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
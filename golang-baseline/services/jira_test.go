@@ -0,0 +1,591 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"golang-baseline/config"
+	"golang-baseline/jira"
+	"golang-baseline/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubIssue is an issue of the stub Jira site
+type stubIssue struct {
+	key, summary, parent string
+	subtask              bool
+	status               string
+	assignee             string
+	start, due           string
+}
+
+// stubJira serves the parts of the Jira REST API the service uses for one
+// project, PRJ
+type stubJira struct {
+	mutex  sync.Mutex
+	issues map[string]*stubIssue
+	users  []jira.User
+}
+
+// stubStatuses are the workflow statuses of the stub site by name, with
+// their status category
+var stubStatuses = map[string]string{
+	"To Do":       jira.CategoryToDo,
+	"Blocked":     jira.CategoryInProgress,
+	"In Progress": jira.CategoryInProgress,
+	"Done":        jira.CategoryDone,
+}
+
+func newStubJira(t *testing.T, issues ...*stubIssue) (*stubJira, *httptest.Server) {
+	stub := &stubJira{
+		issues: make(map[string]*stubIssue),
+		users:  []jira.User{{Name: "ada", DisplayName: "Ada Lovelace"}, {Name: "alan", DisplayName: "Alan Turing"}},
+	}
+	for _, issue := range issues {
+		stub.issues[issue.key] = issue
+	}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (j *stubJira) issue(key string) stubIssue {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return *j.issues[key]
+}
+
+func (j *stubJira) update(key string, change func(*stubIssue)) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	change(j.issues[key])
+}
+
+func (j *stubJira) encode(issue *stubIssue) map[string]interface{} {
+	fields := map[string]interface{}{
+		"summary":           issue.summary,
+		"issuetype":         map[string]interface{}{"name": "Story", "subtask": issue.subtask},
+		"status":            map[string]interface{}{"name": issue.status, "statusCategory": map[string]string{"key": stubStatuses[issue.status]}},
+		"duedate":           nil,
+		"customfield_10015": nil,
+	}
+	if issue.parent != "" {
+		fields["parent"] = map[string]string{"key": issue.parent}
+	}
+	if issue.due != "" {
+		fields["duedate"] = issue.due
+	}
+	if issue.start != "" {
+		fields["customfield_10015"] = issue.start
+	}
+	for _, user := range j.users {
+		if user.Name == issue.assignee {
+			fields["assignee"] = user
+		}
+	}
+	return map[string]interface{}{"key": issue.key, "fields": fields}
+}
+
+func (j *stubJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest/api/2")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errorMessages": []string{"not found"}})
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "project":
+		if parts[1] != "PRJ" {
+			notFound()
+			return
+		}
+		json.NewEncoder(w).Encode(jira.Project{Key: "PRJ", Name: "Project"})
+	case len(parts) == 1 && parts[0] == "search":
+		var issues []map[string]interface{}
+		for _, issue := range j.issues {
+			issues = append(issues, j.encode(issue))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"total": len(issues), "issues": issues})
+	case len(parts) == 2 && parts[0] == "issue":
+		issue, exists := j.issues[parts[1]]
+		if !exists {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body struct {
+				Fields map[string]json.RawMessage `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			for name, raw := range body.Fields {
+				switch name {
+				case "assignee":
+					var ref map[string]string
+					json.Unmarshal(raw, &ref)
+					issue.assignee = ref["name"]
+				case "duedate":
+					issue.due = ""
+					json.Unmarshal(raw, &issue.due)
+				case "customfield_10015":
+					issue.start = ""
+					json.Unmarshal(raw, &issue.start)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(j.encode(issue))
+	case len(parts) == 3 && parts[0] == "issue" && parts[2] == "transitions":
+		issue, exists := j.issues[parts[1]]
+		if !exists {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodPost {
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			issue.status = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var transitions []map[string]interface{}
+		for name, category := range stubStatuses {
+			if name != issue.status {
+				transitions = append(transitions, map[string]interface{}{
+					"id": name, "name": name,
+					"to": map[string]interface{}{"name": name, "statusCategory": map[string]string{"key": category}},
+				})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"transitions": transitions})
+	case len(parts) == 2 && parts[0] == "user" && parts[1] == "search":
+		var users []jira.User
+		for _, user := range j.users {
+			if user.Matches(r.URL.Query().Get("query")) {
+				users = append(users, user)
+			}
+		}
+		json.NewEncoder(w).Encode(users)
+	default:
+		notFound()
+	}
+}
+
+func newJiraTestService(t *testing.T, server *httptest.Server) *Service {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.JiraBaseURL = server.URL
+		cfg.JiraStatusMap = []string{"Blocked=TODO"}
+	})
+}
+
+func projectIssues() []*stubIssue {
+	return []*stubIssue{
+		{key: "PRJ-1", summary: "Login", status: "Blocked", assignee: "ada", start: "2024-03-04", due: "2024-03-08"},
+		{key: "PRJ-2", summary: "Logout", status: "In Progress"},
+		{key: "PRJ-3", summary: "Login form", status: "Done", subtask: true, parent: "PRJ-1"},
+		{key: "OTHER-1", summary: "Orphan", status: "To Do", subtask: true, parent: "OTHER-9"},
+	}
+}
+
+// importedIDs returns the item IDs of an import by issue key
+func importedIDs(result *models.JiraImportResult) map[string]string {
+	ids := make(map[string]string)
+	for _, mapping := range result.Mapping {
+		ids[mapping.ExternalKey] = mapping.ID
+	}
+	return ids
+}
+
+func TestImportJiraProject(t *testing.T) {
+	_, server := newStubJira(t, projectIssues()...)
+	s := newJiraTestService(t, server)
+	ctx := context.Background()
+
+	result, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 4 || len(result.Skipped) != 1 || result.Skipped[0].Path != "OTHER-1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	ids := importedIDs(result)
+
+	login, err := s.GetStory(ids["PRJ-1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The status map takes precedence over the status category
+	if login.Status != models.StatusTodo {
+		t.Errorf("PRJ-1 status = %s, want TODO", login.Status)
+	}
+	if login.PIC != "Ada Lovelace" || login.JiraURL != server.URL+"/browse/PRJ-1" {
+		t.Errorf("PRJ-1 = %+v", login)
+	}
+	if got := login.PlanStart.Format(models.DateLayout); got != "2024-03-04" {
+		t.Errorf("PRJ-1 plan start = %s", got)
+	}
+	logout, _ := s.GetStory(ids["PRJ-2"])
+	if logout.Status != models.StatusInProgress {
+		t.Errorf("PRJ-2 status = %s, want IN_PROGRESS", logout.Status)
+	}
+	form, err := s.GetSubTask(ids["PRJ-3"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.StoryID != login.ID || form.Status != models.StatusDone {
+		t.Errorf("PRJ-3 = %+v", form)
+	}
+
+	links, _ := s.GetJiraLinks()
+	if len(links) != 3 {
+		t.Errorf("got %d links, want 3", len(links))
+	}
+
+	// Importing again updates the same backlog
+	again, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.BacklogID != result.BacklogID || again.Created != 0 {
+		t.Errorf("reimport = %+v", again)
+	}
+
+	if _, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "NOPE"}); err == nil ||
+		!strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestJiraStatusMapping(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.JiraStatusMap = []string{"In Review=DONE", "Parked = todo", "Broken=NOPE"}
+	})
+	status := func(name, category string) *jira.Status {
+		status := &jira.Status{Name: name}
+		status.StatusCategory.Key = category
+		return status
+	}
+	tests := []struct {
+		status *jira.Status
+		want   models.Status
+	}{
+		{nil, models.StatusTodo},
+		{status("Open", jira.CategoryToDo), models.StatusTodo},
+		{status("Doing", jira.CategoryInProgress), models.StatusInProgress},
+		{status("Closed", jira.CategoryDone), models.StatusDone},
+		{status("in review", jira.CategoryInProgress), models.StatusDone},
+		{status("Parked", jira.CategoryInProgress), models.StatusTodo},
+		{status("Broken", jira.CategoryDone), models.StatusDone},
+	}
+	for _, tt := range tests {
+		if got := s.jiraStatus(tt.status); got != tt.want {
+			t.Errorf("jiraStatus(%+v) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPlanJiraSync(t *testing.T) {
+	target := jiraSyncTarget{
+		local: models.JiraValues{
+			models.JiraFieldStatus: "DONE", models.JiraFieldPIC: "ada",
+			models.JiraFieldPlanStart: "2024-03-05", models.JiraFieldPlanEnd: "2024-03-09",
+		},
+		link: models.JiraLink{Synced: models.JiraValues{
+			models.JiraFieldStatus: "TODO", models.JiraFieldPIC: "ada",
+			models.JiraFieldPlanStart: "2024-03-04", models.JiraFieldPlanEnd: "2024-03-08",
+		}},
+		conflicted: map[string]bool{},
+	}
+	remote := models.JiraValues{
+		models.JiraFieldStatus: "TODO", models.JiraFieldPIC: "alan",
+		models.JiraFieldPlanStart: "2024-03-06", models.JiraFieldPlanEnd: "2024-03-09",
+	}
+
+	plan := planJiraSync(target, remote)
+	if plan.push[models.JiraFieldStatus] != "DONE" || len(plan.push) != 1 {
+		t.Errorf("push = %v", plan.push)
+	}
+	if plan.pull[models.JiraFieldPIC] != "alan" || len(plan.pull) != 1 {
+		t.Errorf("pull = %v", plan.pull)
+	}
+	if len(plan.conflicts) != 1 || plan.conflicts[0] != models.JiraFieldPlanStart {
+		t.Errorf("conflicts = %v", plan.conflicts)
+	}
+	if plan.agreed[models.JiraFieldPlanEnd] != "2024-03-09" || len(plan.agreed) != 1 {
+		t.Errorf("agreed = %v", plan.agreed)
+	}
+
+	// Fields with an open conflict are left alone
+	target.conflicted[models.JiraFieldPlanStart] = true
+	if plan := planJiraSync(target, remote); len(plan.conflicts) != 0 {
+		t.Errorf("conflicted field planned again: %v", plan.conflicts)
+	}
+}
+
+func TestSyncJira(t *testing.T) {
+	stub, server := newStubJira(t, projectIssues()...)
+	s := newJiraTestService(t, server)
+	ctx := context.Background()
+
+	result, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := importedIDs(result)
+
+	// Nothing changed since the import
+	report, err := s.SyncJira(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 3 || len(report.Changes) != 0 || len(report.Conflicts) != 0 || len(report.Errors) != 0 {
+		t.Fatalf("first sync = %+v", report)
+	}
+
+	// Pushed: the status and due date of PRJ-2 changed locally
+	if _, err := s.UpdateStoryStatus(ids["PRJ-2"], models.StatusDone); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	pic := "Alan Turing"
+	if _, err := s.UpdateStory(ids["PRJ-2"], models.UpdateStoryRequest{PlanEnd: &due, PIC: &pic}); err != nil {
+		t.Fatal(err)
+	}
+	// Pulled: PRJ-1 was reassigned in Jira
+	stub.update("PRJ-1", func(issue *stubIssue) { issue.assignee = "alan" })
+	// Conflict: the start of PRJ-1 changed on both sides
+	start := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	if _, err := s.UpdateStory(ids["PRJ-1"], models.UpdateStoryRequest{PlanStart: &start}); err != nil {
+		t.Fatal(err)
+	}
+	stub.update("PRJ-1", func(issue *stubIssue) { issue.start = "2024-03-06" })
+
+	report, err = s.SyncJira(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("sync errors: %+v", report.Errors)
+	}
+	changes := make(map[string]models.JiraChange)
+	for _, change := range report.Changes {
+		changes[change.IssueKey+" "+change.Field] = change
+	}
+	if change := changes["PRJ-2 status"]; change.Direction != models.JiraPush || change.To != "DONE" {
+		t.Errorf("PRJ-2 status change = %+v", change)
+	}
+	if change := changes["PRJ-2 plan_end"]; change.Direction != models.JiraPush || change.To != "2024-04-01" {
+		t.Errorf("PRJ-2 plan_end change = %+v", change)
+	}
+	if change := changes["PRJ-1 pic"]; change.Direction != models.JiraPull || change.To != "Alan Turing" {
+		t.Errorf("PRJ-1 pic change = %+v", change)
+	}
+	if issue := stub.issue("PRJ-2"); issue.status != "Done" || issue.due != "2024-04-01" || issue.assignee != "alan" {
+		t.Errorf("PRJ-2 in Jira = %+v", issue)
+	}
+	if story, _ := s.GetStory(ids["PRJ-1"]); story.PIC != "Alan Turing" {
+		t.Errorf("PRJ-1 PIC = %q", story.PIC)
+	}
+
+	conflicts, _ := s.GetJiraConflicts()
+	if len(conflicts) != 1 || conflicts[0].IssueKey != "PRJ-1" || conflicts[0].Field != models.JiraFieldPlanStart ||
+		conflicts[0].Local != "2024-03-05" || conflicts[0].Remote != "2024-03-06" || conflicts[0].Synced != "2024-03-04" {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+
+	// A conflict stays until resolved, then the winner is copied over
+	report, _ = s.SyncJira(ctx)
+	if len(report.Changes) != 0 || len(report.Conflicts) != 0 {
+		t.Errorf("unresolved conflict synced: %+v", report)
+	}
+	if _, err := s.ResolveJiraConflict(conflicts[0].ID, models.ResolveJiraConflictRequest{Keep: models.JiraKeepLocal}); err != nil {
+		t.Fatal(err)
+	}
+	report, _ = s.SyncJira(ctx)
+	if len(report.Changes) != 1 || report.Changes[0].Direction != models.JiraPush || report.Changes[0].To != "2024-03-05" {
+		t.Errorf("resolved sync = %+v", report.Changes)
+	}
+	if issue := stub.issue("PRJ-1"); issue.start != "2024-03-05" {
+		t.Errorf("PRJ-1 start in Jira = %q", issue.start)
+	}
+	if conflicts, _ := s.GetJiraConflicts(); len(conflicts) != 0 {
+		t.Errorf("conflicts left: %+v", conflicts)
+	}
+}
+
+func TestSyncJiraReportsErrors(t *testing.T) {
+	stub, server := newStubJira(t, projectIssues()...)
+	s := newJiraTestService(t, server)
+	ctx := context.Background()
+
+	result, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := importedIDs(result)
+
+	// An assignee without a Jira account cannot be pushed
+	pic := "Grace Hopper"
+	if _, err := s.UpdateStory(ids["PRJ-2"], models.UpdateStoryRequest{PIC: &pic}); err != nil {
+		t.Fatal(err)
+	}
+	// An issue deleted in Jira cannot be read
+	stub.mutex.Lock()
+	delete(stub.issues, "PRJ-3")
+	stub.mutex.Unlock()
+
+	report, err := s.SyncJira(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := make(map[string]bool)
+	for _, syncErr := range report.Errors {
+		failed[syncErr.IssueKey] = true
+	}
+	if len(report.Errors) != 2 || !failed["PRJ-2"] || !failed["PRJ-3"] {
+		t.Errorf("errors = %+v", report.Errors)
+	}
+	links, _ := s.GetJiraLinks()
+	for _, link := range links {
+		if failed[link.IssueKey] && link.LastError == "" {
+			t.Errorf("link %s has no error", link.IssueKey)
+		}
+	}
+}
+
+func TestJiraSyncIsScopedToWorkspaces(t *testing.T) {
+	stub, server := newStubJira(t, projectIssues()...)
+	s := newJiraTestService(t, server)
+	ctx := context.Background()
+
+	result, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := importedIDs(result)
+
+	other := s.WithRequest(models.RequestInfo{Workspace: "other"})
+	backlog, err := other.CreateBacklog(models.CreateBacklogRequest{Title: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.CreateStory(models.CreateStoryRequest{BacklogID: backlog.ID, Title: "Orphan", JiraURL: server.URL + "/browse/OTHER-1"}); err != nil {
+		t.Fatal(err)
+	}
+	admin, _ := asUser(t, other, "olga", true)
+
+	// A workspace admin only syncs the items of the workspace
+	report, err := admin.SyncJira(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 1 {
+		t.Errorf("workspace sync checked %d items", report.Checked)
+	}
+	if _, err := s.SyncJira(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := admin.GetJiraLinks(); len(links) != 1 || links[0].IssueKey != "OTHER-1" {
+		t.Errorf("workspace links = %+v", links)
+	}
+	if links, _ := s.GetJiraLinks(); len(links) != 3 {
+		t.Errorf("default links = %+v", links)
+	}
+
+	// Conflicts and reports of other workspaces are not shown
+	start := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	if _, err := s.UpdateStory(ids["PRJ-1"], models.UpdateStoryRequest{PlanStart: &start}); err != nil {
+		t.Fatal(err)
+	}
+	stub.update("PRJ-1", func(issue *stubIssue) { issue.start = "2024-03-06" })
+	if _, err := s.SyncJira(ctx); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, _ := s.GetJiraConflicts()
+	if len(conflicts) != 1 || conflicts[0].ItemID != ids["PRJ-1"] {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+	if conflicts, _ := admin.GetJiraConflicts(); len(conflicts) != 0 {
+		t.Errorf("workspace conflicts = %+v", conflicts)
+	}
+	if _, err := admin.ResolveJiraConflict(conflicts[0].ID, models.ResolveJiraConflictRequest{Keep: models.JiraKeepLocal}); err == nil {
+		t.Error("conflict of another workspace resolved")
+	}
+	if report, _ := admin.GetJiraSyncReport(); report == nil || report.Checked != 1 || len(report.Conflicts) != 0 {
+		t.Errorf("workspace report = %+v", report)
+	}
+	if report, _ := s.GetJiraSyncReport(); report == nil || report.Checked != 3 || len(report.Conflicts) != 1 {
+		t.Errorf("default report = %+v", report)
+	}
+}
+
+func TestJiraIssueIsSyncedByOneItem(t *testing.T) {
+	stub, server := newStubJira(t, projectIssues()...)
+	s := newJiraTestService(t, server)
+	ctx := context.Background()
+
+	result, err := s.ImportJiraProject(ctx, models.JiraImportRequest{ProjectKey: "PRJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := importedIDs(result)
+	if _, err := s.SyncJira(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clones are not linked to the issue of their source
+	clone, err := s.CloneStory(ids["PRJ-1"], models.CloneStoryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.JiraURL != "" {
+		t.Errorf("clone linked to %q", clone.JiraURL)
+	}
+	for _, subtask := range clone.SubTasks {
+		if subtask.JiraURL != "" {
+			t.Errorf("subtask clone linked to %q", subtask.JiraURL)
+		}
+	}
+
+	// A second item pointing at an issue is reported and left alone
+	story, _ := s.GetStory(ids["PRJ-2"])
+	duplicate, err := s.CreateStory(models.CreateStoryRequest{BacklogID: story.BacklogID, Title: "Logout again", JiraURL: story.JiraURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateStoryStatus(duplicate.ID, models.StatusDone); err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.SyncJira(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 4 || len(report.Changes) != 0 || len(report.Errors) != 1 || report.Errors[0].IssueKey != "PRJ-2" ||
+		!strings.Contains(report.Errors[0].Error, duplicate.ID) {
+		t.Errorf("report = %+v", report)
+	}
+	if issue := stub.issue("PRJ-2"); issue.status != "In Progress" {
+		t.Errorf("PRJ-2 in Jira = %+v", issue)
+	}
+	links, _ := s.GetJiraLinks()
+	for _, link := range links {
+		if link.ItemID == duplicate.ID {
+			t.Errorf("duplicate linked: %+v", link)
+		}
+	}
+	if len(links) != 3 {
+		t.Errorf("links = %+v", links)
+	}
+}
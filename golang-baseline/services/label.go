@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultLabelColor is used for labels created without a color
+const defaultLabelColor = "#808080"
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Label operations
+func (s *Service) CreateLabel(backlogID string, req models.CreateLabelRequest) (*models.Label, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermManage); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if s.labelByName(backlogID, name) != nil {
+		return nil, fmt.Errorf("%w: label %q already exists", ErrInvalidRequest, name)
+	}
+	color := req.Color
+	if color == "" {
+		color = defaultLabelColor
+	}
+	if !labelColorPattern.MatchString(color) {
+		return nil, fmt.Errorf("%w: color must be a #RRGGBB value", ErrInvalidRequest)
+	}
+
+	label := &models.Label{
+		ID:        uuid.New().String(),
+		BacklogID: backlogID,
+		Name:      name,
+		Color:     color,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	s.labels[label.ID] = label
+	s.publish(models.EventLabelCreated, nil, s.snapshot(label))
+	return label, nil
+}
+
+func (s *Service) GetLabelsByBacklog(backlogID string) ([]*models.Label, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	labels := []*models.Label{}
+	for _, label := range s.labels {
+		if label.BacklogID == backlogID {
+			labels = append(labels, label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	return labels, nil
+}
+
+func (s *Service) UpdateLabel(id string, req models.UpdateLabelRequest) (*models.Label, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	label, exists := s.labels[id]
+	if !exists {
+		return nil, errors.New("label not found")
+	}
+	if err := s.authorize(label.BacklogID, models.PermManage); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidRequest)
+		}
+		if other := s.labelByName(label.BacklogID, name); other != nil && other.ID != id {
+			return nil, fmt.Errorf("%w: label %q already exists", ErrInvalidRequest, name)
+		}
+	}
+	if req.Color != nil && !labelColorPattern.MatchString(*req.Color) {
+		return nil, fmt.Errorf("%w: color must be a #RRGGBB value", ErrInvalidRequest)
+	}
+
+	before := s.snapshot(label)
+	if req.Name != nil {
+		label.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Color != nil {
+		label.Color = *req.Color
+	}
+	label.UpdatedAt = time.Now()
+
+	s.publish(models.EventLabelUpdated, before, s.snapshot(label))
+	return label, nil
+}
+
+// DeleteLabel deletes a label and removes it from every item carrying it
+func (s *Service) DeleteLabel(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	label, exists := s.labels[id]
+	if !exists {
+		return errors.New("label not found")
+	}
+	if err := s.authorize(label.BacklogID, models.PermManage); err != nil {
+		return err
+	}
+
+	for _, story := range s.stories {
+		if labels, removed := removeMember(story.Labels, id); removed {
+			before := s.snapshot(s.storyView(story))
+			story.Labels = labels
+			s.publish(models.EventStoryUpdated, before, s.snapshot(s.storyView(story)))
+		}
+	}
+	for _, subtask := range s.subtasks {
+		if labels, removed := removeMember(subtask.Labels, id); removed {
+			before := s.snapshot(s.subTaskView(subtask))
+			subtask.Labels = labels
+			s.publish(models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(subtask)))
+		}
+	}
+
+	deleted := s.snapshot(label)
+	delete(s.labels, id)
+	s.publish(models.EventLabelDeleted, deleted, nil)
+	return nil
+}
+
+// BulkRelabel adds and removes labels on many stories or subtasks at once.
+// Every item and label is validated before any item is changed.
+func (s *Service) BulkRelabel(req models.BulkLabelRequest) (*models.BulkLabelResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("%w: ids is required", ErrInvalidRequest)
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return nil, fmt.Errorf("%w: add or remove is required", ErrInvalidRequest)
+	}
+
+	type change struct {
+		labels    *[]string
+		updatedAt *time.Time
+		add       []string
+		remove    []string
+	}
+	changes := make([]change, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		var c change
+		var backlogID string
+		switch req.ItemType {
+		case models.ItemStory:
+			story, exists := s.stories[id]
+			if !exists {
+				return nil, fmt.Errorf("story %s not found", id)
+			}
+			backlogID, c.labels, c.updatedAt = story.BacklogID, &story.Labels, &story.UpdatedAt
+		case models.ItemSubTask:
+			subtask, exists := s.subtasks[id]
+			if !exists {
+				return nil, fmt.Errorf("subtask %s not found", id)
+			}
+			backlogID, c.labels, c.updatedAt = s.backlogOfSubTask(subtask), &subtask.Labels, &subtask.UpdatedAt
+		default:
+			return nil, fmt.Errorf("%w: unknown item type %q", ErrInvalidRequest, req.ItemType)
+		}
+		if err := s.authorize(backlogID, models.PermEdit); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if c.add, err = s.resolveLabels(backlogID, req.Add); err != nil {
+			return nil, err
+		}
+		if c.remove, err = s.resolveLabels(backlogID, req.Remove); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	result := &models.BulkLabelResult{Updated: []string{}}
+	now := time.Now()
+	for i, c := range changes {
+		before := s.itemSnapshot(req.ItemType, req.IDs[i])
+		*c.labels = relabel(*c.labels, c.add, c.remove)
+		*c.updatedAt = now
+		s.publishItemUpdated(req.ItemType, req.IDs[i], before)
+		result.Updated = append(result.Updated, req.IDs[i])
+	}
+
+	return result, nil
+}
+
+// labelByName finds a label of a backlog by name, ignoring case. Callers
+// must hold the lock.
+func (s *Service) labelByName(backlogID, name string) *models.Label {
+	for _, label := range s.labels {
+		if label.BacklogID == backlogID && strings.EqualFold(label.Name, name) {
+			return label
+		}
+	}
+	return nil
+}
+
+// resolveLabels converts label IDs or names into the IDs of labels of the
+// given backlog. Callers must hold the lock.
+func (s *Service) resolveLabels(backlogID string, refs []string) ([]string, error) {
+	ids := []string{}
+	for _, ref := range refs {
+		if label, exists := s.labels[ref]; exists && label.BacklogID == backlogID {
+			ids = addMembers(ids, []string{label.ID})
+			continue
+		}
+		if label := s.labelByName(backlogID, ref); label != nil {
+			ids = addMembers(ids, []string{label.ID})
+			continue
+		}
+		return nil, fmt.Errorf("%w: label %q does not exist in this backlog", ErrInvalidRequest, ref)
+	}
+	return ids, nil
+}
+
+// backlogOfSubTask returns the ID of the backlog a subtask belongs to.
+// Callers must hold the lock.
+func (s *Service) backlogOfSubTask(subtask *models.SubTask) string {
+	if story, exists := s.stories[subtask.StoryID]; exists {
+		return story.BacklogID
+	}
+	return ""
+}
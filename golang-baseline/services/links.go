@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Link operations
+func (s *Service) CreateLink(req models.CreateLinkRequest) (*models.Link, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown link type %q", ErrInvalidLink, req.Type)
+	}
+	if req.SourceID == req.TargetID {
+		return nil, fmt.Errorf("%w: an item cannot be linked to itself", ErrInvalidLink)
+	}
+
+	switch req.ItemType {
+	case models.ItemStory:
+		if _, exists := s.stories[req.SourceID]; !exists {
+			return nil, errors.New("source story not found")
+		}
+		if _, exists := s.stories[req.TargetID]; !exists {
+			return nil, errors.New("target story not found")
+		}
+	case models.ItemSubTask:
+		if _, exists := s.subtasks[req.SourceID]; !exists {
+			return nil, errors.New("source subtask not found")
+		}
+		if _, exists := s.subtasks[req.TargetID]; !exists {
+			return nil, errors.New("target subtask not found")
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown item type %q", ErrInvalidLink, req.ItemType)
+	}
+	if err := s.authorizeItem(req.ItemType, req.SourceID, models.PermEdit); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeItem(req.ItemType, req.TargetID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	link := &models.Link{
+		ID:        uuid.New().String(),
+		ItemType:  req.ItemType,
+		SourceID:  req.SourceID,
+		TargetID:  req.TargetID,
+		Type:      req.Type,
+		CreatedAt: time.Now(),
+	}
+
+	for _, existing := range s.links {
+		if sameLink(existing, link) {
+			return nil, ErrDuplicateLink
+		}
+	}
+
+	// A new blocking edge creates a cycle if the blocked item already
+	// (transitively) blocks the blocker
+	if blocker, blocked, ok := link.Blocking(); ok && s.blocks(req.ItemType, blocked, blocker) {
+		return nil, ErrDependencyCycle
+	}
+
+	s.links[link.ID] = link
+	s.publish(models.EventLinkCreated, nil, s.snapshot(link))
+	return link, nil
+}
+
+// GetLinks returns every link in which the given item is the source or target
+func (s *Service) GetLinks(itemType models.ItemType, id string) ([]*models.Link, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	switch itemType {
+	case models.ItemStory:
+		if _, exists := s.stories[id]; !exists {
+			return nil, errors.New("story not found")
+		}
+	case models.ItemSubTask:
+		if _, exists := s.subtasks[id]; !exists {
+			return nil, errors.New("subtask not found")
+		}
+	}
+	if err := s.authorizeItem(itemType, id, models.PermView); err != nil {
+		return nil, err
+	}
+
+	links := []*models.Link{}
+	for _, link := range s.links {
+		if link.ItemType == itemType && (link.SourceID == id || link.TargetID == id) {
+			links = append(links, link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.Before(links[j].CreatedAt)
+	})
+
+	return links, nil
+}
+
+func (s *Service) DeleteLink(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	link, exists := s.links[id]
+	if !exists {
+		return errors.New("link not found")
+	}
+	if err := s.authorizeItem(link.ItemType, link.SourceID, models.PermEdit); err != nil {
+		return err
+	}
+
+	deleted := s.snapshot(link)
+	delete(s.links, id)
+	s.publish(models.EventLinkDeleted, deleted, nil)
+	return nil
+}
+
+// sameLink reports whether two links express the same relationship. "A
+// blocks B" and "B blocked_by A" are the same, as are both directions of
+// relates_to.
+func sameLink(a, b *models.Link) bool {
+	if a.ItemType != b.ItemType {
+		return false
+	}
+	aBlocker, aBlocked, aBlocking := a.Blocking()
+	bBlocker, bBlocked, bBlocking := b.Blocking()
+	if aBlocking || bBlocking {
+		return aBlocking && bBlocking && aBlocker == bBlocker && aBlocked == bBlocked
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	if a.SourceID == b.SourceID && a.TargetID == b.TargetID {
+		return true
+	}
+	return a.Type == models.LinkRelatesTo && a.SourceID == b.TargetID && a.TargetID == b.SourceID
+}
+
+// blocks reports whether from transitively blocks to. Callers must hold the lock.
+func (s *Service) blocks(itemType models.ItemType, from, to string) bool {
+	successors := make(map[string][]string)
+	for _, link := range s.links {
+		if link.ItemType != itemType {
+			continue
+		}
+		if blocker, blocked, ok := link.Blocking(); ok {
+			successors[blocker] = append(successors[blocker], blocked)
+		}
+	}
+
+	visited := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		for _, next := range successors[current] {
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
+
+// unfinishedBlockers returns the IDs of items that block the given item and
+// are not done yet. Callers must hold the lock.
+func (s *Service) unfinishedBlockers(itemType models.ItemType, id string) []string {
+	var blockers []string
+	for _, link := range s.links {
+		if link.ItemType != itemType {
+			continue
+		}
+		blocker, blocked, ok := link.Blocking()
+		if !ok || blocked != id {
+			continue
+		}
+
+		var status models.Status
+		switch itemType {
+		case models.ItemStory:
+			if story, exists := s.stories[blocker]; exists {
+				status = story.Status
+			}
+		case models.ItemSubTask:
+			if subtask, exists := s.subtasks[blocker]; exists {
+				status = subtask.Status
+			}
+		}
+		if status != "" && status != models.StatusDone {
+			blockers = append(blockers, blocker)
+		}
+	}
+	sort.Strings(blockers)
+	return blockers
+}
+
+// storyView returns a copy of a story with its derived fields populated.
+// Callers must hold the lock.
+func (s *Service) storyView(story *models.Story) models.Story {
+	view := *story
+	view.BlockedBy = s.unfinishedBlockers(models.ItemStory, story.ID)
+	view.Blocked = len(view.BlockedBy) > 0
+	view.CommentCount = s.commentCount(models.ItemStory, story.ID)
+	return view
+}
+
+// subTaskView returns a copy of a subtask with its derived fields populated.
+// Callers must hold the lock.
+func (s *Service) subTaskView(subtask *models.SubTask) models.SubTask {
+	view := *subtask
+	view.BlockedBy = s.unfinishedBlockers(models.ItemSubTask, subtask.ID)
+	view.Blocked = len(view.BlockedBy) > 0
+	view.CommentCount = s.commentCount(models.ItemSubTask, subtask.ID)
+	return view
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"golang-baseline/auth"
+	"golang-baseline/models"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// oidcLoginTTL is how long a user has to sign in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// usernameInvalidChars matches what usernames may not contain, to derive
+// usernames from the claims of the provider
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// oidcLogin is a login waiting for the provider to call back
+type oidcLogin struct {
+	nonce        string
+	codeVerifier string
+	redirect     string
+	expiresAt    time.Time
+}
+
+// Single sign-on operations
+
+// SecureCookies reports whether session cookies are only sent over HTTPS
+func (s *Service) SecureCookies() bool {
+	return !s.config.SessionCookieInsecure
+}
+
+// BeginOIDCLogin starts a login at the provider. The user returns to the
+// local path redirect once signed in.
+func (s *Service) BeginOIDCLogin(ctx context.Context, redirect string) (*models.OIDCLogin, error) {
+	if s.oidc == nil {
+		return nil, fmt.Errorf("%w: single sign-on is not configured", ErrInvalidRequest)
+	}
+	if redirect == "" {
+		redirect = "/"
+	}
+	// Only local paths are accepted, so logins cannot send users elsewhere
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return nil, fmt.Errorf("%w: redirect must be a local path", ErrInvalidRequest)
+	}
+
+	state, err := auth.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := auth.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier := auth.NewPKCEVerifier()
+	url, err := s.oidc.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for key, login := range s.logins {
+		if now.After(login.expiresAt) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = &oidcLogin{
+		nonce:        nonce,
+		codeVerifier: codeVerifier,
+		redirect:     redirect,
+		expiresAt:    now.Add(oidcLoginTTL),
+	}
+	return &models.OIDCLogin{URL: url, State: state}, nil
+}
+
+// CompleteOIDCLogin finishes a login with the authorization code the
+// provider called back with. Users signing in for the first time are
+// provisioned; the roles of every user follow their groups when group
+// roles are configured. A login can only be completed once.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, state, code string) (*models.SessionLogin, error) {
+	if s.oidc == nil {
+		return nil, fmt.Errorf("%w: single sign-on is not configured", ErrInvalidRequest)
+	}
+
+	s.mutex.Lock()
+	login, exists := s.logins[state]
+	delete(s.logins, state)
+	s.mutex.Unlock()
+	if !exists || time.Now().After(login.expiresAt) {
+		return nil, fmt.Errorf("%w: the login expired or was already completed", ErrUnauthorized)
+	}
+
+	// Exchanging the code calls the provider, so it is done without the lock
+	identity, err := s.oidc.Exchange(ctx, code, login.codeVerifier, login.nonce)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	sessionID, err := auth.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, err := s.provisionUser(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for key, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+	session := &models.Session{
+		Hash:      auth.HashSessionID(sessionID),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(s.config.SessionTTL) * time.Minute),
+	}
+	s.sessions[session.Hash] = session
+
+	return &models.SessionLogin{
+		SessionID: sessionID,
+		ExpiresAt: session.ExpiresAt,
+		Redirect:  login.redirect,
+		User:      userView(user),
+	}, nil
+}
+
+// AuthenticateSession resolves the ID of a browser session into the user
+// it belongs to
+func (s *Service) AuthenticateSession(sessionID string) (*models.Principal, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, exists := s.sessions[auth.HashSessionID(sessionID)]
+	if !exists || time.Now().After(session.ExpiresAt) {
+		return nil, fmt.Errorf("%w: the session expired", ErrUnauthorized)
+	}
+	user, exists := s.users[session.UserID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, auth.ErrInvalidCredentials)
+	}
+	return &models.Principal{UserID: user.ID, Username: user.Username, Method: models.AuthSession,
+		WorkspaceID: user.WorkspaceID}, nil
+}
+
+// Logout ends a browser session. Unknown sessions are ignored.
+func (s *Service) Logout(sessionID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, auth.HashSessionID(sessionID))
+}
+
+// provisionUser finds the user of a provider account, creating it on its
+// first login, and brings its profile and roles up to date. Changes are
+// made to a copy that replaces the stored user. Callers must hold the
+// lock.
+func (s *Service) provisionUser(identity *auth.Identity) (*models.User, error) {
+	subject := identity.Issuer + " " + identity.Subject
+	mapGroups := len(s.config.OIDCGroupRoles) > 0
+	admin, roles := s.groupRoles(identity.Groups)
+
+	var user *models.User
+	for _, candidate := range s.users {
+		if candidate.SSOSubject == subject {
+			user = candidate
+			break
+		}
+	}
+
+	if user == nil {
+		workspaceID := s.config.OIDCWorkspace
+		if _, exists := s.workspaces[workspaceID]; !exists {
+			return nil, fmt.Errorf("workspace %q of single sign-on users not found", workspaceID)
+		}
+		username := ssoUsername(identity)
+		// Local accounts are never taken over by a provider account
+		// that happens to share their username
+		if s.userByUsername(username) != nil {
+			return nil, fmt.Errorf("%w: username %q belongs to another user", ErrForbidden, username)
+		}
+		displayName := identity.Name
+		if displayName == "" {
+			displayName = username
+		}
+		user = &models.User{
+			ID:          uuid.New().String(),
+			WorkspaceID: workspaceID,
+			Username:    username,
+			DisplayName: displayName,
+			Email:       identity.Email,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+
+			Admin:      admin,
+			Roles:      roles,
+			SSOSubject: subject,
+		}
+		s.users[user.ID] = user
+		s.signingIn(user).publish(models.EventUserCreated, nil, s.snapshot(user))
+		return user, nil
+	}
+
+	before := s.snapshot(user)
+	updated := userView(user)
+	if identity.Name != "" {
+		updated.DisplayName = identity.Name
+	}
+	if identity.Email != "" {
+		updated.Email = identity.Email
+	}
+	if mapGroups {
+		updated.Admin, updated.Roles = admin, roles
+	}
+	if after := s.snapshot(updated); !bytes.Equal(before.body, after.body) {
+		updated.UpdatedAt = time.Now()
+		s.users[updated.ID] = updated
+		s.signingIn(updated).publish(models.EventUserUpdated, before, s.snapshot(updated))
+		return updated, nil
+	}
+	return user, nil
+}
+
+// signingIn returns a view of the service that attributes the changes of a
+// login to the user signing in
+func (s *Service) signingIn(user *models.User) *Service {
+	request := s.request
+	request.Actor = user.Username
+	return s.WithRequest(request)
+}
+
+// groupRoles maps groups to workspace admin and the roles held on every
+// backlog, following the configured group roles
+func (s *Service) groupRoles(groups []string) (bool, []string) {
+	admin := false
+	var roles []string
+	for _, entry := range s.config.OIDCGroupRoles {
+		group, role, ok := strings.Cut(entry, "=")
+		if !ok || !slices.Contains(groups, strings.TrimSpace(group)) {
+			continue
+		}
+		if role = strings.TrimSpace(role); role == models.RoleAdmin {
+			admin = true
+		} else if role != "" {
+			roles = addMembers(roles, []string{role})
+		}
+	}
+	return admin, roles
+}
+
+// ssoUsername derives a username from the claims of a provider account:
+// its preferred username, its email address, or its subject
+func ssoUsername(identity *auth.Identity) string {
+	username := identity.Username
+	if username == "" {
+		username = identity.Email
+	}
+	if username == "" {
+		username = identity.Subject
+	}
+	username = strings.Trim(usernameInvalidChars.ReplaceAllString(username, "-"), "-")
+	if username == "" {
+		username = "user-" + uuid.New().String()[:8]
+	}
+	return username
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recurrence operations
+
+// SetRecurrence makes a story recurring, starting a series in which it is
+// the first occurrence. When the story already belongs to a series, the
+// rule of that series is replaced.
+func (s *Service) SetRecurrence(storyID string, req models.SetRecurrenceRequest) (*models.Recurrence, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	story, exists := s.stories[storyID]
+	if !exists {
+		return nil, errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+	rule, err := recurrenceRule(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var before *snapshot
+	series, exists := s.recurrences[story.SeriesID]
+	if exists {
+		before = s.snapshot(s.recurrenceView(series))
+	} else {
+		anchor := story.PlanStart
+		if anchor.IsZero() {
+			anchor = now
+		}
+		series = &models.Recurrence{
+			ID:        uuid.New().String(),
+			BacklogID: story.BacklogID,
+			Anchor:    startOfDay(anchor),
+			Generated: 1,
+			CreatedAt: now,
+		}
+		s.recurrences[series.ID] = series
+
+		storyBefore := s.snapshot(s.storyView(story))
+		story.SeriesID = series.ID
+		story.Occurrence = 1
+		story.UpdatedAt = now
+		s.publish(models.EventStoryUpdated, storyBefore, s.snapshot(s.storyView(story)))
+	}
+	series.Rule = rule
+	series.RRule = rule.String()
+	series.Finished = false
+	series.UpdatedAt = now
+
+	s.advanceRecurrence(series, now, false)
+	view := s.recurrenceView(series)
+	s.publishSaved(models.EventRecurrenceCreated, models.EventRecurrenceUpdated, before, s.snapshot(view))
+	return view, nil
+}
+
+// GetRecurrence returns the series a story belongs to
+func (s *Service) GetRecurrence(storyID string) (*models.Recurrence, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	story, exists := s.stories[storyID]
+	if !exists {
+		return nil, errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
+	series, exists := s.recurrences[story.SeriesID]
+	if !exists {
+		return nil, errors.New("story is not recurring")
+	}
+
+	return s.recurrenceView(series), nil
+}
+
+func (s *Service) GetAllRecurrences() ([]*models.Recurrence, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	recurrences := []*models.Recurrence{}
+	for _, series := range s.recurrences {
+		if s.can(series.BacklogID, models.PermView) {
+			recurrences = append(recurrences, s.recurrenceView(series))
+		}
+	}
+	sort.Slice(recurrences, func(i, j int) bool { return recurrences[i].CreatedAt.Before(recurrences[j].CreatedAt) })
+
+	return recurrences, nil
+}
+
+// StopRecurrence ends the series a story belongs to. Occurrences that were
+// already created are kept.
+func (s *Service) StopRecurrence(storyID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	story, exists := s.stories[storyID]
+	if !exists {
+		return errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermEdit); err != nil {
+		return err
+	}
+	series, exists := s.recurrences[story.SeriesID]
+	if !exists {
+		return errors.New("story is not recurring")
+	}
+
+	deleted := s.snapshot(s.recurrenceView(series))
+	delete(s.recurrences, story.SeriesID)
+	s.publish(models.EventRecurrenceDeleted, deleted, nil)
+	return nil
+}
+
+// GenerateDueOccurrences creates the occurrences of every series whose date
+// has come, and returns how many were created. Occurrences are numbered and
+// a number is never created twice, so running it repeatedly is safe.
+func (s *Service) GenerateDueOccurrences(now time.Time) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	created := 0
+	for _, series := range s.recurrences {
+		generated := series.Generated
+		before := s.snapshot(s.recurrenceView(series))
+		s.advanceRecurrence(series, now, false)
+		s.publishIfChanged(models.EventRecurrenceUpdated, before, s.snapshot(s.recurrenceView(series)))
+		created += series.Generated - generated
+	}
+	return created
+}
+
+// StartRecurrenceScheduler generates due occurrences right away and then
+// every interval in the background. The returned function stops it.
+func (s *Service) StartRecurrenceScheduler(interval time.Duration) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.GenerateDueOccurrences(time.Now())
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				s.GenerateDueOccurrences(now)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// advanceRecurrence creates the next occurrence of a series when it is due,
+// or straight away when the latest occurrence was just closed. Missed
+// occurrences are not created retroactively: only the most recent due one
+// is, and the numbers in between are skipped. Callers must hold the lock.
+func (s *Service) advanceRecurrence(series *models.Recurrence, now time.Time, previousClosed bool) {
+	if series.Finished {
+		return
+	}
+
+	next := series.Generated + 1
+	if previousClosed {
+		if s.occurrenceAllowed(series, next) {
+			s.createOccurrence(series, next)
+		}
+	} else {
+		due := 0
+		for n := next; s.occurrenceAllowed(series, n) && !occurrenceStart(series, n).After(now); n++ {
+			due = n
+		}
+		if due > 0 {
+			s.createOccurrence(series, due)
+		}
+	}
+
+	series.NextAt = nil
+	if next = series.Generated + 1; s.occurrenceAllowed(series, next) {
+		nextAt := occurrenceStart(series, next)
+		series.NextAt = &nextAt
+	} else {
+		series.Finished = true
+	}
+}
+
+// createOccurrence creates occurrence n of a series as a copy of the latest
+// existing occurrence, with its subtasks, checklists and internal links.
+// Nothing is created when occurrence n already exists. Callers must hold
+// the lock.
+func (s *Service) createOccurrence(series *models.Recurrence, n int) {
+	var source *models.Story
+	for _, story := range s.stories {
+		if story.SeriesID != series.ID {
+			continue
+		}
+		if story.Occurrence == n {
+			series.Generated = n
+			return
+		}
+		if story.Occurrence < n && (source == nil || story.Occurrence > source.Occurrence) {
+			source = story
+		}
+	}
+	if source == nil {
+		// Every occurrence was deleted, so there is nothing to copy
+		series.Finished = true
+		return
+	}
+
+	start := occurrenceStart(series, n)
+	shift := 0
+	if !source.PlanStart.IsZero() {
+		shift = daysBetween(source.PlanStart, start)
+	}
+	clone := s.cloneStory(source, source.BacklogID, shift, nil)
+	if clone.PlanStart.IsZero() {
+		clone.PlanStart = start
+	}
+	clone.SeriesID = series.ID
+	clone.Occurrence = n
+
+	subtaskIDs := make(map[string]string)
+	for _, subtask := range s.sortedSubTasks(source.ID) {
+		subtaskIDs[subtask.ID] = s.cloneSubTask(subtask, clone, shift, nil).ID
+	}
+	s.cloneLinks(models.ItemSubTask, subtaskIDs)
+
+	series.Generated = n
+	series.UpdatedAt = time.Now()
+}
+
+// occurrenceAllowed reports whether a series has an occurrence number n
+// under its count and end date. Callers must hold the lock.
+func (s *Service) occurrenceAllowed(series *models.Recurrence, n int) bool {
+	if series.Rule.Count > 0 && n > series.Rule.Count {
+		return false
+	}
+	if series.Rule.Until != nil && occurrenceStart(series, n).After(*series.Rule.Until) {
+		return false
+	}
+	return true
+}
+
+// recurrenceView returns a copy of a series. Callers must hold the lock.
+func (s *Service) recurrenceView(series *models.Recurrence) *models.Recurrence {
+	seriesCopy := *series
+	return &seriesCopy
+}
+
+// occurrenceStart returns the plan start of occurrence n of a series.
+// Monthly occurrences falling on a day the month does not have move to its
+// last day.
+func occurrenceStart(series *models.Recurrence, n int) time.Time {
+	steps := (n - 1) * series.Rule.Interval
+	anchor := series.Anchor
+	switch series.Rule.Frequency {
+	case models.FrequencyWeekly:
+		return anchor.AddDate(0, 0, 7*steps)
+	case models.FrequencyMonthly:
+		first := time.Date(anchor.Year(), anchor.Month()+time.Month(steps), 1, 0, 0, 0, 0, anchor.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		day := anchor.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return first.AddDate(0, 0, day-1)
+	default:
+		return anchor.AddDate(0, 0, steps)
+	}
+}
+
+// recurrenceRule builds and validates the rule of a recurrence request
+func recurrenceRule(req models.SetRecurrenceRequest) (models.RecurrenceRule, error) {
+	rule := models.RecurrenceRule{
+		Frequency: req.Frequency,
+		Interval:  req.Interval,
+		Count:     req.Count,
+		Until:     req.Until,
+	}
+	if req.RRule != "" {
+		parsed, err := models.ParseRecurrenceRule(req.RRule)
+		if err != nil {
+			return rule, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		rule = parsed
+	}
+
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	if !rule.Frequency.IsValid() {
+		return rule, fmt.Errorf("%w: frequency must be DAILY, WEEKLY or MONTHLY", ErrInvalidRequest)
+	}
+	if rule.Interval < 0 || rule.Count < 0 {
+		return rule, fmt.Errorf("%w: interval and count cannot be negative", ErrInvalidRequest)
+	}
+	return rule, nil
+}
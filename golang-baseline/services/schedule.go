@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// maxScheduleDays bounds the search for free capacity so a calendar without
+// working days cannot stall the scheduler
+const maxScheduleDays = 3650
+
+// Calendar operations. Each workspace has its own calendar.
+func (s *Service) GetCalendar() models.Calendar {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.calendarOf(s.workspace())
+}
+
+func (s *Service) UpdateCalendar(calendar models.Calendar) (models.Calendar, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return models.Calendar{}, err
+	}
+	if err := validateCalendar(calendar); err != nil {
+		return models.Calendar{}, err
+	}
+	if calendar.Holidays == nil {
+		calendar.Holidays = []string{}
+	}
+	workspace, exists := s.workspaces[s.workspace()]
+	if !exists {
+		return models.Calendar{}, errors.New("workspace not found")
+	}
+
+	before := s.snapshot(workspace.Settings.Calendar)
+	workspace.Settings.Calendar = calendar
+	s.publish(models.EventCalendarUpdated, before, s.snapshot(workspace.Settings.Calendar))
+	return workspace.Settings.Calendar, nil
+}
+
+// validateCalendar checks the working days and holidays of a calendar
+func validateCalendar(calendar models.Calendar) error {
+	if len(calendar.WorkingDays) == 0 {
+		return fmt.Errorf("%w: at least one working day is required", ErrInvalidCalendar)
+	}
+	for _, weekday := range calendar.WorkingDays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("%w: unknown weekday %d", ErrInvalidCalendar, weekday)
+		}
+	}
+	for _, holiday := range calendar.Holidays {
+		if _, err := time.Parse(models.DateLayout, holiday); err != nil {
+			return fmt.Errorf("%w: holiday %q is not a YYYY-MM-DD date", ErrInvalidCalendar, holiday)
+		}
+	}
+	return nil
+}
+
+// Capacity operations. Capacities are set per workspace.
+func (s *Service) GetCapacities() []models.Capacity {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	capacities := []models.Capacity{}
+	for pic, hours := range s.capacities[s.workspace()] {
+		capacities = append(capacities, models.Capacity{PIC: pic, HoursPerDay: hours})
+	}
+	sort.Slice(capacities, func(i, j int) bool { return capacities[i].PIC < capacities[j].PIC })
+
+	return capacities
+}
+
+func (s *Service) SetCapacity(capacity models.Capacity) (*models.Capacity, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	if capacity.PIC == "" {
+		return nil, errors.New("pic is required")
+	}
+	if capacity.HoursPerDay <= 0 || capacity.HoursPerDay > 24 {
+		return nil, errors.New("hours per day must be between 0 and 24")
+	}
+
+	capacities, exists := s.capacities[s.workspace()]
+	if !exists {
+		return nil, errors.New("workspace not found")
+	}
+	var before *snapshot
+	if hours, exists := capacities[capacity.PIC]; exists {
+		before = s.snapshot(&models.Capacity{PIC: capacity.PIC, HoursPerDay: hours})
+	}
+	capacities[capacity.PIC] = capacity.HoursPerDay
+	s.publish(models.EventCapacityUpdated, before, s.snapshot(&capacity))
+	return &capacity, nil
+}
+
+// hoursPerDay returns the daily capacity of a person in a workspace.
+// Callers must hold the lock.
+func (s *Service) hoursPerDay(workspaceID, pic string) float64 {
+	if hours, exists := s.capacities[workspaceID][pic]; exists {
+		return hours
+	}
+	return float64(s.config.WorkHoursPerDay)
+}
+
+// ScheduleBacklog proposes plan dates for every story and subtask in a
+// backlog from their effort, dependencies, the capacity of their PIC and the
+// working calendar. Items that are already done keep their plan; their
+// actual end only positions their successors. When req.Apply is set the
+// proposed dates are written in a single step.
+func (s *Service) ScheduleBacklog(backlogID string, req models.ScheduleRequest) (*models.ScheduleResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	permission := models.PermView
+	if req.Apply {
+		permission = models.PermEdit
+	}
+	if err := s.authorize(backlogID, permission); err != nil {
+		return nil, err
+	}
+
+	if req.StartDate.IsZero() {
+		return nil, fmt.Errorf("%w: start date is required", ErrInvalidSchedule)
+	}
+	if s.config.WorkHoursPerDay <= 0 {
+		return nil, fmt.Errorf("%w: work hours per day must be positive", ErrInvalidSchedule)
+	}
+
+	g, err := s.buildDependencyGraph(backlogID)
+	if err != nil {
+		return nil, err
+	}
+	workspaceID := s.workspaceOf(backlogID)
+	calendar := s.calendarOf(workspaceID)
+
+	type window struct{ start, end time.Time }
+	planned := make(map[string]window, len(g.nodes))
+	// ends tracks when each item frees its successors: the day after it
+	// actually finished for done items, the end of its plan otherwise
+	ends := make(map[string]time.Time, len(g.nodes))
+	// booked tracks the hours already allocated to each person per day
+	booked := make(map[string]map[string]float64)
+	projectStart := startOfDay(req.StartDate)
+
+	for _, id := range g.order {
+		node := g.nodes[id]
+		var (
+			status             models.Status
+			pic                string
+			effort             int
+			planStart, planEnd time.Time
+			actualEnd          *time.Time
+		)
+		if node.subtask != nil {
+			status, pic, effort = node.subtask.Status, node.subtask.PIC, node.subtask.Effort
+			planStart, planEnd, actualEnd = node.subtask.PlanStart, node.subtask.PlanEnd, node.subtask.ActualEnd
+		} else {
+			status, pic, effort = node.story.Status, node.story.PIC, node.story.EffortOrigin
+			planStart, planEnd, actualEnd = node.story.PlanStart, node.story.PlanEnd, node.story.ActualEnd
+		}
+
+		if status == models.StatusDone {
+			planned[id] = window{start: planStart, end: planEnd}
+			ends[id] = planEnd
+			if actualEnd != nil {
+				ends[id] = startOfDay(*actualEnd).AddDate(0, 0, 1)
+			}
+			continue
+		}
+
+		earliest := projectStart
+		for _, pred := range node.preds {
+			if end := ends[pred]; end.After(earliest) {
+				earliest = end
+			}
+		}
+
+		hours := float64(effort)
+		if hours <= 0 {
+			hours = float64(s.durationDays(planStart, planEnd, 0) * s.config.WorkHoursPerDay)
+		}
+		capacity := s.hoursPerDay(workspaceID, pic)
+		if pic != "" && booked[pic] == nil {
+			booked[pic] = make(map[string]float64)
+		}
+
+		var start, last time.Time
+		current := startOfDay(earliest)
+		for i := 0; hours > 0; i++ {
+			if i > maxScheduleDays {
+				return nil, fmt.Errorf("%w: no working capacity found for %q", ErrInvalidSchedule, pic)
+			}
+			if calendar.IsWorkingDay(current) {
+				date := current.Format(models.DateLayout)
+				free := capacity
+				if pic != "" {
+					free -= booked[pic][date]
+				}
+				if free > 0 {
+					used := math.Min(free, hours)
+					hours -= used
+					if pic != "" {
+						booked[pic][date] += used
+					}
+					if start.IsZero() {
+						start = current
+					}
+					last = current
+				}
+			}
+			current = current.AddDate(0, 0, 1)
+		}
+		planned[id] = window{start: start, end: last.AddDate(0, 0, 1)}
+		ends[id] = planned[id].end
+	}
+
+	result := &models.ScheduleResult{
+		BacklogID: backlogID,
+		Applied:   req.Apply,
+		Changes:   []models.ScheduleChange{},
+	}
+	type update struct {
+		id                    string
+		itemType              models.ItemType
+		start, end, updatedAt *time.Time
+		proposed              window
+	}
+	var updates []update
+	propose := func(id string, itemType models.ItemType, title, pic string, start, end, updatedAt *time.Time, proposed window) {
+		unchanged := start.Equal(proposed.start) && end.Equal(proposed.end)
+		result.Changes = append(result.Changes, models.ScheduleChange{
+			ID:        id,
+			ItemType:  itemType,
+			Title:     title,
+			PIC:       pic,
+			OldStart:  *start,
+			OldEnd:    *end,
+			NewStart:  proposed.start,
+			NewEnd:    proposed.end,
+			Unchanged: unchanged,
+		})
+		if !unchanged {
+			updates = append(updates, update{id: id, itemType: itemType, start: start, end: end, updatedAt: updatedAt, proposed: proposed})
+		}
+	}
+
+	for _, story := range g.stories {
+		children := g.children[story.ID]
+		if len(children) == 0 {
+			propose(story.ID, models.ItemStory, story.Title, story.PIC,
+				&story.PlanStart, &story.PlanEnd, &story.UpdatedAt, planned[story.ID])
+			continue
+		}
+
+		// Stories span the dates of their subtasks
+		var span window
+		for _, subtask := range children {
+			w := planned[subtask.ID]
+			if !w.start.IsZero() && (span.start.IsZero() || w.start.Before(span.start)) {
+				span.start = w.start
+			}
+			if w.end.After(span.end) {
+				span.end = w.end
+			}
+		}
+		propose(story.ID, models.ItemStory, story.Title, story.PIC,
+			&story.PlanStart, &story.PlanEnd, &story.UpdatedAt, span)
+		for _, subtask := range children {
+			propose(subtask.ID, models.ItemSubTask, subtask.Title, subtask.PIC,
+				&subtask.PlanStart, &subtask.PlanEnd, &subtask.UpdatedAt, planned[subtask.ID])
+		}
+	}
+
+	// Every date has been computed at this point, so applying cannot fail
+	// half way through
+	if req.Apply {
+		now := time.Now()
+		for _, u := range updates {
+			before := s.itemSnapshot(u.itemType, u.id)
+			*u.start = u.proposed.start
+			*u.end = u.proposed.end
+			*u.updatedAt = now
+			s.publishItemUpdated(u.itemType, u.id, before)
+		}
+	}
+
+	return result, nil
+}
@@ -1,29 +1,211 @@
 package services
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"golang-baseline/auth"
+	"golang-baseline/config"
+	"golang-baseline/events"
+	"golang-baseline/feed"
+	"golang-baseline/jira"
 	"golang-baseline/models"
+	"golang-baseline/storage"
+	"golang-baseline/webhook"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Service handles business logic for the application
+// Service handles business logic for the application. Every Service made
+// with WithRequest shares the state of the one made by NewService and adds
+// who is making the changes.
 type Service struct {
-	backlogs map[string]*models.Backlog
-	stories  map[string]*models.Story
-	subtasks map[string]*models.SubTask
-	mutex    sync.RWMutex
+	*state
+	request models.RequestInfo
 }
 
-// NewService creates a new service instance
-func NewService() *Service {
-	return &Service{
-		backlogs: make(map[string]*models.Backlog),
-		stories:  make(map[string]*models.Story),
-		subtasks: make(map[string]*models.SubTask),
+// state is the data shared by a service and its request views
+type state struct {
+	backlogs     map[string]*models.Backlog
+	stories      map[string]*models.Story
+	subtasks     map[string]*models.SubTask
+	links        map[string]*models.Link
+	labels       map[string]*models.Label
+	customFields map[string]*models.CustomField
+	users        map[string]*models.User
+	comments     map[string]*models.Comment
+	attachments  map[string]*models.Attachment
+	blobs        storage.BlobStore
+	baselines    map[string]*models.Baseline
+	epics        map[string]*models.Epic
+	milestones   map[string]*models.Milestone
+	templates    map[string]*models.StoryTemplate
+	recurrences  map[string]*models.Recurrence
+	// capacities holds, by workspace, the hours per day of people whose
+	// capacity differs from the configured default
+	capacities map[string]map[string]float64
+	config     *config.Config
+	mutex      sync.RWMutex
+
+	// jira is nil unless a Jira site is configured. jiraSyncing keeps sync
+	// runs from overlapping without holding the main lock during requests.
+	jira          *jira.Client
+	jiraLinks     map[string]*models.JiraLink
+	jiraConflicts map[string]*models.JiraConflict
+	jiraReports   map[string]*models.JiraSyncReport
+	jiraSyncing   sync.Mutex
+
+	// deliveryLog holds the delivery IDs of each webhook, oldest first
+	webhooks    map[string]*models.Webhook
+	deliveries  map[string]*models.WebhookDelivery
+	deliveryLog map[string][]string
+	dispatcher  *webhook.Dispatcher
+
+	bus  *events.Bus
+	feed *feed.Hub
+
+	// audit only grows: entries are never changed or removed
+	audit []models.AuditEntry
+
+	// tokens signs and verifies JWTs; apiKeys holds hashed keys by ID
+	tokens  *auth.Tokens
+	apiKeys map[string]*models.APIKey
+
+	// oidc is nil unless single sign-on is configured. logins holds the
+	// logins waiting for the provider by state; sessions holds the browser
+	// sessions by the hash of their ID.
+	oidc     *auth.OIDC
+	logins   map[string]*oidcLogin
+	sessions map[string]*models.Session
+
+	// roles holds the custom roles; the built-in ones are not stored
+	roles  map[string]*models.Role
+	teams  map[string]*models.Team
+	grants map[string]*models.Grant
+
+	// workspaces always holds the default workspace
+	workspaces map[string]*models.Workspace
+}
+
+// NewService creates a new service instance. It fails when the configured
+// JWT keys cannot be loaded.
+func NewService(cfg *config.Config) (*Service, error) {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	tokens, err := auth.NewTokens(auth.TokenOptions{
+		Secret:         secret,
+		PublicKeyFiles: cfg.JWTPublicKeyFiles,
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+		TTL:            time.Duration(cfg.JWTTTL) * time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var oidcClient *auth.OIDC
+	if cfg.OIDCIssuer != "" {
+		oidcClient = auth.NewOIDC(auth.OIDCOptions{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+			GroupsClaim:  cfg.OIDCGroupsClaim,
+		})
+	}
+
+	var jiraClient *jira.Client
+	if cfg.JiraBaseURL != "" {
+		jiraClient = jira.NewClient(cfg.JiraBaseURL, cfg.JiraEmail, cfg.JiraAPIToken,
+			time.Duration(cfg.JiraTimeout)*time.Second)
 	}
+
+	s := &Service{state: &state{
+		backlogs:     make(map[string]*models.Backlog),
+		stories:      make(map[string]*models.Story),
+		subtasks:     make(map[string]*models.SubTask),
+		links:        make(map[string]*models.Link),
+		labels:       make(map[string]*models.Label),
+		customFields: make(map[string]*models.CustomField),
+		users:        make(map[string]*models.User),
+		comments:     make(map[string]*models.Comment),
+		attachments:  make(map[string]*models.Attachment),
+		blobs:        storage.NewLocalStore(cfg.AttachmentDir),
+		baselines:    make(map[string]*models.Baseline),
+		epics:        make(map[string]*models.Epic),
+		milestones:   make(map[string]*models.Milestone),
+		templates:    make(map[string]*models.StoryTemplate),
+		recurrences:  make(map[string]*models.Recurrence),
+		capacities:   make(map[string]map[string]float64),
+		config:       cfg,
+
+		jira:          jiraClient,
+		jiraLinks:     make(map[string]*models.JiraLink),
+		jiraConflicts: make(map[string]*models.JiraConflict),
+		jiraReports:   make(map[string]*models.JiraSyncReport),
+
+		webhooks:    make(map[string]*models.Webhook),
+		deliveries:  make(map[string]*models.WebhookDelivery),
+		deliveryLog: make(map[string][]string),
+
+		bus:  events.NewBus(),
+		feed: feed.NewHub(cfg.FeedHistorySize),
+
+		audit: []models.AuditEntry{},
+
+		tokens:  tokens,
+		apiKeys: make(map[string]*models.APIKey),
+
+		oidc:     oidcClient,
+		logins:   make(map[string]*oidcLogin),
+		sessions: make(map[string]*models.Session),
+
+		roles:  make(map[string]*models.Role),
+		teams:  make(map[string]*models.Team),
+		grants: make(map[string]*models.Grant),
+
+		workspaces: make(map[string]*models.Workspace),
+	}}
+	s.dispatcher = webhook.NewDispatcher(webhook.Options{
+		Workers:     cfg.WebhookWorkers,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseDelay:   time.Duration(cfg.WebhookRetryBase) * time.Second,
+		MaxDelay:    time.Duration(cfg.WebhookRetryMax) * time.Second,
+		Timeout:     time.Duration(cfg.WebhookTimeout) * time.Second,
+
+		AllowPrivateNetworks: cfg.WebhookAllowPrivateNetworks,
+	}, s.webhookJobWanted, s.recordWebhookAttempt)
+
+	s.bus.Subscribe("feed", s.streamEvent, models.ChangeEvents...)
+	s.bus.SubscribeAsync("webhooks", cfg.EventWorkers, s.deliverEvent, models.ChangeEvents...)
+	s.bus.Subscribe("audit", s.recordAudit)
+
+	s.workspaces[models.DefaultWorkspace] = &models.Workspace{
+		ID:        models.DefaultWorkspace,
+		Name:      "Default",
+		Settings:  models.WorkspaceSettings{Calendar: models.DefaultCalendar()},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	s.capacities[models.DefaultWorkspace] = make(map[string]float64)
+	return s, nil
+}
+
+// WithRequest returns a view of the service whose changes are attributed
+// to a request. Changes made through the service itself, such as those of
+// background jobs, are attributed to the system.
+func (s *Service) WithRequest(request models.RequestInfo) *Service {
+	return &Service{state: s.state, request: request}
 }
 
 // Backlog operations
@@ -33,14 +215,18 @@ func (s *Service) CreateBacklog(req models.CreateBacklogRequest) (*models.Backlo
 
 	backlog := &models.Backlog{
 		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
 		Title:       req.Title,
 		Description: req.Description,
+		WIPLimits:   models.WIPLimits{},
 		Stories:     []models.Story{},
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
 	s.backlogs[backlog.ID] = backlog
+	s.publish(models.EventBacklogCreated, nil, s.snapshot(backlog))
+	s.grantOwner(backlog.ID)
 	return backlog, nil
 }
 
@@ -52,25 +238,11 @@ func (s *Service) GetBacklog(id string) (*models.Backlog, error) {
 	if !exists {
 		return nil, errors.New("backlog not found")
 	}
-
-	// Load stories for this backlog
-	var stories []models.Story
-	for _, story := range s.stories {
-		if story.BacklogID == id {
-			// Load subtasks for this story
-			var subtasks []models.SubTask
-			for _, subtask := range s.subtasks {
-				if subtask.StoryID == story.ID {
-					subtasks = append(subtasks, *subtask)
-				}
-			}
-			story.SubTasks = subtasks
-			stories = append(stories, *story)
-		}
+	if err := s.authorize(id, models.PermView); err != nil {
+		return nil, err
 	}
-	backlog.Stories = stories
 
-	return backlog, nil
+	return s.backlogView(backlog), nil
 }
 
 func (s *Service) GetAllBacklogs() ([]*models.Backlog, error) {
@@ -79,27 +251,37 @@ func (s *Service) GetAllBacklogs() ([]*models.Backlog, error) {
 
 	var backlogs []*models.Backlog
 	for _, backlog := range s.backlogs {
-		// Load stories for each backlog
-		var stories []models.Story
-		for _, story := range s.stories {
-			if story.BacklogID == backlog.ID {
-				// Load subtasks for this story
-				var subtasks []models.SubTask
-				for _, subtask := range s.subtasks {
-					if subtask.StoryID == story.ID {
-						subtasks = append(subtasks, *subtask)
-					}
+		if !s.can(backlog.ID, models.PermView) {
+			continue
+		}
+		backlogs = append(backlogs, s.backlogView(backlog))
+	}
+
+	return backlogs, nil
+}
+
+// backlogView returns a copy of a backlog with its stories and their
+// subtasks loaded. Callers must hold the lock.
+func (s *Service) backlogView(backlog *models.Backlog) *models.Backlog {
+	var stories []models.Story
+	for _, story := range s.stories {
+		if story.BacklogID == backlog.ID {
+			// Load subtasks for this story
+			var subtasks []models.SubTask
+			for _, subtask := range s.subtasks {
+				if subtask.StoryID == story.ID {
+					subtasks = append(subtasks, s.subTaskView(subtask))
 				}
-				story.SubTasks = subtasks
-				stories = append(stories, *story)
 			}
+			storyCopy := s.storyView(story)
+			storyCopy.SubTasks = subtasks
+			stories = append(stories, storyCopy)
 		}
-		backlogCopy := *backlog
-		backlogCopy.Stories = stories
-		backlogs = append(backlogs, &backlogCopy)
 	}
 
-	return backlogs, nil
+	backlogCopy := *backlog
+	backlogCopy.Stories = stories
+	return &backlogCopy
 }
 
 // Story operations
@@ -111,6 +293,22 @@ func (s *Service) CreateStory(req models.CreateStoryRequest) (*models.Story, err
 	if _, exists := s.backlogs[req.BacklogID]; !exists {
 		return nil, errors.New("backlog not found")
 	}
+	if err := s.authorize(req.BacklogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	priority, err := defaultPriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.resolveLabels(req.BacklogID, req.Labels)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := s.applyCustomValues(req.BacklogID, nil, req.CustomFields, true)
+	if err != nil {
+		return nil, err
+	}
 
 	story := &models.Story{
 		ID:           uuid.New().String(),
@@ -123,12 +321,17 @@ func (s *Service) CreateStory(req models.CreateStoryRequest) (*models.Story, err
 		PlanStart:    req.PlanStart,
 		PlanEnd:      req.PlanEnd,
 		Status:       models.StatusTodo,
+		Priority:     priority,
+		Labels:       labels,
+		CustomFields: customFields,
+		Checklist:    []models.ChecklistItem{},
 		SubTasks:     []models.SubTask{},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
 	s.stories[story.ID] = story
+	s.publish(models.EventStoryCreated, nil, s.snapshot(s.storyView(story)))
 	return story, nil
 }
 
@@ -140,38 +343,53 @@ func (s *Service) GetStory(id string) (*models.Story, error) {
 	if !exists {
 		return nil, errors.New("story not found")
 	}
+	if err := s.authorize(story.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
 
 	// Load subtasks for this story
 	var subtasks []models.SubTask
 	for _, subtask := range s.subtasks {
 		if subtask.StoryID == id {
-			subtasks = append(subtasks, *subtask)
+			subtasks = append(subtasks, s.subTaskView(subtask))
 		}
 	}
-	story.SubTasks = subtasks
+	storyCopy := s.storyView(story)
+	storyCopy.SubTasks = subtasks
 
-	return story, nil
+	return &storyCopy, nil
 }
 
-func (s *Service) GetStoriesByBacklog(backlogID string) ([]*models.Story, error) {
+// GetStoriesByBacklog returns the stories of a backlog that match the filter
+func (s *Service) GetStoriesByBacklog(backlogID string, filter models.ListFilter) ([]*models.Story, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+	if err := s.validateSort(backlogID, filter.Sort); err != nil {
+		return nil, err
+	}
+
 	var stories []*models.Story
 	for _, story := range s.stories {
-		if story.BacklogID == backlogID {
+		if story.BacklogID == backlogID && s.matchesFilter(storyListItem(story), filter) {
 			// Load subtasks for this story
 			var subtasks []models.SubTask
 			for _, subtask := range s.subtasks {
 				if subtask.StoryID == story.ID {
-					subtasks = append(subtasks, *subtask)
+					subtasks = append(subtasks, s.subTaskView(subtask))
 				}
 			}
-			storyCopy := *story
+			storyCopy := s.storyView(story)
 			storyCopy.SubTasks = subtasks
 			stories = append(stories, &storyCopy)
 		}
 	}
+	sort.Slice(stories, func(i, j int) bool {
+		return lessBySort(filter.Sort, storyListItem(stories[i]), storyListItem(stories[j]))
+	})
 
 	return stories, nil
 }
@@ -182,26 +400,48 @@ func (s *Service) CreateSubTask(req models.CreateSubTaskRequest) (*models.SubTas
 	defer s.mutex.Unlock()
 
 	// Check if story exists
-	if _, exists := s.stories[req.StoryID]; !exists {
+	story, exists := s.stories[req.StoryID]
+	if !exists {
 		return nil, errors.New("story not found")
 	}
+	if err := s.authorize(story.BacklogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	priority, err := defaultPriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.resolveLabels(story.BacklogID, req.Labels)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := s.applyCustomValues(story.BacklogID, nil, req.CustomFields, true)
+	if err != nil {
+		return nil, err
+	}
 
 	subtask := &models.SubTask{
-		ID:          uuid.New().String(),
-		StoryID:     req.StoryID,
-		Title:       req.Title,
-		Description: req.Description,
-		Effort:      req.Effort,
-		JiraURL:     req.JiraURL,
-		PIC:         req.PIC,
-		PlanStart:   req.PlanStart,
-		PlanEnd:     req.PlanEnd,
-		Status:      models.StatusTodo,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:           uuid.New().String(),
+		StoryID:      req.StoryID,
+		Title:        req.Title,
+		Description:  req.Description,
+		Effort:       req.Effort,
+		JiraURL:      req.JiraURL,
+		PIC:          req.PIC,
+		PlanStart:    req.PlanStart,
+		PlanEnd:      req.PlanEnd,
+		Status:       models.StatusTodo,
+		Priority:     priority,
+		Labels:       labels,
+		CustomFields: customFields,
+		Checklist:    []models.ChecklistItem{},
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	s.subtasks[subtask.ID] = subtask
+	s.publish(models.EventSubTaskCreated, nil, s.snapshot(s.subTaskView(subtask)))
 	return subtask, nil
 }
 
@@ -213,24 +453,295 @@ func (s *Service) GetSubTask(id string) (*models.SubTask, error) {
 	if !exists {
 		return nil, errors.New("subtask not found")
 	}
+	if err := s.authorize(s.backlogOfSubTask(subtask), models.PermView); err != nil {
+		return nil, err
+	}
 
-	return subtask, nil
+	subtaskCopy := s.subTaskView(subtask)
+	return &subtaskCopy, nil
 }
 
-func (s *Service) GetSubTasksByStory(storyID string) ([]*models.SubTask, error) {
+// GetSubTasksByStory returns the subtasks of a story that match the filter
+func (s *Service) GetSubTasksByStory(storyID string, filter models.ListFilter) ([]*models.SubTask, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
+	backlogID := ""
+	if story, exists := s.stories[storyID]; exists {
+		backlogID = story.BacklogID
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+	if err := s.validateSort(backlogID, filter.Sort); err != nil {
+		return nil, err
+	}
+
 	var subtasks []*models.SubTask
 	for _, subtask := range s.subtasks {
-		if subtask.StoryID == storyID {
-			subtasks = append(subtasks, subtask)
+		if subtask.StoryID == storyID && s.matchesFilter(subTaskListItem(subtask), filter) {
+			subtaskCopy := s.subTaskView(subtask)
+			subtasks = append(subtasks, &subtaskCopy)
 		}
 	}
+	sort.Slice(subtasks, func(i, j int) bool {
+		return lessBySort(filter.Sort, subTaskListItem(subtasks[i]), subTaskListItem(subtasks[j]))
+	})
 
 	return subtasks, nil
 }
 
+// Update operations
+func (s *Service) UpdateStory(id string, req models.UpdateStoryRequest) (*models.Story, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	story, exists := s.stories[id]
+	if !exists {
+		return nil, errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidRequest)
+	}
+	if req.Priority != nil && !req.Priority.IsValid() {
+		return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidRequest, *req.Priority)
+	}
+	var labels []string
+	if req.Labels != nil {
+		var err error
+		if labels, err = s.resolveLabels(story.BacklogID, *req.Labels); err != nil {
+			return nil, err
+		}
+	}
+	customFields, err := s.applyCustomValues(story.BacklogID, story.CustomFields, req.CustomFields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	before := s.snapshot(s.storyView(story))
+	applyStoryUpdate(story, req, labels, customFields)
+
+	storyCopy := s.storyView(story)
+	s.publish(models.EventStoryUpdated, before, s.snapshot(storyCopy))
+	return &storyCopy, nil
+}
+
+// applyStoryUpdate sets the fields given in an update request on a story,
+// using the labels and custom values already resolved for the request
+func applyStoryUpdate(story *models.Story, req models.UpdateStoryRequest, labels []string, customFields models.CustomValues) {
+	if req.Title != nil {
+		story.Title = *req.Title
+	}
+	if req.Description != nil {
+		story.Description = *req.Description
+	}
+	if req.JiraURL != nil {
+		story.JiraURL = *req.JiraURL
+	}
+	if req.EffortOrigin != nil {
+		story.EffortOrigin = *req.EffortOrigin
+	}
+	if req.PIC != nil {
+		story.PIC = *req.PIC
+	}
+	if req.PlanStart != nil {
+		story.PlanStart = *req.PlanStart
+	}
+	if req.PlanEnd != nil {
+		story.PlanEnd = *req.PlanEnd
+	}
+	if req.Priority != nil {
+		story.Priority = *req.Priority
+	}
+	if req.Labels != nil {
+		story.Labels = labels
+	}
+	story.CustomFields = customFields
+	story.UpdatedAt = time.Now()
+}
+
+func (s *Service) UpdateSubTask(id string, req models.UpdateSubTaskRequest) (*models.SubTask, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subtask, exists := s.subtasks[id]
+	if !exists {
+		return nil, errors.New("subtask not found")
+	}
+	if err := s.authorize(s.backlogOfSubTask(subtask), models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidRequest)
+	}
+	if req.Priority != nil && !req.Priority.IsValid() {
+		return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidRequest, *req.Priority)
+	}
+	var labels []string
+	if req.Labels != nil {
+		var err error
+		if labels, err = s.resolveLabels(s.backlogOfSubTask(subtask), *req.Labels); err != nil {
+			return nil, err
+		}
+	}
+	customFields, err := s.applyCustomValues(s.backlogOfSubTask(subtask), subtask.CustomFields, req.CustomFields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	before := s.snapshot(s.subTaskView(subtask))
+	applySubTaskUpdate(subtask, req, labels, customFields)
+
+	subtaskCopy := s.subTaskView(subtask)
+	s.publish(models.EventSubTaskUpdated, before, s.snapshot(subtaskCopy))
+	return &subtaskCopy, nil
+}
+
+// applySubTaskUpdate sets the fields given in an update request on a
+// subtask, using the labels and custom values already resolved for the
+// request
+func applySubTaskUpdate(subtask *models.SubTask, req models.UpdateSubTaskRequest, labels []string, customFields models.CustomValues) {
+	if req.Title != nil {
+		subtask.Title = *req.Title
+	}
+	if req.Description != nil {
+		subtask.Description = *req.Description
+	}
+	if req.Effort != nil {
+		subtask.Effort = *req.Effort
+	}
+	if req.JiraURL != nil {
+		subtask.JiraURL = *req.JiraURL
+	}
+	if req.PIC != nil {
+		subtask.PIC = *req.PIC
+	}
+	if req.PlanStart != nil {
+		subtask.PlanStart = *req.PlanStart
+	}
+	if req.PlanEnd != nil {
+		subtask.PlanEnd = *req.PlanEnd
+	}
+	if req.Priority != nil {
+		subtask.Priority = *req.Priority
+	}
+	if req.Labels != nil {
+		subtask.Labels = labels
+	}
+	subtask.CustomFields = customFields
+	subtask.UpdatedAt = time.Now()
+}
+
+// Delete operations
+
+// DeleteStory deletes a story together with its subtasks, links, comments
+// and attachments
+func (s *Service) DeleteStory(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.stories[id]; !exists {
+		return errors.New("story not found")
+	}
+	if err := s.authorizeItem(models.ItemStory, id, models.PermEdit); err != nil {
+		return err
+	}
+
+	s.deleteStory(id)
+	return nil
+}
+
+// deleteStory removes a story and what hangs off it. Callers must hold the
+// lock.
+func (s *Service) deleteStory(id string) {
+	deleted := s.snapshot(s.storyView(s.stories[id]))
+	for _, subtask := range s.subtasks {
+		if subtask.StoryID == id {
+			s.deleteSubTask(subtask.ID)
+		}
+	}
+	s.deleteItemRelations(models.ItemStory, id)
+	for _, epic := range s.epics {
+		if members, removed := removeMember(epic.StoryIDs, id); removed {
+			before := s.snapshot(s.epicView(epic))
+			epic.StoryIDs = members
+			s.publish(models.EventEpicUpdated, before, s.snapshot(s.epicView(epic)))
+		}
+	}
+	for _, milestone := range s.milestones {
+		if members, removed := removeMember(milestone.StoryIDs, id); removed {
+			before := s.snapshot(s.milestoneView(milestone))
+			milestone.StoryIDs = members
+			s.publish(models.EventMilestoneUpdated, before, s.snapshot(s.milestoneView(milestone)))
+		}
+	}
+
+	delete(s.stories, id)
+	s.publish(models.EventStoryDeleted, deleted, nil)
+}
+
+// DeleteSubTask deletes a subtask together with its links, comments and
+// attachments
+func (s *Service) DeleteSubTask(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.subtasks[id]; !exists {
+		return errors.New("subtask not found")
+	}
+	if err := s.authorizeItem(models.ItemSubTask, id, models.PermEdit); err != nil {
+		return err
+	}
+
+	s.deleteSubTask(id)
+	return nil
+}
+
+// deleteSubTask removes a subtask and what hangs off it. Callers must hold
+// the lock.
+func (s *Service) deleteSubTask(id string) {
+	deleted := s.snapshot(s.subTaskView(s.subtasks[id]))
+	s.deleteItemRelations(models.ItemSubTask, id)
+	delete(s.subtasks, id)
+	s.publish(models.EventSubTaskDeleted, deleted, nil)
+}
+
+// deleteItemRelations removes the links, comments and attachments of a work
+// item. Callers must hold the lock.
+func (s *Service) deleteItemRelations(itemType models.ItemType, id string) {
+	for linkID, link := range s.links {
+		if link.ItemType == itemType && (link.SourceID == id || link.TargetID == id) {
+			deleted := s.snapshot(link)
+			delete(s.links, linkID)
+			s.publish(models.EventLinkDeleted, deleted, nil)
+		}
+	}
+	for commentID, comment := range s.comments {
+		if comment.ItemType == itemType && comment.ItemID == id {
+			deleted := s.snapshot(comment)
+			delete(s.comments, commentID)
+			s.publish(models.EventCommentDeleted, deleted, nil)
+		}
+	}
+	s.deleteAttachmentsOf(itemType, id)
+}
+
+// defaultPriority validates a requested priority, defaulting to medium
+func defaultPriority(priority models.Priority) (models.Priority, error) {
+	if priority == "" {
+		return models.PriorityMedium, nil
+	}
+	if !priority.IsValid() {
+		return "", fmt.Errorf("%w: unknown priority %q", ErrInvalidRequest, priority)
+	}
+	return priority, nil
+}
+
 // Status update operations
 func (s *Service) UpdateBacklogStatus(id string, status models.Status) error {
 	s.mutex.Lock()
@@ -240,20 +751,82 @@ func (s *Service) UpdateBacklogStatus(id string, status models.Status) error {
 	if !exists {
 		return errors.New("backlog not found")
 	}
+	if err := s.authorize(id, models.PermEdit); err != nil {
+		return err
+	}
 
+	before := s.snapshot(backlog)
 	backlog.UpdatedAt = time.Now()
+	s.publish(models.EventBacklogUpdated, before, s.snapshot(backlog))
 	return nil
 }
 
-func (s *Service) UpdateStoryStatus(id string, status models.Status) error {
+// UpdateStoryStatus changes the status of a story. The returned warnings
+// describe rules that were broken but not enforced.
+func (s *Service) UpdateStoryStatus(id string, status models.Status) ([]string, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	story, exists := s.stories[id]
 	if !exists {
-		return errors.New("story not found")
+		return nil, errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermEdit); err != nil {
+		return nil, err
 	}
 
+	warnings, err := s.checkStoryStatus(story, status, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setStoryStatus(story, status)
+	return warnings, nil
+}
+
+// checkStoryStatus applies the workflow rules of its workspace to a story
+// moving to a status, returning warnings for the rules that are only
+// flagged. pending is the number of other stories of the backlog being
+// moved to the same status in the same operation. Callers must hold the
+// lock.
+func (s *Service) checkStoryStatus(story *models.Story, status models.Status, pending int) ([]string, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	rules := s.workflow(s.workspaceOf(story.BacklogID))
+
+	var warnings []string
+	if status == models.StatusInProgress {
+		if blockers := s.unfinishedBlockers(models.ItemStory, story.ID); len(blockers) > 0 {
+			msg := fmt.Sprintf("story has %d unfinished blocker(s)", len(blockers))
+			if rules.BlockedStatusPolicy == config.PolicyReject {
+				return nil, fmt.Errorf("%w: %s", ErrBlocked, msg)
+			}
+			warnings = append(warnings, msg)
+		}
+	}
+
+	if breach := s.wipLimitBreach(story, status, pending); breach != "" {
+		if rules.WIPLimitPolicy == config.PolicyReject {
+			return nil, fmt.Errorf("%w: %s", ErrWIPLimit, breach)
+		}
+		warnings = append(warnings, "WIP limit exceeded: "+breach)
+	}
+
+	if status == models.StatusDone && *rules.RequireChecklistForDone {
+		if unchecked := uncheckedRequired(story.Checklist); unchecked > 0 {
+			return nil, fmt.Errorf("%w: %d item(s) left", ErrChecklistIncomplete, unchecked)
+		}
+	}
+
+	return warnings, nil
+}
+
+// setStoryStatus changes the status of a story that passed
+// checkStoryStatus. Callers must hold the lock.
+func (s *Service) setStoryStatus(story *models.Story, status models.Status) {
+	from := story.Status
+	before := s.snapshot(s.storyView(story))
 	story.Status = status
 	story.UpdatedAt = time.Now()
 
@@ -265,18 +838,69 @@ func (s *Service) UpdateStoryStatus(id string, status models.Status) error {
 		story.ActualEnd = &now
 	}
 
-	return nil
+	// Closing the latest occurrence of a series brings the next one forward
+	if series, exists := s.recurrences[story.SeriesID]; exists && status == models.StatusDone {
+		seriesBefore := s.snapshot(s.recurrenceView(series))
+		s.advanceRecurrence(series, now, story.Occurrence == series.Generated)
+		s.publishIfChanged(models.EventRecurrenceUpdated, seriesBefore, s.snapshot(s.recurrenceView(series)))
+	}
+
+	if from != status {
+		s.publish(models.EventStoryStatusChanged, before, s.snapshot(s.storyView(story)))
+	}
 }
 
-func (s *Service) UpdateSubTaskStatus(id string, status models.Status) error {
+// UpdateSubTaskStatus changes the status of a subtask. The returned warnings
+// describe rules that were broken but not enforced.
+func (s *Service) UpdateSubTaskStatus(id string, status models.Status) ([]string, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	subtask, exists := s.subtasks[id]
 	if !exists {
-		return errors.New("subtask not found")
+		return nil, errors.New("subtask not found")
+	}
+	if err := s.authorize(s.backlogOfSubTask(subtask), models.PermEdit); err != nil {
+		return nil, err
 	}
 
+	warnings, err := s.checkSubTaskStatus(subtask, status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setSubTaskStatus(subtask, status)
+	return warnings, nil
+}
+
+// checkSubTaskStatus applies the workflow rules to a subtask moving to a
+// status, returning warnings for the rules that are only flagged. Callers
+// must hold the lock.
+func (s *Service) checkSubTaskStatus(subtask *models.SubTask, status models.Status) ([]string, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	rules := s.workflow(s.workspaceOf(s.backlogOfSubTask(subtask)))
+
+	var warnings []string
+	if status == models.StatusInProgress {
+		if blockers := s.unfinishedBlockers(models.ItemSubTask, subtask.ID); len(blockers) > 0 {
+			msg := fmt.Sprintf("subtask has %d unfinished blocker(s)", len(blockers))
+			if rules.BlockedStatusPolicy == config.PolicyReject {
+				return nil, fmt.Errorf("%w: %s", ErrBlocked, msg)
+			}
+			warnings = append(warnings, msg)
+		}
+	}
+
+	return warnings, nil
+}
+
+// setSubTaskStatus changes the status of a subtask that passed
+// checkSubTaskStatus. Callers must hold the lock.
+func (s *Service) setSubTaskStatus(subtask *models.SubTask, status models.Status) {
+	from := subtask.Status
+	before := s.snapshot(s.subTaskView(subtask))
 	subtask.Status = status
 	subtask.UpdatedAt = time.Now()
 
@@ -288,7 +912,9 @@ func (s *Service) UpdateSubTaskStatus(id string, status models.Status) error {
 		subtask.ActualEnd = &now
 	}
 
-	return nil
+	if from != status {
+		s.publish(models.EventSubTaskStatusChanged, before, s.snapshot(s.subTaskView(subtask)))
+	}
 }
 
 // Dashboard/Statistics operations
@@ -296,26 +922,79 @@ func (s *Service) GetDashboardStats() (map[string]interface{}, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
+	// Only the backlogs the caller can see are counted
+	visible := s.visibleBacklogs()
+	var stories []*models.Story
+	for _, story := range s.stories {
+		if visible[story.BacklogID] {
+			stories = append(stories, story)
+		}
+	}
+	var subtasks []*models.SubTask
+	for _, subtask := range s.subtasks {
+		if visible[s.backlogOfSubTask(subtask)] {
+			subtasks = append(subtasks, subtask)
+		}
+	}
+
 	stats := map[string]interface{}{
-		"total_backlogs": len(s.backlogs),
-		"total_stories":  len(s.stories),
-		"total_subtasks": len(s.subtasks),
+		"total_backlogs": len(visible),
+		"total_stories":  len(stories),
+		"total_subtasks": len(subtasks),
 	}
 
 	// Count by status
 	storyStatusCount := make(map[models.Status]int)
 	subtaskStatusCount := make(map[models.Status]int)
 
-	for _, story := range s.stories {
+	for _, story := range stories {
 		storyStatusCount[story.Status]++
 	}
 
-	for _, subtask := range s.subtasks {
+	for _, subtask := range subtasks {
 		subtaskStatusCount[subtask.Status]++
 	}
 
 	stats["story_status"] = storyStatusCount
 	stats["subtask_status"] = subtaskStatusCount
 
+	// Count by priority and label. Labels are counted by ID, since labels
+	// of different backlogs may share a name.
+	storyPriorityCount := make(map[models.Priority]int)
+	subtaskPriorityCount := make(map[models.Priority]int)
+	storyLabelCount := make(map[string]*models.LabelCount)
+	subtaskLabelCount := make(map[string]*models.LabelCount)
+	countLabel := func(counts map[string]*models.LabelCount, label *models.Label) {
+		count, exists := counts[label.ID]
+		if !exists {
+			count = &models.LabelCount{LabelID: label.ID, BacklogID: label.BacklogID, Name: label.Name, Color: label.Color}
+			counts[label.ID] = count
+		}
+		count.Count++
+	}
+
+	for _, story := range stories {
+		storyPriorityCount[story.Priority]++
+		for _, id := range story.Labels {
+			if label, exists := s.labels[id]; exists {
+				countLabel(storyLabelCount, label)
+			}
+		}
+	}
+
+	for _, subtask := range subtasks {
+		subtaskPriorityCount[subtask.Priority]++
+		for _, id := range subtask.Labels {
+			if label, exists := s.labels[id]; exists {
+				countLabel(subtaskLabelCount, label)
+			}
+		}
+	}
+
+	stats["story_priority"] = storyPriorityCount
+	stats["subtask_priority"] = subtaskPriorityCount
+	stats["story_labels"] = storyLabelCount
+	stats["subtask_labels"] = subtaskLabelCount
+
 	return stats, nil
 }
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"golang-baseline/config"
+	"golang-baseline/models"
+	"testing"
+)
+
+// newTestService creates a service with the default configuration, changed
+// by configure when it is not nil. Authentication is not required, so calls
+// on the service itself are not checked.
+func newTestService(t *testing.T, configure func(*config.Config)) *Service {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.AttachmentDir = t.TempDir()
+	cfg.AuthRequired = false
+	if configure != nil {
+		configure(cfg)
+	}
+	s, err := NewService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// asUser returns the view of a service for requests made with an API key of
+// a new user, who is an admin when admin is set
+func asUser(t *testing.T, s *Service, username string, admin bool) (*Service, *models.User) {
+	t.Helper()
+	user, err := s.CreateUser(models.CreateUserRequest{Username: username, Admin: admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal := &models.Principal{UserID: user.ID, Username: user.Username, Method: models.AuthAPIKey}
+	return s.WithRequest(models.RequestInfo{Actor: user.Username, Principal: principal}), user
+}
@@ -0,0 +1,685 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sheetColumns are the columns of a backlog export, followed by one
+// "cf.<key>" column per custom field. Stories and subtasks share the sheet
+// and are told apart by the type column.
+var sheetColumns = []string{
+	"type", "id", "story_id", "story_key", "external_key", "title", "description", "jira_url",
+	"effort_origin", "effort", "pic", "plan_start", "plan_end", "actual_start", "actual_end",
+	"status", "priority", "labels", "checklist", "series_id", "occurrence",
+	"comment_count", "blocked", "blocked_by", "created_at", "updated_at",
+}
+
+// sheetImportColumns are the columns an import reads; the others are
+// derived or managed elsewhere and are ignored
+var sheetImportColumns = map[string]bool{
+	"type": true, "id": true, "story_id": true, "story_key": true, "external_key": true,
+	"title": true, "description": true, "jira_url": true, "effort_origin": true, "effort": true,
+	"pic": true, "plan_start": true, "plan_end": true, "status": true, "priority": true, "labels": true,
+}
+
+// sheetListSeparator separates the values of list cells such as labels
+const sheetListSeparator = "; "
+
+// sheetRowPlan is a validated spreadsheet row and the change it makes
+type sheetRowPlan struct {
+	result       *models.SheetRowResult
+	cells        []string
+	key          string
+	story        *models.Story
+	subtask      *models.SubTask
+	storyReq     models.UpdateStoryRequest
+	subtaskReq   models.UpdateSubTaskRequest
+	labels       []string
+	customFields models.CustomValues
+	status       models.Status
+	// parent is the story of a subtask row, either stored or created by
+	// parentRow in the same file
+	parent    *models.Story
+	parentRow *sheetRowPlan
+}
+
+// Spreadsheet operations
+
+// ExportBacklogSheet returns the stories and subtasks of a backlog as rows
+// of text cells, headed by the column names. Each story is followed by its
+// subtasks.
+func (s *Service) ExportBacklogSheet(backlogID string) ([][]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermView); err != nil {
+		return nil, err
+	}
+
+	fields := s.sortedCustomFields(backlogID)
+	header := append([]string{}, sheetColumns...)
+	for _, field := range fields {
+		header = append(header, models.SortCustomFieldPrefix+field.Key)
+	}
+	rows := [][]string{header}
+
+	for _, stored := range s.sortedStories(backlogID) {
+		story := s.storyView(stored)
+		row := []string{
+			string(models.ItemStory), story.ID, "", "", story.ExternalKey, story.Title, story.Description, story.JiraURL,
+			strconv.Itoa(story.EffortOrigin), "", story.PIC, sheetTime(story.PlanStart), sheetTime(story.PlanEnd),
+			sheetOptionalTime(story.ActualStart), sheetOptionalTime(story.ActualEnd),
+			string(story.Status), string(story.Priority), strings.Join(s.labelNames(story.Labels), sheetListSeparator),
+			sheetChecklist(story.Checklist), story.SeriesID, sheetOptionalInt(story.Occurrence),
+			strconv.Itoa(story.CommentCount), strconv.FormatBool(story.Blocked), strings.Join(story.BlockedBy, sheetListSeparator),
+			sheetTime(story.CreatedAt), sheetTime(story.UpdatedAt),
+		}
+		rows = append(rows, append(row, sheetCustomValues(fields, story.CustomFields)...))
+
+		for _, storedSubTask := range s.sortedSubTasks(story.ID) {
+			subtask := s.subTaskView(storedSubTask)
+			row := []string{
+				string(models.ItemSubTask), subtask.ID, story.ID, story.ExternalKey, subtask.ExternalKey, subtask.Title,
+				subtask.Description, subtask.JiraURL, "", strconv.Itoa(subtask.Effort), subtask.PIC,
+				sheetTime(subtask.PlanStart), sheetTime(subtask.PlanEnd),
+				sheetOptionalTime(subtask.ActualStart), sheetOptionalTime(subtask.ActualEnd),
+				string(subtask.Status), string(subtask.Priority), strings.Join(s.labelNames(subtask.Labels), sheetListSeparator),
+				sheetChecklist(subtask.Checklist), "", "",
+				strconv.Itoa(subtask.CommentCount), strconv.FormatBool(subtask.Blocked), strings.Join(subtask.BlockedBy, sheetListSeparator),
+				sheetTime(subtask.CreatedAt), sheetTime(subtask.UpdatedAt),
+			}
+			rows = append(rows, append(row, sheetCustomValues(fields, subtask.CustomFields)...))
+		}
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = sheetSafe(cell)
+		}
+	}
+	return rows, nil
+}
+
+// ImportBacklogSheet creates or updates the stories and subtasks of a
+// backlog from spreadsheet rows, the first of which holds the column
+// headers. Every row is validated before anything is stored, and nothing is
+// stored when a row has errors or a preview is requested.
+func (s *Service) ImportBacklogSheet(backlogID string, rows [][]string, opts models.SheetImportOptions) (*models.SheetImportResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.backlogs[backlogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(backlogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+	if opts.Mode == "" {
+		opts.Mode = models.SheetUpsert
+	}
+	if opts.Mode != models.SheetUpsert && opts.Mode != models.SheetCreate {
+		return nil, fmt.Errorf("%w: mode must be upsert or create", ErrInvalidRequest)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no header row", ErrInvalidRequest)
+	}
+
+	result := &models.SheetImportResult{
+		Mode:    opts.Mode,
+		Preview: opts.Preview,
+		Columns: make(map[string]string),
+	}
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		name, mapped := opts.Mapping[header]
+		if !mapped {
+			name = strings.ToLower(strings.Join(strings.Fields(header), "_"))
+		}
+		key := strings.TrimPrefix(name, models.SortCustomFieldPrefix)
+		importable := sheetImportColumns[name] ||
+			(key != name && s.customFieldByKey(backlogID, key) != nil)
+		if !importable {
+			if header != "" {
+				result.Ignored = append(result.Ignored, header)
+			}
+			continue
+		}
+		if _, duplicate := columns[name]; duplicate {
+			return nil, fmt.Errorf("%w: more than one column maps to %q", ErrInvalidRequest, name)
+		}
+		columns[name] = i
+		result.Columns[header] = name
+	}
+	if _, exists := columns["title"]; !exists {
+		return nil, fmt.Errorf("%w: the file has no title column", ErrInvalidRequest)
+	}
+
+	// Story rows are planned first so subtask rows can refer to stories
+	// created by the same file
+	var storyRows, subtaskRows []*sheetRowPlan
+	byID := make(map[string]*sheetRowPlan)
+	byKey := make(map[string]*sheetRowPlan)
+	// keyRows remembers the first row of every external key, so a key used
+	// twice cannot create two items
+	keyRows := make(map[models.ItemType]map[string]int)
+	result.Rows = make([]models.SheetRowResult, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if sheetRowEmpty(row) {
+			continue
+		}
+		result.Rows = append(result.Rows, models.SheetRowResult{Row: i + 2})
+		plan := &sheetRowPlan{result: &result.Rows[len(result.Rows)-1], cells: row}
+
+		kind := models.ItemType(strings.ToLower(sheetCell(row, columns, "type")))
+		if kind == "" {
+			kind = models.ItemStory
+			if sheetCell(row, columns, "story_id") != "" || sheetCell(row, columns, "story_key") != "" {
+				kind = models.ItemSubTask
+			}
+		}
+		plan.result.Type = kind
+		if key := sheetCell(row, columns, "external_key"); key != "" && (kind == models.ItemStory || kind == models.ItemSubTask) {
+			if keyRows[kind] == nil {
+				keyRows[kind] = make(map[string]int)
+			}
+			if first, duplicate := keyRows[kind][key]; duplicate {
+				plan.result.Errors = append(plan.result.Errors, fmt.Sprintf("external key %q is already used on row %d", key, first))
+			} else {
+				keyRows[kind][key] = plan.result.Row
+			}
+		}
+		switch kind {
+		case models.ItemStory:
+			storyRows = append(storyRows, plan)
+			if id := sheetCell(row, columns, "id"); id != "" {
+				byID[id] = plan
+			}
+			if key := sheetCell(row, columns, "external_key"); key != "" && byKey[key] == nil {
+				byKey[key] = plan
+			}
+		case models.ItemSubTask:
+			subtaskRows = append(subtaskRows, plan)
+		default:
+			plan.result.Type = ""
+			plan.result.Errors = append(plan.result.Errors, fmt.Sprintf("unknown type %q", kind))
+		}
+	}
+
+	for _, plan := range storyRows {
+		s.planSheetStory(backlogID, plan, columns, opts.Mode)
+	}
+	for _, plan := range subtaskRows {
+		s.planSheetSubTask(backlogID, plan, columns, opts.Mode, byID, byKey)
+	}
+
+	for _, row := range result.Rows {
+		switch {
+		case len(row.Errors) > 0:
+			result.Failed++
+		case row.Action == models.ImportCreated:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+	if result.Failed > 0 {
+		if opts.Preview {
+			return result, nil
+		}
+		return result, fmt.Errorf("%w: %d row(s) have errors", ErrInvalidRequest, result.Failed)
+	}
+	if opts.Preview {
+		return result, nil
+	}
+
+	now := time.Now()
+	for _, plan := range storyRows {
+		var before *snapshot
+		if plan.story != nil {
+			before = s.snapshot(s.storyView(plan.story))
+		} else {
+			plan.story = &models.Story{
+				ID:          uuid.New().String(),
+				BacklogID:   backlogID,
+				ExternalKey: plan.key,
+				Status:      models.StatusTodo,
+				Priority:    models.PriorityMedium,
+				Labels:      []string{},
+				Checklist:   []models.ChecklistItem{},
+				SubTasks:    []models.SubTask{},
+				CreatedAt:   now,
+			}
+			s.stories[plan.story.ID] = plan.story
+		}
+		applyStoryUpdate(plan.story, plan.storyReq, plan.labels, plan.customFields)
+		if plan.status != "" {
+			plan.story.Status = plan.status
+		}
+		plan.result.ID = plan.story.ID
+		s.publishSaved(models.EventStoryCreated, models.EventStoryUpdated, before, s.snapshot(s.storyView(plan.story)))
+	}
+	for _, plan := range subtaskRows {
+		var before *snapshot
+		if plan.subtask != nil {
+			before = s.snapshot(s.subTaskView(plan.subtask))
+		} else {
+			plan.subtask = &models.SubTask{
+				ID:          uuid.New().String(),
+				ExternalKey: plan.key,
+				Status:      models.StatusTodo,
+				Priority:    models.PriorityMedium,
+				Labels:      []string{},
+				Checklist:   []models.ChecklistItem{},
+				CreatedAt:   now,
+			}
+			s.subtasks[plan.subtask.ID] = plan.subtask
+		}
+		if plan.parentRow != nil {
+			plan.parent = plan.parentRow.story
+		}
+		if plan.parent != nil {
+			plan.subtask.StoryID = plan.parent.ID
+		}
+		applySubTaskUpdate(plan.subtask, plan.subtaskReq, plan.labels, plan.customFields)
+		if plan.status != "" {
+			plan.subtask.Status = plan.status
+		}
+		plan.result.ID = plan.subtask.ID
+		s.publishSaved(models.EventSubTaskCreated, models.EventSubTaskUpdated, before, s.snapshot(s.subTaskView(plan.subtask)))
+	}
+
+	return result, nil
+}
+
+// sheetFields are the values of the importable columns of a row. A column
+// present in the file sets its field, so an empty cell clears it; only
+// status and priority keep their value when left empty.
+type sheetFields struct {
+	title, description, jiraURL, pic *string
+	effortOrigin, effort             *int
+	planStart, planEnd               *time.Time
+	priority                         *models.Priority
+	labels                           *[]string
+	status                           models.Status
+	custom                           models.CustomValues
+}
+
+// planSheetStory validates a story row and decides whether it creates or
+// updates a story. Callers must hold the lock.
+func (s *Service) planSheetStory(backlogID string, plan *sheetRowPlan, columns map[string]int, mode string) {
+	fail := func(err error) { plan.result.Errors = append(plan.result.Errors, sheetError(err)) }
+
+	plan.key = sheetCell(plan.cells, columns, "external_key")
+	if mode == models.SheetUpsert {
+		if id := sheetCell(plan.cells, columns, "id"); id != "" {
+			if story, exists := s.stories[id]; exists {
+				if story.BacklogID != backlogID {
+					fail(fmt.Errorf("story %s belongs to another backlog", id))
+				}
+				plan.story = story
+			}
+		}
+		if plan.story == nil && plan.key != "" {
+			plan.story = s.storyByExternalKey(backlogID, plan.key)
+		}
+	} else if plan.key != "" && s.storyByExternalKey(backlogID, plan.key) != nil {
+		fail(fmt.Errorf("external key %q is already used in this backlog", plan.key))
+	}
+
+	fields, errs := parseSheetFields(plan.cells, columns)
+	for _, err := range errs {
+		fail(err)
+	}
+	if fields.title != nil && strings.TrimSpace(*fields.title) == "" {
+		fail(errors.New("title is required"))
+	}
+	plan.storyReq = models.UpdateStoryRequest{
+		Title:        fields.title,
+		Description:  fields.description,
+		JiraURL:      fields.jiraURL,
+		EffortOrigin: fields.effortOrigin,
+		PIC:          fields.pic,
+		PlanStart:    fields.planStart,
+		PlanEnd:      fields.planEnd,
+		Priority:     fields.priority,
+		Labels:       fields.labels,
+	}
+	if plan.storyReq.EffortOrigin == nil {
+		// Sheets with a single effort column use it for stories too
+		plan.storyReq.EffortOrigin = fields.effort
+	}
+	plan.status = fields.status
+
+	var current models.CustomValues
+	if plan.story != nil {
+		current = plan.story.CustomFields
+	}
+	s.planSheetValues(backlogID, plan, fields, current, plan.story == nil)
+
+	plan.result.Action = models.ImportCreated
+	if plan.story != nil {
+		plan.result.Action = models.ImportUpdated
+		plan.result.ID = plan.story.ID
+	}
+}
+
+// planSheetSubTask validates a subtask row, finds its story and decides
+// whether it creates or updates a subtask. Callers must hold the lock.
+func (s *Service) planSheetSubTask(backlogID string, plan *sheetRowPlan, columns map[string]int, mode string, byID, byKey map[string]*sheetRowPlan) {
+	fail := func(err error) { plan.result.Errors = append(plan.result.Errors, sheetError(err)) }
+
+	plan.key = sheetCell(plan.cells, columns, "external_key")
+	if mode == models.SheetUpsert {
+		if id := sheetCell(plan.cells, columns, "id"); id != "" {
+			if subtask, exists := s.subtasks[id]; exists {
+				if s.backlogOfSubTask(subtask) != backlogID {
+					fail(fmt.Errorf("subtask %s belongs to another backlog", id))
+				}
+				plan.subtask = subtask
+			}
+		}
+		if plan.subtask == nil && plan.key != "" {
+			plan.subtask = s.subTaskByExternalKey(backlogID, plan.key)
+		}
+	} else if plan.key != "" && s.subTaskByExternalKey(backlogID, plan.key) != nil {
+		fail(fmt.Errorf("external key %q is already used in this backlog", plan.key))
+	}
+
+	// The story may be a row of the same file or a story of the backlog
+	if storyID := sheetCell(plan.cells, columns, "story_id"); storyID != "" {
+		if row, exists := byID[storyID]; exists {
+			plan.parentRow = row
+		} else if story, exists := s.stories[storyID]; exists && story.BacklogID == backlogID {
+			plan.parent = story
+		} else {
+			fail(fmt.Errorf("story %s not found in this backlog", storyID))
+		}
+	} else if storyKey := sheetCell(plan.cells, columns, "story_key"); storyKey != "" {
+		if row, exists := byKey[storyKey]; exists {
+			plan.parentRow = row
+		} else if story := s.storyByExternalKey(backlogID, storyKey); story != nil {
+			plan.parent = story
+		} else {
+			fail(fmt.Errorf("story with external key %q not found in this backlog", storyKey))
+		}
+	} else if plan.subtask == nil {
+		fail(errors.New("story_id or story_key is required"))
+	}
+
+	fields, errs := parseSheetFields(plan.cells, columns)
+	for _, err := range errs {
+		fail(err)
+	}
+	if fields.title != nil && strings.TrimSpace(*fields.title) == "" {
+		fail(errors.New("title is required"))
+	}
+	plan.subtaskReq = models.UpdateSubTaskRequest{
+		Title:       fields.title,
+		Description: fields.description,
+		Effort:      fields.effort,
+		JiraURL:     fields.jiraURL,
+		PIC:         fields.pic,
+		PlanStart:   fields.planStart,
+		PlanEnd:     fields.planEnd,
+		Priority:    fields.priority,
+		Labels:      fields.labels,
+	}
+	plan.status = fields.status
+
+	var current models.CustomValues
+	if plan.subtask != nil {
+		current = plan.subtask.CustomFields
+	}
+	s.planSheetValues(backlogID, plan, fields, current, plan.subtask == nil)
+
+	plan.result.Action = models.ImportCreated
+	if plan.subtask != nil {
+		plan.result.Action = models.ImportUpdated
+		plan.result.ID = plan.subtask.ID
+	}
+}
+
+// planSheetValues resolves the labels and custom values of a row. Callers
+// must hold the lock.
+func (s *Service) planSheetValues(backlogID string, plan *sheetRowPlan, fields sheetFields, current models.CustomValues, creating bool) {
+	if fields.labels != nil {
+		labels, err := s.resolveLabels(backlogID, *fields.labels)
+		if err != nil {
+			plan.result.Errors = append(plan.result.Errors, sheetError(err))
+		}
+		plan.labels = labels
+	}
+	customFields, err := s.applyCustomValues(backlogID, current, fields.custom, creating)
+	if err != nil {
+		plan.result.Errors = append(plan.result.Errors, sheetError(err))
+	}
+	plan.customFields = customFields
+}
+
+// sortedCustomFields returns the custom fields of a backlog ordered by key.
+// Callers must hold the lock.
+func (s *Service) sortedCustomFields(backlogID string) []*models.CustomField {
+	var fields []*models.CustomField
+	for _, field := range s.customFields {
+		if s.fieldApplies(field, backlogID) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// parseSheetFields reads the importable columns of a row
+func parseSheetFields(row []string, columns map[string]int) (sheetFields, []error) {
+	var fields sheetFields
+	var errs []error
+	text := func(name string) *string {
+		if _, exists := columns[name]; !exists {
+			return nil
+		}
+		value := sheetCell(row, columns, name)
+		return &value
+	}
+	number := func(name string) *int {
+		value := text(name)
+		if value == nil {
+			return nil
+		}
+		n, err := parseSheetInt(*value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a whole number", name))
+		}
+		return &n
+	}
+	date := func(name string) *time.Time {
+		value := text(name)
+		if value == nil {
+			return nil
+		}
+		t, err := parseSheetTime(*value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a date", name))
+		}
+		return &t
+	}
+
+	fields.title = text("title")
+	fields.description = text("description")
+	fields.jiraURL = text("jira_url")
+	fields.pic = text("pic")
+	fields.effortOrigin = number("effort_origin")
+	fields.effort = number("effort")
+	fields.planStart = date("plan_start")
+	fields.planEnd = date("plan_end")
+
+	if value := text("priority"); value != nil && *value != "" {
+		priority := models.Priority(strings.ToUpper(*value))
+		if !priority.IsValid() {
+			errs = append(errs, fmt.Errorf("unknown priority %q", *value))
+		}
+		fields.priority = &priority
+	}
+	if value := text("status"); value != nil && *value != "" {
+		fields.status = models.Status(strings.ToUpper(strings.Join(strings.Fields(*value), "_")))
+		if !fields.status.IsValid() {
+			errs = append(errs, fmt.Errorf("unknown status %q", *value))
+		}
+	}
+	if value := text("labels"); value != nil {
+		labels := []string{}
+		for _, name := range strings.Split(*value, ";") {
+			if name = strings.TrimSpace(name); name != "" {
+				labels = append(labels, name)
+			}
+		}
+		fields.labels = &labels
+	}
+	for name := range columns {
+		if key := strings.TrimPrefix(name, models.SortCustomFieldPrefix); key != name {
+			if fields.custom == nil {
+				fields.custom = models.CustomValues{}
+			}
+			fields.custom[key] = nil
+			if value := sheetCell(row, columns, name); value != "" {
+				fields.custom[key] = value
+			}
+		}
+	}
+	return fields, errs
+}
+
+// sheetCell returns the trimmed cell of a named column, or an empty string
+// when the column is missing or the row is short. The quote sheetSafe adds
+// on export is removed.
+func sheetCell(row []string, columns map[string]int, name string) string {
+	i, exists := columns[name]
+	if !exists || i >= len(row) {
+		return ""
+	}
+	cell := strings.TrimSpace(row[i])
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(sheetFormulaPrefixes, rune(cell[1])) {
+		cell = cell[1:]
+	}
+	return cell
+}
+
+// sheetFormulaPrefixes are the characters that make spreadsheet
+// applications read a cell as a formula
+const sheetFormulaPrefixes = "=+-@\t\r"
+
+// sheetSafe quotes a cell that would be read as a formula, so exported
+// text such as a title starting with "=" cannot run in the application
+// opening the file
+func sheetSafe(cell string) string {
+	if cell != "" && strings.ContainsRune(sheetFormulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func sheetRowEmpty(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sheetError turns a service error into a row error message
+func sheetError(err error) string {
+	return strings.TrimPrefix(err.Error(), ErrInvalidRequest.Error()+": ")
+}
+
+func sheetTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func sheetOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return sheetTime(*t)
+}
+
+func sheetOptionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// sheetChecklist renders a checklist with one "[x] text" line per item
+func sheetChecklist(checklist []models.ChecklistItem) string {
+	lines := make([]string, 0, len(checklist))
+	for _, item := range checklist {
+		mark := "[ ]"
+		if item.Checked {
+			mark = "[x]"
+		}
+		lines = append(lines, mark+" "+item.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sheetCustomValues renders custom values in field order
+func sheetCustomValues(fields []*models.CustomField, values models.CustomValues) []string {
+	cells := make([]string, 0, len(fields))
+	for _, field := range fields {
+		cells = append(cells, formatCustomValue(values[field.Key]))
+	}
+	return cells
+}
+
+// parseSheetInt reads a whole number, accepting the "3.0" form spreadsheet
+// applications write for numeric cells
+func parseSheetInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != math.Trunc(f) {
+		return 0, fmt.Errorf("not a whole number: %q", value)
+	}
+	return int(f), nil
+}
+
+// excelEpoch is day zero of the serial dates spreadsheet applications use
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// parseSheetTime reads a date written as RFC 3339, as YYYY-MM-DD with an
+// optional time, or as a spreadsheet serial date
+func parseSheetTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", models.DateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		days := math.Floor(serial)
+		seconds := math.Round((serial - days) * 24 * 60 * 60)
+		return excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("not a date: %q", value)
+}
@@ -0,0 +1,648 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template operations
+func (s *Service) CreateTemplate(req models.CreateTemplateRequest) (*models.StoryTemplate, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireManager(); err != nil {
+		return nil, err
+	}
+	if err := validateTemplate(req); err != nil {
+		return nil, err
+	}
+
+	template := &models.StoryTemplate{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Story:       req.Story,
+		SubTasks:    req.SubTasks,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if template.SubTasks == nil {
+		template.SubTasks = []models.TemplateSubTask{}
+	}
+
+	s.templates[template.ID] = template
+	s.publish(models.EventTemplateCreated, nil, s.snapshot(template))
+	return template, nil
+}
+
+func (s *Service) GetTemplate(id string) (*models.StoryTemplate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	template, exists := s.templates[id]
+	if !exists || template.WorkspaceID != s.workspace() {
+		return nil, errors.New("template not found")
+	}
+
+	return template, nil
+}
+
+func (s *Service) GetAllTemplates() ([]*models.StoryTemplate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	templates := []*models.StoryTemplate{}
+	for _, template := range s.templates {
+		if template.WorkspaceID == s.workspace() {
+			templates = append(templates, template)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	return templates, nil
+}
+
+func (s *Service) DeleteTemplate(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireManager(); err != nil {
+		return err
+	}
+	template, exists := s.templates[id]
+	if !exists || template.WorkspaceID != s.workspace() {
+		return errors.New("template not found")
+	}
+
+	deleted := s.snapshot(template)
+	delete(s.templates, id)
+	s.publish(models.EventTemplateDeleted, deleted, nil)
+	return nil
+}
+
+// CreateTemplateFromStory captures an existing story and its subtasks as a
+// template. Subtask plan dates become offsets from the story plan start.
+func (s *Service) CreateTemplateFromStory(storyID string, req models.CreateTemplateFromStoryRequest) (*models.StoryTemplate, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	story, exists := s.stories[storyID]
+	if !exists {
+		return nil, errors.New("story not found")
+	}
+	if err := s.authorize(story.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
+	if err := s.requireManager(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+
+	subtasks := s.sortedSubTasks(storyID)
+	origin := story.PlanStart
+	if origin.IsZero() {
+		origin = earliestPlanStart(nil, subtasks)
+	}
+
+	template := &models.StoryTemplate{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Story: models.TemplateStory{
+			Title:        story.Title,
+			Description:  story.Description,
+			EffortOrigin: story.EffortOrigin,
+			PIC:          story.PIC,
+			Priority:     story.Priority,
+			Labels:       s.labelNames(story.Labels),
+			DurationDays: planDays(story.PlanStart, story.PlanEnd),
+			Checklist:    templateChecklist(story.Checklist),
+		},
+		SubTasks:  []models.TemplateSubTask{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	for _, subtask := range subtasks {
+		offset := 0
+		if !origin.IsZero() && !subtask.PlanStart.IsZero() {
+			offset = planDays(origin, subtask.PlanStart)
+		}
+		template.SubTasks = append(template.SubTasks, models.TemplateSubTask{
+			Title:        subtask.Title,
+			Description:  subtask.Description,
+			Effort:       subtask.Effort,
+			PIC:          subtask.PIC,
+			Priority:     subtask.Priority,
+			Labels:       s.labelNames(subtask.Labels),
+			OffsetDays:   offset,
+			DurationDays: planDays(subtask.PlanStart, subtask.PlanEnd),
+			Checklist:    templateChecklist(subtask.Checklist),
+		})
+	}
+
+	s.templates[template.ID] = template
+	s.publish(models.EventTemplateCreated, nil, s.snapshot(template))
+	return template, nil
+}
+
+// InstantiateTemplate creates a story and its subtasks in a backlog from a
+// template, planned relative to the given start date. Template labels that
+// do not exist in the backlog are skipped; the given custom values apply to
+// the story and every subtask.
+func (s *Service) InstantiateTemplate(id string, req models.InstantiateTemplateRequest) (*models.Story, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	template, exists := s.templates[id]
+	if !exists || template.WorkspaceID != s.workspace() {
+		return nil, errors.New("template not found")
+	}
+	if _, exists := s.backlogs[req.BacklogID]; !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(req.BacklogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+	if req.StartDate.IsZero() {
+		return nil, fmt.Errorf("%w: start_date is required", ErrInvalidRequest)
+	}
+	customFields, err := s.applyCustomValues(req.BacklogID, nil, req.CustomFields, true)
+	if err != nil {
+		return nil, err
+	}
+
+	start := startOfDay(req.StartDate)
+	now := time.Now()
+	story := &models.Story{
+		ID:           uuid.New().String(),
+		BacklogID:    req.BacklogID,
+		Title:        template.Story.Title,
+		Description:  template.Story.Description,
+		EffortOrigin: template.Story.EffortOrigin,
+		PIC:          template.Story.PIC,
+		PlanStart:    start,
+		PlanEnd:      start.AddDate(0, 0, template.Story.DurationDays),
+		Status:       models.StatusTodo,
+		Priority:     templatePriority(template.Story.Priority),
+		Labels:       s.labelIDs(req.BacklogID, template.Story.Labels),
+		CustomFields: customFields,
+		Checklist:    newChecklist(template.Story.Checklist, now),
+		SubTasks:     []models.SubTask{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.stories[story.ID] = story
+	s.publish(models.EventStoryCreated, nil, s.snapshot(s.storyView(story)))
+
+	subtasks := []models.SubTask{}
+	for _, item := range template.SubTasks {
+		subtaskStart := start.AddDate(0, 0, item.OffsetDays)
+		subtask := &models.SubTask{
+			ID:           uuid.New().String(),
+			StoryID:      story.ID,
+			Title:        item.Title,
+			Description:  item.Description,
+			Effort:       item.Effort,
+			PIC:          item.PIC,
+			PlanStart:    subtaskStart,
+			PlanEnd:      subtaskStart.AddDate(0, 0, item.DurationDays),
+			Status:       models.StatusTodo,
+			Priority:     templatePriority(item.Priority),
+			Labels:       s.labelIDs(req.BacklogID, item.Labels),
+			CustomFields: s.cloneCustomValues(customFields, req.BacklogID, req.BacklogID),
+			Checklist:    newChecklist(item.Checklist, now),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		s.subtasks[subtask.ID] = subtask
+		subtasks = append(subtasks, s.subTaskView(subtask))
+		s.publish(models.EventSubTaskCreated, nil, s.snapshot(subtasks[len(subtasks)-1]))
+	}
+
+	storyCopy := s.storyView(story)
+	storyCopy.SubTasks = subtasks
+	return &storyCopy, nil
+}
+
+// Clone operations
+
+// CloneStory deep-copies a story with its subtasks, checklists and the links
+// between its subtasks. The copy starts over in TODO with its checklists
+// unchecked; comments and attachments are not copied. When the copy goes to
+// another backlog, labels are matched by name and custom values are kept
+// only where the target backlog has a matching field.
+func (s *Service) CloneStory(id string, req models.CloneStoryRequest) (*models.Story, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	story, exists := s.stories[id]
+	if !exists {
+		return nil, errors.New("story not found")
+	}
+	backlogID := story.BacklogID
+	if req.BacklogID != "" {
+		if _, exists := s.backlogs[req.BacklogID]; !exists {
+			return nil, errors.New("backlog not found")
+		}
+		backlogID = req.BacklogID
+	}
+	if err := s.authorize(story.BacklogID, models.PermView); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(backlogID, models.PermEdit); err != nil {
+		return nil, err
+	}
+
+	subtasks := s.sortedSubTasks(id)
+	var shift int
+	if req.StartDate != nil {
+		if origin := earliestPlanStart([]*models.Story{story}, subtasks); !origin.IsZero() {
+			shift = daysBetween(origin, *req.StartDate)
+		}
+	}
+
+	clone := s.cloneStory(story, backlogID, shift, nil)
+	subtaskIDs := make(map[string]string)
+	views := []models.SubTask{}
+	for _, subtask := range subtasks {
+		subtaskClone := s.cloneSubTask(subtask, clone, shift, nil)
+		subtaskIDs[subtask.ID] = subtaskClone.ID
+		views = append(views, s.subTaskView(subtaskClone))
+	}
+	s.cloneLinks(models.ItemSubTask, subtaskIDs)
+
+	storyCopy := s.storyView(clone)
+	storyCopy.SubTasks = views
+	return &storyCopy, nil
+}
+
+// CloneBacklog deep-copies a backlog with its labels, custom fields,
+// stories, subtasks, checklists and the links inside it. Comments,
+// attachments, baselines and epic membership are not copied.
+func (s *Service) CloneBacklog(id string, req models.CloneBacklogRequest) (*models.Backlog, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	backlog, exists := s.backlogs[id]
+	if !exists {
+		return nil, errors.New("backlog not found")
+	}
+	if err := s.authorize(id, models.PermView); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	clone := &models.Backlog{
+		ID:          uuid.New().String(),
+		WorkspaceID: backlog.WorkspaceID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: backlog.Description,
+		WIPLimits:   models.WIPLimits{},
+		Stories:     []models.Story{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if clone.Title == "" {
+		clone.Title = backlog.Title + " (copy)"
+	}
+	for status, limit := range backlog.WIPLimits {
+		clone.WIPLimits[status] = limit
+	}
+	s.backlogs[clone.ID] = clone
+	s.publish(models.EventBacklogCreated, nil, s.snapshot(clone))
+	s.grantOwner(clone.ID)
+
+	labelIDs := make(map[string]string)
+	for _, label := range s.labels {
+		if label.BacklogID != id {
+			continue
+		}
+		labelClone := *label
+		labelClone.ID = uuid.New().String()
+		labelClone.BacklogID = clone.ID
+		labelClone.CreatedAt = now
+		labelClone.UpdatedAt = now
+		s.labels[labelClone.ID] = &labelClone
+		labelIDs[label.ID] = labelClone.ID
+		s.publish(models.EventLabelCreated, nil, s.snapshot(&labelClone))
+	}
+	for _, field := range s.customFields {
+		if field.BacklogID != id {
+			continue
+		}
+		fieldClone := *field
+		fieldClone.ID = uuid.New().String()
+		fieldClone.BacklogID = clone.ID
+		fieldClone.Options = append([]string(nil), field.Options...)
+		fieldClone.CreatedAt = now
+		fieldClone.UpdatedAt = now
+		s.customFields[fieldClone.ID] = &fieldClone
+		s.publish(models.EventCustomFieldCreated, nil, s.snapshot(&fieldClone))
+	}
+
+	stories := s.sortedStories(id)
+	var subtasks []*models.SubTask
+	for _, story := range stories {
+		subtasks = append(subtasks, s.sortedSubTasks(story.ID)...)
+	}
+	var shift int
+	if req.StartDate != nil {
+		if origin := earliestPlanStart(stories, subtasks); !origin.IsZero() {
+			shift = daysBetween(origin, *req.StartDate)
+		}
+	}
+
+	storyIDs := make(map[string]string)
+	subtaskIDs := make(map[string]string)
+	for _, story := range stories {
+		storyClone := s.cloneStory(story, clone.ID, shift, labelIDs)
+		storyIDs[story.ID] = storyClone.ID
+		for _, subtask := range s.sortedSubTasks(story.ID) {
+			subtaskIDs[subtask.ID] = s.cloneSubTask(subtask, storyClone, shift, labelIDs).ID
+		}
+	}
+	s.cloneLinks(models.ItemStory, storyIDs)
+	s.cloneLinks(models.ItemSubTask, subtaskIDs)
+
+	return s.backlogView(clone), nil
+}
+
+// cloneStory stores a fresh copy of a story in the given backlog. Labels are
+// translated through labelIDs when it is set and matched by name otherwise.
+// The copy is not linked to the Jira issue of the story, which only one
+// item may sync with. Callers must hold the lock.
+func (s *Service) cloneStory(story *models.Story, backlogID string, shift int, labelIDs map[string]string) *models.Story {
+	now := time.Now()
+	clone := &models.Story{
+		ID:           uuid.New().String(),
+		BacklogID:    backlogID,
+		Title:        story.Title,
+		Description:  story.Description,
+		EffortOrigin: story.EffortOrigin,
+		PIC:          story.PIC,
+		PlanStart:    shiftPlan(story.PlanStart, shift),
+		PlanEnd:      shiftPlan(story.PlanEnd, shift),
+		Status:       models.StatusTodo,
+		Priority:     story.Priority,
+		Labels:       s.cloneLabels(story.Labels, story.BacklogID, backlogID, labelIDs),
+		CustomFields: s.cloneCustomValues(story.CustomFields, story.BacklogID, backlogID),
+		Checklist:    cloneChecklist(story.Checklist, now),
+		SubTasks:     []models.SubTask{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.stories[clone.ID] = clone
+	s.publish(models.EventStoryCreated, nil, s.snapshot(s.storyView(clone)))
+	return clone
+}
+
+// cloneSubTask stores a fresh copy of a subtask under the given story,
+// without its Jira issue. Callers must hold the lock.
+func (s *Service) cloneSubTask(subtask *models.SubTask, story *models.Story, shift int, labelIDs map[string]string) *models.SubTask {
+	now := time.Now()
+	fromBacklog := s.backlogOfSubTask(subtask)
+	clone := &models.SubTask{
+		ID:           uuid.New().String(),
+		StoryID:      story.ID,
+		Title:        subtask.Title,
+		Description:  subtask.Description,
+		Effort:       subtask.Effort,
+		PIC:          subtask.PIC,
+		PlanStart:    shiftPlan(subtask.PlanStart, shift),
+		PlanEnd:      shiftPlan(subtask.PlanEnd, shift),
+		Status:       models.StatusTodo,
+		Priority:     subtask.Priority,
+		Labels:       s.cloneLabels(subtask.Labels, fromBacklog, story.BacklogID, labelIDs),
+		CustomFields: s.cloneCustomValues(subtask.CustomFields, fromBacklog, story.BacklogID),
+		Checklist:    cloneChecklist(subtask.Checklist, now),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.subtasks[clone.ID] = clone
+	s.publish(models.EventSubTaskCreated, nil, s.snapshot(s.subTaskView(clone)))
+	return clone
+}
+
+// cloneLinks copies the links whose both ends were cloned, pointing the
+// copies at the clones. Callers must hold the lock.
+func (s *Service) cloneLinks(itemType models.ItemType, ids map[string]string) {
+	var copies []*models.Link
+	for _, link := range s.links {
+		source, sourceCloned := ids[link.SourceID]
+		target, targetCloned := ids[link.TargetID]
+		if link.ItemType != itemType || !sourceCloned || !targetCloned {
+			continue
+		}
+		copies = append(copies, &models.Link{
+			ID:        uuid.New().String(),
+			ItemType:  itemType,
+			SourceID:  source,
+			TargetID:  target,
+			Type:      link.Type,
+			CreatedAt: time.Now(),
+		})
+	}
+	for _, link := range copies {
+		s.links[link.ID] = link
+		s.publish(models.EventLinkCreated, nil, s.snapshot(link))
+	}
+}
+
+// cloneLabels translates label IDs from one backlog to another. Callers
+// must hold the lock.
+func (s *Service) cloneLabels(labels []string, fromBacklog, toBacklog string, labelIDs map[string]string) []string {
+	if labelIDs == nil && fromBacklog == toBacklog {
+		return append([]string{}, labels...)
+	}
+	ids := []string{}
+	for _, id := range labels {
+		if labelIDs != nil {
+			if cloneID, exists := labelIDs[id]; exists {
+				ids = append(ids, cloneID)
+			}
+			continue
+		}
+		if label, exists := s.labels[id]; exists {
+			if target := s.labelByName(toBacklog, label.Name); target != nil {
+				ids = addMembers(ids, []string{target.ID})
+			}
+		}
+	}
+	return ids
+}
+
+// cloneCustomValues copies custom values into another backlog, keeping
+// only those the target backlog has a field for and accepts. Callers must
+// hold the lock.
+func (s *Service) cloneCustomValues(values models.CustomValues, fromBacklog, toBacklog string) models.CustomValues {
+	result := models.CustomValues{}
+	for key, value := range values {
+		if fromBacklog == toBacklog {
+			result[key] = value
+			continue
+		}
+		field := s.customFieldByKey(toBacklog, key)
+		if field == nil {
+			continue
+		}
+		if normalized, err := s.normalizeCustomValue(field, value); err == nil {
+			result[key] = normalized
+		}
+	}
+	return result
+}
+
+// labelNames returns the names of the given labels. Callers must hold the
+// lock.
+func (s *Service) labelNames(ids []string) []string {
+	names := []string{}
+	for _, id := range ids {
+		if label, exists := s.labels[id]; exists {
+			names = append(names, label.Name)
+		}
+	}
+	return names
+}
+
+// labelIDs resolves label names in a backlog, skipping unknown names.
+// Callers must hold the lock.
+func (s *Service) labelIDs(backlogID string, names []string) []string {
+	ids := []string{}
+	for _, name := range names {
+		if label := s.labelByName(backlogID, name); label != nil {
+			ids = addMembers(ids, []string{label.ID})
+		}
+	}
+	return ids
+}
+
+// validateTemplate checks a template before it is stored
+func validateTemplate(req models.CreateTemplateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if strings.TrimSpace(req.Story.Title) == "" {
+		return fmt.Errorf("%w: story title is required", ErrInvalidRequest)
+	}
+	if req.Story.Priority != "" && !req.Story.Priority.IsValid() {
+		return fmt.Errorf("%w: unknown priority %q", ErrInvalidRequest, req.Story.Priority)
+	}
+	if req.Story.DurationDays < 0 {
+		return fmt.Errorf("%w: duration_days cannot be negative", ErrInvalidRequest)
+	}
+	if err := validateTemplateChecklist(req.Story.Checklist); err != nil {
+		return err
+	}
+	for i, subtask := range req.SubTasks {
+		if strings.TrimSpace(subtask.Title) == "" {
+			return fmt.Errorf("%w: subtask %d: title is required", ErrInvalidRequest, i+1)
+		}
+		if subtask.Priority != "" && !subtask.Priority.IsValid() {
+			return fmt.Errorf("%w: subtask %d: unknown priority %q", ErrInvalidRequest, i+1, subtask.Priority)
+		}
+		if subtask.OffsetDays < 0 || subtask.DurationDays < 0 {
+			return fmt.Errorf("%w: subtask %d: offset_days and duration_days cannot be negative", ErrInvalidRequest, i+1)
+		}
+		if err := validateTemplateChecklist(subtask.Checklist); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateTemplateChecklist(checklist []models.TemplateCheckItem) error {
+	for _, item := range checklist {
+		if strings.TrimSpace(item.Text) == "" {
+			return fmt.Errorf("%w: checklist text is required", ErrInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// templatePriority returns the priority of an item created from a template
+func templatePriority(priority models.Priority) models.Priority {
+	if priority == "" {
+		return models.PriorityMedium
+	}
+	return priority
+}
+
+// templateChecklist captures a checklist without its check state
+func templateChecklist(checklist []models.ChecklistItem) []models.TemplateCheckItem {
+	items := []models.TemplateCheckItem{}
+	for _, item := range checklist {
+		items = append(items, models.TemplateCheckItem{Text: item.Text, Required: item.Required})
+	}
+	return items
+}
+
+// newChecklist creates unchecked checklist items from a template
+func newChecklist(items []models.TemplateCheckItem, now time.Time) []models.ChecklistItem {
+	checklist := []models.ChecklistItem{}
+	for _, item := range items {
+		checklist = append(checklist, models.ChecklistItem{
+			ID:        uuid.New().String(),
+			Text:      item.Text,
+			Required:  item.Required,
+			CreatedAt: now,
+		})
+	}
+	return checklist
+}
+
+// cloneChecklist copies a checklist with every item unchecked
+func cloneChecklist(checklist []models.ChecklistItem, now time.Time) []models.ChecklistItem {
+	return newChecklist(templateChecklist(checklist), now)
+}
+
+// earliestPlanStart returns the earliest plan start among the given items,
+// or the zero time when none is planned
+func earliestPlanStart(stories []*models.Story, subtasks []*models.SubTask) time.Time {
+	var earliest time.Time
+	consider := func(t time.Time) {
+		if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	for _, story := range stories {
+		consider(story.PlanStart)
+	}
+	for _, subtask := range subtasks {
+		consider(subtask.PlanStart)
+	}
+	return earliest
+}
+
+// planDays returns the whole days from one plan date to a later one, or
+// zero when either is unset
+func planDays(from, to time.Time) int {
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return 0
+	}
+	return daysBetween(from, to)
+}
+
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(startOfDay(to).Sub(startOfDay(from)).Hours() / 24))
+}
+
+// shiftPlan moves a plan date by a number of days, leaving unset dates
+// alone
+func shiftPlan(t time.Time, days int) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.AddDate(0, 0, days)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"encoding/json"
+	"golang-baseline/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopiesWithoutSubTasksListNone(t *testing.T) {
+	s := newTestService(t, nil)
+	backlog, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Backlog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := s.CreateTemplate(models.CreateTemplateRequest{Name: "Template", Story: models.TemplateStory{Title: "Story"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	story, err := s.InstantiateTemplate(template.ID, models.InstantiateTemplateRequest{BacklogID: backlog.ID, StartDate: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := s.CloneStory(story.ID, models.CloneStoryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty list is sent rather than null
+	for _, copy := range []*models.Story{story, clone} {
+		body, _ := json.Marshal(copy)
+		if copy.SubTasks == nil || !strings.Contains(string(body), `"subtasks":[]`) {
+			t.Errorf("story = %s", body)
+		}
+	}
+}
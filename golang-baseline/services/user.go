@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/auth"
+	"golang-baseline/models"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// User operations
+func (s *Service) CreateUser(req models.CreateUserRequest) (*models.User, error) {
+	// Hashing a password takes a while, so it is done before locking
+	var passwordHash string
+	if req.Password != "" {
+		if len(req.Password) < minPasswordLength {
+			return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidRequest, minPasswordLength)
+		}
+		var err error
+		if passwordHash, err = auth.HashPassword(req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	if !usernamePattern.MatchString(req.Username) {
+		return nil, fmt.Errorf("%w: username may only contain letters, digits, '.', '_' and '-'", ErrInvalidRequest)
+	}
+	if s.userByUsername(req.Username) != nil {
+		return nil, fmt.Errorf("%w: username %q is taken", ErrInvalidRequest, req.Username)
+	}
+
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		displayName = req.Username
+	}
+
+	user := &models.User{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		Username:    req.Username,
+		DisplayName: displayName,
+		Email:       req.Email,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+
+		Admin: req.Admin,
+
+		PasswordHash: passwordHash,
+	}
+
+	s.users[user.ID] = user
+	s.publish(models.EventUserCreated, nil, s.snapshot(user))
+	return userView(user), nil
+}
+
+func (s *Service) GetUser(id string) (*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, exists := s.users[id]
+	if !exists || user.WorkspaceID != s.workspace() {
+		return nil, errors.New("user not found")
+	}
+
+	return userView(user), nil
+}
+
+func (s *Service) GetAllUsers() ([]*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	users := []*models.User{}
+	for _, user := range s.users {
+		if user.WorkspaceID == s.workspace() {
+			users = append(users, userView(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return users, nil
+}
+
+// userView returns a copy of a user, which logins may update while the
+// copy is encoded
+func userView(user *models.User) *models.User {
+	view := *user
+	view.Roles = slices.Clone(user.Roles)
+	return &view
+}
+
+// userByUsername finds a user by username, ignoring case. Usernames are
+// unique across workspaces. Callers must hold the lock.
+func (s *Service) userByUsername(username string) *models.User {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Username, username) {
+			return user
+		}
+	}
+	return nil
+}
+
+// memberByUsername finds a user of the request's workspace by username,
+// ignoring case. Callers must hold the lock.
+func (s *Service) memberByUsername(username string) *models.User {
+	if user := s.userByUsername(username); user != nil && user.WorkspaceID == s.workspace() {
+		return user
+	}
+	return nil
+}
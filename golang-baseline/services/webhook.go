@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-baseline/events"
+	"golang-baseline/models"
+	"golang-baseline/webhook"
+	"net"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhookResolveTimeout bounds the lookup of the host of a webhook URL
+const webhookResolveTimeout = 5 * time.Second
+
+// Webhook operations
+func (s *Service) CreateWebhook(req models.CreateWebhookRequest) (*models.Webhook, error) {
+	s.mutex.RLock()
+	err := s.requireAdmin()
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	// The host is resolved without holding the lock
+	if err := s.validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The admin role may have been revoked while the host was resolved
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	events, err := validateWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	hook := &models.Webhook{
+		ID:          uuid.New().String(),
+		WorkspaceID: s.workspace(),
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Active:      req.Active == nil || *req.Active,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	s.webhooks[hook.ID] = hook
+	s.publish(models.EventWebhookCreated, nil, s.snapshot(webhookView(hook)))
+
+	// The secret is shown once, so the caller can configure the receiver
+	hookCopy := *hook
+	return &hookCopy, nil
+}
+
+func (s *Service) GetWebhook(id string) (*models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	hook, exists := s.webhooks[id]
+	if !exists || hook.WorkspaceID != s.workspace() {
+		return nil, errors.New("webhook not found")
+	}
+	return webhookView(hook), nil
+}
+
+func (s *Service) GetAllWebhooks() ([]*models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	hooks := []*models.Webhook{}
+	for _, hook := range s.webhooks {
+		if hook.WorkspaceID == s.workspace() {
+			hooks = append(hooks, webhookView(hook))
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.Before(hooks[j].CreatedAt) })
+	return hooks, nil
+}
+
+func (s *Service) UpdateWebhook(id string, req models.UpdateWebhookRequest) (*models.Webhook, error) {
+	s.mutex.RLock()
+	err := s.requireAdmin()
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	// The host is resolved without holding the lock
+	if req.URL != nil {
+		if err := s.validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The admin role may have been revoked while the host was resolved
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	hook, exists := s.webhooks[id]
+	if !exists || hook.WorkspaceID != s.workspace() {
+		return nil, errors.New("webhook not found")
+	}
+	var events []string
+	if req.Events != nil {
+		if events, err = validateWebhookEvents(*req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil && *req.Secret == "" {
+		return nil, fmt.Errorf("%w: secret cannot be empty", ErrInvalidRequest)
+	}
+
+	before := s.snapshot(webhookView(hook))
+	if req.URL != nil {
+		hook.URL = *req.URL
+	}
+	if req.Secret != nil {
+		hook.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		hook.Events = events
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	if req.Description != nil {
+		hook.Description = *req.Description
+	}
+	hook.UpdatedAt = time.Now()
+
+	s.publish(models.EventWebhookUpdated, before, s.snapshot(webhookView(hook)))
+	return webhookView(hook), nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery log. Queued deliveries
+// are dropped.
+func (s *Service) DeleteWebhook(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return err
+	}
+
+	hook, exists := s.webhooks[id]
+	if !exists || hook.WorkspaceID != s.workspace() {
+		return errors.New("webhook not found")
+	}
+	for _, deliveryID := range s.deliveryLog[id] {
+		delete(s.deliveries, deliveryID)
+	}
+	delete(s.deliveryLog, id)
+	delete(s.webhooks, id)
+	s.publish(models.EventWebhookDeleted, s.snapshot(webhookView(hook)), nil)
+	return nil
+}
+
+// PingWebhook queues a ping event for a webhook, whatever its filter and
+// even when it is inactive, and returns the pending delivery
+func (s *Service) PingWebhook(id string) (*models.WebhookDelivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	hook, exists := s.webhooks[id]
+	if !exists || hook.WorkspaceID != s.workspace() {
+		return nil, errors.New("webhook not found")
+	}
+
+	payload := models.WebhookPayload{
+		ID:         uuid.New().String(),
+		Event:      models.EventPing,
+		OccurredAt: time.Now(),
+		Data:       webhookView(hook),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	delivery := s.queueDelivery(hook, payload, body)
+	deliveryCopy := *delivery
+	return &deliveryCopy, nil
+}
+
+// GetWebhookDeliveries returns the delivery log of a webhook, newest first
+func (s *Service) GetWebhookDeliveries(id string) ([]models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	if hook, exists := s.webhooks[id]; !exists || hook.WorkspaceID != s.workspace() {
+		return nil, errors.New("webhook not found")
+	}
+
+	log := s.deliveryLog[id]
+	deliveries := make([]models.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		if delivery, exists := s.deliveries[log[i]]; exists {
+			deliveryCopy := *delivery
+			deliveryCopy.Attempts = append([]models.WebhookAttempt{}, delivery.Attempts...)
+			deliveries = append(deliveries, deliveryCopy)
+		}
+	}
+	return deliveries, nil
+}
+
+// StartWebhookDispatcher starts sending queued deliveries in the background.
+// The returned function stops it.
+func (s *Service) StartWebhookDispatcher() func() {
+	return s.dispatcher.Start()
+}
+
+// deliverEvent queues a change for every active webhook of its workspace
+// subscribed to it.
+// It subscribes asynchronously, so matching webhooks does not slow changes
+// down.
+func (s *Service) deliverEvent(event events.Event) {
+	data, err := changeData(event)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var hooks []*models.Webhook
+	for _, hook := range s.webhooks {
+		if hook.Active && hook.WorkspaceID == event.WorkspaceID && events.Matches(hook.Events, event.Type) {
+			hooks = append(hooks, hook)
+		}
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		ID:         uuid.New().String(),
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	for _, hook := range hooks {
+		s.queueDelivery(hook, payload, body)
+	}
+}
+
+// queueDelivery records a delivery in the log of a webhook and hands it to
+// the dispatcher. Callers must hold the lock.
+func (s *Service) queueDelivery(hook *models.Webhook, payload models.WebhookPayload, body []byte) *models.WebhookDelivery {
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: hook.ID,
+		EventID:   payload.ID,
+		Event:     payload.Event,
+		Status:    models.DeliveryPending,
+		Attempts:  []models.WebhookAttempt{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.deliveries[delivery.ID] = delivery
+
+	// Only the most recent deliveries of each webhook are kept
+	log := append(s.deliveryLog[hook.ID], delivery.ID)
+	if limit := s.config.WebhookLogSize; limit > 0 && len(log) > limit {
+		for _, id := range log[:len(log)-limit] {
+			delete(s.deliveries, id)
+		}
+		log = append([]string{}, log[len(log)-limit:]...)
+	}
+	s.deliveryLog[hook.ID] = log
+
+	s.dispatcher.Enqueue(webhook.Job{
+		DeliveryID: delivery.ID,
+		URL:        hook.URL,
+		Secret:     hook.Secret,
+		Event:      payload.Event,
+		Body:       body,
+	})
+	return delivery
+}
+
+// webhookJobWanted keeps the dispatcher from sending deliveries whose
+// webhook was deleted or disabled, or that dropped out of the delivery log.
+// Pings are sent to disabled webhooks too. Wanted jobs are sent to the
+// current URL of their webhook, signed with its current secret.
+func (s *Service) webhookJobWanted(job *webhook.Job) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	delivery, exists := s.deliveries[job.DeliveryID]
+	if !exists {
+		return false
+	}
+	hook, exists := s.webhooks[delivery.WebhookID]
+	if !exists || (!hook.Active && delivery.Event != models.EventPing) {
+		return false
+	}
+	job.URL = hook.URL
+	job.Secret = hook.Secret
+	return true
+}
+
+// recordWebhookAttempt adds the outcome of an attempt to the delivery log
+func (s *Service) recordWebhookAttempt(attempt webhook.Attempt) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery, exists := s.deliveries[attempt.DeliveryID]
+	if !exists {
+		return
+	}
+	delivery.Attempts = append(delivery.Attempts, models.WebhookAttempt{
+		Number:     attempt.Number,
+		At:         attempt.At,
+		DurationMS: attempt.Duration.Milliseconds(),
+		StatusCode: attempt.StatusCode,
+		Error:      attempt.Error,
+	})
+	delivery.NextAttemptAt = nil
+	switch {
+	case attempt.Succeeded:
+		delivery.Status = models.DeliverySucceeded
+	case attempt.Retry:
+		delivery.Status = models.DeliveryRetrying
+		next := attempt.NextAttempt
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = models.DeliveryFailed
+	}
+	delivery.UpdatedAt = time.Now()
+}
+
+// webhookView returns a copy of a webhook without its secret
+func webhookView(hook *models.Webhook) *models.Webhook {
+	view := *hook
+	view.Secret = ""
+	return &view
+}
+
+// validateWebhookURL checks that a URL is an absolute http or https URL
+// whose host does not resolve to a private address, unless private
+// networks are allowed. The dispatcher checks the address again when it
+// connects, as the host may resolve differently by then.
+func (s *Service) validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidRequest)
+	}
+	if s.config.WebhookAllowPrivateNetworks {
+		return nil
+	}
+
+	host := parsed.Hostname()
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+		defer cancel()
+		if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+			return fmt.Errorf("%w: host %q cannot be resolved", ErrInvalidRequest, host)
+		}
+	}
+	for _, addr := range addrs {
+		if webhook.IsPrivate(addr) {
+			return fmt.Errorf("%w: url must not point at a private, loopback or link-local address", ErrInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// validateWebhookEvents checks an event filter against the known events
+func validateWebhookEvents(events []string) ([]string, error) {
+	valid := []string{}
+	for _, pattern := range events {
+		pattern = strings.TrimSpace(pattern)
+		matched := pattern == "*"
+		for _, event := range models.ChangeEvents {
+			prefix, wildcard := strings.CutSuffix(pattern, "*")
+			if event == pattern || (wildcard && strings.HasPrefix(event, prefix)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidRequest, pattern)
+		}
+		valid = append(valid, pattern)
+	}
+	return valid, nil
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"golang-baseline/config"
+	"golang-baseline/models"
+	"golang-baseline/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newWebhookTestService creates a service that may deliver to the local
+// test servers and retries without waiting
+func newWebhookTestService(t *testing.T) *Service {
+	t.Helper()
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.WebhookAllowPrivateNetworks = true
+		cfg.WebhookMaxAttempts = 3
+		cfg.WebhookRetryBase = 0
+		cfg.WebhookTimeout = 5
+	})
+	t.Cleanup(s.StartWebhookDispatcher())
+	return s
+}
+
+// waitForDeliveries waits until a webhook has count deliveries that are
+// neither pending nor retrying, and returns its delivery log
+func waitForDeliveries(t *testing.T, s *Service, hookID string, count int) []models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := s.GetWebhookDeliveries(hookID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := 0
+		for _, delivery := range deliveries {
+			if delivery.Status == models.DeliverySucceeded || delivery.Status == models.DeliveryFailed {
+				done++
+			}
+		}
+		if done >= count {
+			return deliveries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries = %+v", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPingWebhook(t *testing.T) {
+	var calls atomic.Int32
+	verified := make(chan bool, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		verified <- webhook.Verify("secret", timestamp, body, r.Header.Get(webhook.HeaderSignature))
+		// The first attempt fails and is retried
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	s := newWebhookTestService(t)
+	hook, err := s.CreateWebhook(models.CreateWebhookRequest{URL: server.URL, Secret: "secret", Events: []string{"story.*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery, err := s.PingWebhook(hook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Event != models.EventPing || delivery.Status != models.DeliveryPending {
+		t.Errorf("delivery = %+v", delivery)
+	}
+
+	deliveries := waitForDeliveries(t, s, hook.ID, 1)
+	if len(deliveries) != 1 || deliveries[0].ID != delivery.ID || deliveries[0].Status != models.DeliverySucceeded {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	attempts := deliveries[0].Attempts
+	if len(attempts) != 2 || attempts[0].StatusCode != http.StatusServiceUnavailable || attempts[1].StatusCode != http.StatusOK {
+		t.Errorf("attempts = %+v", attempts)
+	}
+	if deliveries[0].NextAttemptAt != nil {
+		t.Error("a finished delivery has a next attempt")
+	}
+	for i := 0; i < 2; i++ {
+		if !<-verified {
+			t.Error("delivery signature does not verify")
+		}
+	}
+}
+
+func TestWebhookFailsAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	s := newWebhookTestService(t)
+	hook, err := s.CreateWebhook(models.CreateWebhookRequest{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PingWebhook(hook.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := waitForDeliveries(t, s, hook.ID, 1)
+	if deliveries[0].Status != models.DeliveryFailed || len(deliveries[0].Attempts) != 3 {
+		t.Errorf("delivery = %+v", deliveries[0])
+	}
+	if calls.Load() != 3 {
+		t.Errorf("receiver called %d times", calls.Load())
+	}
+}
+
+func TestInactiveWebhookOnlyGetsPings(t *testing.T) {
+	events := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events <- r.Header.Get(webhook.HeaderEvent)
+	}))
+	defer server.Close()
+
+	s := newWebhookTestService(t)
+	active := false
+	hook, err := s.CreateWebhook(models.CreateWebhookRequest{URL: server.URL, Events: []string{"*"}, Active: &active})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopEvents := s.StartEventBus()
+	if _, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Backlog"}); err != nil {
+		t.Fatal(err)
+	}
+	stopEvents()
+	if _, err := s.PingWebhook(hook.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := waitForDeliveries(t, s, hook.ID, 1)
+	if len(deliveries) != 1 || deliveries[0].Event != models.EventPing {
+		t.Errorf("deliveries = %+v", deliveries)
+	}
+	if event := <-events; event != models.EventPing {
+		t.Errorf("received %q", event)
+	}
+}
+
+func TestDisabledWebhookDropsQueuedDeliveries(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) { cfg.WebhookAllowPrivateNetworks = true })
+	hook, err := s.CreateWebhook(models.CreateWebhookRequest{URL: "http://127.0.0.1:1/hook", Events: []string{"*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopEvents := s.StartEventBus()
+	if _, err := s.CreateBacklog(models.CreateBacklogRequest{Title: "Backlog"}); err != nil {
+		t.Fatal(err)
+	}
+	stopEvents()
+
+	deliveries, err := s.GetWebhookDeliveries(hook.ID)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, %v", deliveries, err)
+	}
+	job := webhook.Job{DeliveryID: deliveries[0].ID}
+	if !s.webhookJobWanted(&job) || job.URL != "http://127.0.0.1:1/hook" || job.Secret == "" {
+		t.Errorf("job = %+v", job)
+	}
+
+	// Deliveries are sent to the current URL, and not at all once disabled
+	url := "http://127.0.0.1:2/hook"
+	if _, err := s.UpdateWebhook(hook.ID, models.UpdateWebhookRequest{URL: &url}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.webhookJobWanted(&job) || job.URL != url {
+		t.Errorf("job = %+v", job)
+	}
+	active := false
+	if _, err := s.UpdateWebhook(hook.ID, models.UpdateWebhookRequest{Active: &active}); err != nil {
+		t.Fatal(err)
+	}
+	if s.webhookJobWanted(&job) {
+		t.Error("job of a disabled webhook is wanted")
+	}
+}
+
+func TestWebhookURLMustBePublic(t *testing.T) {
+	s := newTestService(t, nil)
+	for _, url := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"http://[::1]/hook",
+		"ftp://example.com/hook",
+		"/hook",
+	} {
+		_, err := s.CreateWebhook(models.CreateWebhookRequest{URL: url})
+		if !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%s: error = %v", url, err)
+		}
+	}
+
+	hook, err := s.CreateWebhook(models.CreateWebhookRequest{URL: "https://93.184.216.34/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://127.0.0.1/hook"
+	if _, err := s.UpdateWebhook(hook.ID, models.UpdateWebhookRequest{URL: &url}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("update error = %v", err)
+	}
+}
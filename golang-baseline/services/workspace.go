@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"golang-baseline/config"
+	"golang-baseline/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Workspace checks. Every query is scoped to the workspace of the request:
+// users work in their own workspace, other principals in the one named by
+// the request, or the default workspace.
+
+// workspace returns the workspace of the request. Callers must hold the
+// lock.
+func (s *Service) workspace() string {
+	if principal := s.request.Principal; principal != nil {
+		if user, exists := s.users[principal.UserID]; exists {
+			return user.WorkspaceID
+		}
+	}
+	if s.request.Workspace != "" {
+		return s.request.Workspace
+	}
+	return models.DefaultWorkspace
+}
+
+// isInstanceAdmin reports whether the request may manage every workspace:
+// it comes from the application itself, the bootstrap key, or is served
+// while authentication is not required. Callers must hold the lock.
+func (s *Service) isInstanceAdmin() bool {
+	principal := s.request.Principal
+	return principal == nil || principal.Method == models.AuthBootstrap
+}
+
+// workspaceOf returns the workspace of a backlog. Callers must hold the
+// lock.
+func (s *Service) workspaceOf(backlogID string) string {
+	if backlog, exists := s.backlogs[backlogID]; exists {
+		return backlog.WorkspaceID
+	}
+	return ""
+}
+
+// workflow returns the workflow rules of a workspace, with the server
+// configuration filled in where the workspace does not override it.
+// Callers must hold the lock.
+func (s *Service) workflow(workspaceID string) models.WorkflowSettings {
+	requireChecklist := s.config.RequireChecklistForDone
+	rules := models.WorkflowSettings{
+		BlockedStatusPolicy:     s.config.BlockedStatusPolicy,
+		WIPLimitPolicy:          s.config.WIPLimitPolicy,
+		RequireChecklistForDone: &requireChecklist,
+	}
+	workspace, exists := s.workspaces[workspaceID]
+	if !exists {
+		return rules
+	}
+	if policy := workspace.Settings.Workflow.BlockedStatusPolicy; policy != "" {
+		rules.BlockedStatusPolicy = policy
+	}
+	if policy := workspace.Settings.Workflow.WIPLimitPolicy; policy != "" {
+		rules.WIPLimitPolicy = policy
+	}
+	if required := workspace.Settings.Workflow.RequireChecklistForDone; required != nil {
+		rules.RequireChecklistForDone = required
+	}
+	return rules
+}
+
+// calendarOf returns the working calendar of a workspace. Callers must
+// hold the lock.
+func (s *Service) calendarOf(workspaceID string) models.Calendar {
+	if workspace, exists := s.workspaces[workspaceID]; exists {
+		return workspace.Settings.Calendar
+	}
+	return models.DefaultCalendar()
+}
+
+// visibleWorkspace finds a workspace the request may see: its own, or any
+// workspace for instance admins. Callers must hold the lock.
+func (s *Service) visibleWorkspace(id string) (*models.Workspace, error) {
+	workspace, exists := s.workspaces[id]
+	if !exists || (!s.isInstanceAdmin() && id != s.workspace()) {
+		return nil, errors.New("workspace not found")
+	}
+	return workspace, nil
+}
+
+// validateWorkflow checks the policies of workflow settings
+func validateWorkflow(workflow models.WorkflowSettings) error {
+	for name, policy := range map[string]string{
+		"blocked_status_policy": workflow.BlockedStatusPolicy,
+		"wip_limit_policy":      workflow.WIPLimitPolicy,
+	} {
+		if policy != "" && policy != config.PolicyWarn && policy != config.PolicyReject {
+			return fmt.Errorf("%w: %s must be %q or %q", ErrInvalidRequest, name, config.PolicyWarn, config.PolicyReject)
+		}
+	}
+	return nil
+}
+
+// Workspace operations
+
+// WorkspaceExists reports whether a workspace exists
+func (s *Service) WorkspaceExists(id string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, exists := s.workspaces[id]
+	return exists
+}
+
+// CreateWorkspace creates an empty workspace. Only instance admins may
+// create workspaces.
+func (s *Service) CreateWorkspace(req models.CreateWorkspaceRequest) (*models.Workspace, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isInstanceAdmin() {
+		return nil, fmt.Errorf("%w: only instance admins may create workspaces", ErrForbidden)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	for _, existing := range s.workspaces {
+		if strings.EqualFold(existing.Name, name) {
+			return nil, fmt.Errorf("%w: workspace %q already exists", ErrInvalidRequest, name)
+		}
+	}
+	if err := validateWorkflow(req.Workflow); err != nil {
+		return nil, err
+	}
+	calendar := models.DefaultCalendar()
+	if req.Calendar != nil {
+		if err := validateCalendar(*req.Calendar); err != nil {
+			return nil, err
+		}
+		if req.Calendar.Holidays == nil {
+			req.Calendar.Holidays = []string{}
+		}
+		calendar = *req.Calendar
+	}
+
+	workspace := &models.Workspace{
+		ID:   uuid.New().String(),
+		Name: name,
+		Settings: models.WorkspaceSettings{
+			Workflow: req.Workflow,
+			Calendar: calendar,
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	s.workspaces[workspace.ID] = workspace
+	s.capacities[workspace.ID] = make(map[string]float64)
+	s.publish(models.EventWorkspaceCreated, nil, s.snapshot(workspace))
+	return workspaceView(workspace), nil
+}
+
+// GetWorkspaces returns every workspace to instance admins, and the
+// workspace of the request to everybody else
+func (s *Service) GetWorkspaces() ([]*models.Workspace, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	workspaces := []*models.Workspace{}
+	for _, workspace := range s.workspaces {
+		if s.isInstanceAdmin() || workspace.ID == s.workspace() {
+			workspaces = append(workspaces, workspaceView(workspace))
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+	return workspaces, nil
+}
+
+func (s *Service) GetWorkspace(id string) (*models.Workspace, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	workspace, err := s.visibleWorkspace(id)
+	if err != nil {
+		return nil, err
+	}
+	return workspaceView(workspace), nil
+}
+
+// UpdateWorkspace renames a workspace or replaces its settings. Admins of
+// the workspace and instance admins may update it.
+func (s *Service) UpdateWorkspace(id string, req models.UpdateWorkspaceRequest) (*models.Workspace, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	workspace, err := s.visibleWorkspace(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidRequest)
+		}
+		for _, existing := range s.workspaces {
+			if existing.ID != id && strings.EqualFold(existing.Name, name) {
+				return nil, fmt.Errorf("%w: workspace %q already exists", ErrInvalidRequest, name)
+			}
+		}
+	}
+	if req.Workflow != nil {
+		if err := validateWorkflow(*req.Workflow); err != nil {
+			return nil, err
+		}
+	}
+	if req.Calendar != nil {
+		if err := validateCalendar(*req.Calendar); err != nil {
+			return nil, err
+		}
+		if req.Calendar.Holidays == nil {
+			req.Calendar.Holidays = []string{}
+		}
+	}
+
+	before := s.snapshot(workspace)
+	if req.Name != nil {
+		workspace.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Workflow != nil {
+		workspace.Settings.Workflow = *req.Workflow
+	}
+	if req.Calendar != nil {
+		workspace.Settings.Calendar = *req.Calendar
+	}
+	workspace.UpdatedAt = time.Now()
+	s.publish(models.EventWorkspaceUpdated, before, s.snapshot(workspace))
+	return workspaceView(workspace), nil
+}
+
+// workspaceView returns a copy of a workspace, which may be updated while
+// the copy is encoded
+func workspaceView(workspace *models.Workspace) *models.Workspace {
+	view := *workspace
+	if required := workspace.Settings.Workflow.RequireChecklistForDone; required != nil {
+		requiredCopy := *required
+		view.Settings.Workflow.RequireChecklistForDone = &requiredCopy
+	}
+	view.Settings.Calendar.WorkingDays = slices.Clone(workspace.Settings.Calendar.WorkingDays)
+	view.Settings.Calendar.Holidays = slices.Clone(workspace.Settings.Calendar.Holidays)
+	return &view
+}
+
+// DeleteWorkspace deletes a workspace that no longer has backlogs or
+// users. The default workspace cannot be deleted.
+func (s *Service) DeleteWorkspace(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isInstanceAdmin() {
+		return fmt.Errorf("%w: only instance admins may delete workspaces", ErrForbidden)
+	}
+	workspace, exists := s.workspaces[id]
+	if !exists {
+		return errors.New("workspace not found")
+	}
+	if id == models.DefaultWorkspace {
+		return fmt.Errorf("%w: the default workspace cannot be deleted", ErrInvalidRequest)
+	}
+	for _, backlog := range s.backlogs {
+		if backlog.WorkspaceID == id {
+			return fmt.Errorf("%w: the workspace still has backlogs", ErrInvalidRequest)
+		}
+	}
+	for _, user := range s.users {
+		if user.WorkspaceID == id {
+			return fmt.Errorf("%w: the workspace still has users", ErrInvalidRequest)
+		}
+	}
+
+	deleted := s.snapshot(workspace)
+	delete(s.workspaces, id)
+	delete(s.capacities, id)
+	s.publish(models.EventWorkspaceDeleted, deleted, nil)
+	return nil
+}
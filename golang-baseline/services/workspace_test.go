@@ -0,0 +1,41 @@
+package services
+
+import (
+	"golang-baseline/models"
+	"testing"
+	"time"
+)
+
+func TestWorkspacesAreReturnedAsCopies(t *testing.T) {
+	s := newTestService(t, nil)
+	created, err := s.CreateWorkspace(models.CreateWorkspaceRequest{
+		Name:     "Research",
+		Calendar: &models.Calendar{WorkingDays: []time.Weekday{time.Monday}, Holidays: []string{"2024-12-25"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetWorkspace(created.ID)
+	all, _ := s.GetWorkspaces()
+
+	// Changing a returned workspace does not reach the stored one
+	got.Settings.Calendar.Holidays[0] = "2024-01-01"
+	if stored, _ := s.GetWorkspace(created.ID); stored.Settings.Calendar.Holidays[0] != "2024-12-25" {
+		t.Errorf("stored holidays = %v", stored.Settings.Calendar.Holidays)
+	}
+
+	name := "Labs"
+	calendar := models.Calendar{WorkingDays: []time.Weekday{time.Tuesday}}
+	updated, err := s.UpdateWorkspace(created.ID, models.UpdateWorkspaceRequest{Name: &name, Calendar: &calendar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Labs" || updated.Settings.Calendar.Holidays == nil {
+		t.Errorf("updated workspace = %+v", updated)
+	}
+	for _, workspace := range append(all, created) {
+		if workspace.ID == created.ID && (workspace.Name != "Research" || workspace.Settings.Calendar.WorkingDays[0] != time.Monday) {
+			t.Errorf("returned workspace changed: %+v", workspace)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package spreadsheet reads and writes tables of text cells as CSV and as
+// XLSX workbooks with a single sheet
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ErrUnknownFormat is returned for formats other than CSV and XLSX
+var ErrUnknownFormat = errors.New("unknown spreadsheet format")
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write writes rows in the given format
+func Write(w io.Writer, format string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, rows)
+	case FormatXLSX:
+		return WriteXLSX(w, rows)
+	}
+	return ErrUnknownFormat
+}
+
+// Read reads all rows of a file in the given format
+func Read(data []byte, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(bytes.NewReader(data))
+	case FormatXLSX:
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	}
+	return nil, ErrUnknownFormat
+}
+
+// WriteCSV writes rows as CSV
+func WriteCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// ReadCSV reads all rows of a CSV file. Rows may have different lengths and
+// a leading byte order mark is ignored.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
@@ -0,0 +1,289 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// sheetName is the name of the single sheet of written workbooks
+const sheetName = "Sheet1"
+
+// Limits of read workbooks. Rows and columns stop where spreadsheet
+// applications stop; the part and cell limits keep a small upload from
+// expanding into more memory than the server has.
+const (
+	maxRows     = 1048576
+	maxColumns  = 16384
+	maxCells    = 4 << 20
+	maxPartSize = 64 << 20
+)
+
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + sheetName + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// WriteXLSX writes rows as a workbook with one sheet. Every cell is stored
+// as an inline string so values round-trip exactly.
+func WriteXLSX(w io.Writer, rows [][]string) error {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(j), i+1)
+			if err := xml.EscapeText(&sheet, []byte(value)); err != nil {
+				return err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if _, err := io.WriteString(file, sheet.String()); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// xlsxText is rich or plain text, as found in shared and inline strings
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Index *int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the rows of the first sheet of a workbook. Cells are
+// returned as they are stored, so numbers and dates come back as their
+// numeric value.
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX file: %w", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var shared []string
+	if file, exists := files["xl/sharedStrings.xml"]; exists {
+		var table struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeXMLFile(file, &table); err != nil {
+			return nil, err
+		}
+		for _, item := range table.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	file, exists := files[sheetPath]
+	if !exists {
+		return nil, fmt.Errorf("workbook has no sheet %s", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeXMLFile(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	allocated := 0
+	for i, row := range sheet.Rows {
+		// Rows and cells may be sparse; their references give the position
+		index := i + 1
+		if row.Index != nil {
+			index = *row.Index
+		}
+		if index < 1 || index > maxRows {
+			return nil, fmt.Errorf("row %d is outside the sheet", index)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.Ref != "" {
+				column = columnIndex(cell.Ref)
+			}
+			if column < 0 || column >= maxColumns {
+				return nil, fmt.Errorf("cell %q of row %d is outside the sheet", cell.Ref, index)
+			}
+			if column >= len(cells) {
+				if allocated += column + 1 - len(cells); allocated > maxCells {
+					return nil, fmt.Errorf("sheet has more than %d cells", maxCells)
+				}
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", cell.Ref)
+				}
+				cells[column] = shared[n]
+			case "inlineStr":
+				cells[column] = cell.Inline.String()
+			default:
+				cells[column] = cell.Value
+			}
+		}
+		rows[index-1] = cells
+	}
+	return rows, nil
+}
+
+// firstSheetPath finds the part holding the first sheet of a workbook
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	fallback := "xl/worksheets/sheet1.xml"
+	workbook, hasWorkbook := files["xl/workbook.xml"]
+	rels, hasRels := files["xl/_rels/workbook.xml.rels"]
+	if !hasWorkbook || !hasRels {
+		return fallback, nil
+	}
+
+	var book struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXMLFile(workbook, &book); err != nil {
+		return "", err
+	}
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXMLFile(rels, &relationships); err != nil {
+		return "", err
+	}
+	if len(book.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+	for _, rel := range relationships.Items {
+		if rel.ID == book.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+// decodeXMLFile decodes a part of a workbook. Parts are read up to
+// maxPartSize once decompressed.
+func decodeXMLFile(file *zip.File, v interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	limited := &io.LimitedReader{R: reader, N: maxPartSize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return fmt.Errorf("%s is larger than %d bytes", file.Name, maxPartSize)
+		}
+		return fmt.Errorf("invalid %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// columnName converts a zero-based column index to its letters, so 0 is A
+// and 26 is AA
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "AB12", or -1 when the reference does not start with a column. Columns
+// beyond maxColumns are returned as maxColumns.
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		if index > maxColumns {
+			return maxColumns
+		}
+	}
+	return index - 1
+}
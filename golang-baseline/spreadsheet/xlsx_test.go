@@ -0,0 +1,96 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// workbook builds an XLSX file whose only sheet holds the given sheetData
+func workbook(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		sheetData + `</sheetData></worksheet>`
+	if _, err := file.Write([]byte(sheet)); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{{"title", "effort"}, {"<Login> & \"SSO\"", "3"}, {"", "", "last"}}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("got %q, want %q", got, rows)
+	}
+}
+
+func TestReadXLSXSparse(t *testing.T) {
+	data := workbook(t, `<row r="2"><c r="C2"><v>7</v></c></row>`)
+	got, err := ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{nil, {"", "", "7"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXMalformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+	}{
+		{"lowercase reference", `<row r="1"><c r="a1"><v>1</v></c></row>`},
+		{"reference without column", `<row r="1"><c r="1"><v>1</v></c></row>`},
+		{"column beyond the sheet", `<row r="1"><c r="XFE1"><v>1</v></c></row>`},
+		{"overflowing column", `<row r="1"><c r="ZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`},
+		{"negative row", `<row r="-5"><c r="A1"><v>1</v></c></row>`},
+		{"row zero", `<row r="0"><c r="A1"><v>1</v></c></row>`},
+		{"row beyond the sheet", `<row r="2000000000"><c r="A1"><v>1</v></c></row>`},
+		{"missing shared string", `<row r="1"><c r="A1" t="s"><v>0</v></c></row>`},
+		{"too many cells", strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, maxCells/maxColumns+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := workbook(t, tt.sheetData)
+			if _, err := ReadXLSX(bytes.NewReader(data), int64(len(data))); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestReadXLSXPartTooLarge(t *testing.T) {
+	data := workbook(t, `<row r="1"><c r="A1"><v>`+strings.Repeat("9", maxPartSize)+`</v></c></row>`)
+	_, err := ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected a size error, got %v", err)
+	}
+}
+
+func TestReadXLSXNotAWorkbook(t *testing.T) {
+	data := []byte("title,effort\n")
+	if _, err := ReadXLSX(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("expected an error")
+	}
+}
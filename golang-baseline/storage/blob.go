@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist in the store
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Blob is the readable, seekable content of a stored blob
+type Blob interface {
+	io.ReadSeekCloser
+}
+
+// BlobStore stores content addressed by its SHA-256 hash, so identical
+// uploads share a single blob
+type BlobStore interface {
+	// Put stores the content of r and returns its hex encoded SHA-256 hash
+	// and size. Storing content that already exists is a no-op.
+	Put(r io.Reader) (hash string, size int64, err error)
+	// Open returns the blob stored under hash
+	Open(hash string) (Blob, error)
+	// Exists reports whether a blob is stored under hash
+	Exists(hash string) (bool, error)
+	// Delete removes the blob stored under hash
+	Delete(hash string) error
+}
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// LocalStore is a BlobStore backed by a directory on the local filesystem
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at dir. The directory is created on
+// first write.
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{root: dir}
+}
+
+// path returns the location of a blob, sharded by the first two characters
+// of its hash to keep directories small
+func (s *LocalStore) path(hash string) (string, error) {
+	if !hashPattern.MatchString(hash) {
+		return "", fmt.Errorf("invalid blob hash %q", hash)
+	}
+	return filepath.Join(s.root, hash[:2], hash), nil
+}
+
+// Put writes the content to a temporary file while hashing it, then moves
+// it into place
+func (s *LocalStore) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	path, err := s.path(hash)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return hash, size, nil
+}
+
+func (s *LocalStore) Open(hash string) (Blob, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStore) Exists(hash string) (bool, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStore) Delete(hash string) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package webhook signs webhook requests and delivers them from a
+// background queue, retrying failed deliveries with exponential backoff
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm of a signature
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of a delivery: the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the secret, prefixed with "sha256=".
+// Signing the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a signature matches a delivery, in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ErrPrivateAddress is returned for deliveries to addresses of the host or
+// its private networks
+var ErrPrivateAddress = errors.New("webhook: private, loopback and link-local addresses are not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip does not
+// count as private
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPrivate reports whether an address belongs to the host or its networks
+// rather than the internet: loopback, private, link-local, shared,
+// unspecified and multicast addresses. Webhooks must not reach them, or
+// anyone creating a webhook could call services such as cloud metadata
+// endpoints from inside the network.
+func IsPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
+
+// Job is a delivery waiting in the queue. Attempts counts the attempts
+// already made.
+type Job struct {
+	DeliveryID string
+	URL        string
+	Secret     string
+	Event      string
+	Body       []byte
+	Attempts   int
+}
+
+// Attempt is the outcome of one attempt at a delivery. Retry is set when
+// the delivery failed and will be attempted again at NextAttempt.
+type Attempt struct {
+	DeliveryID  string
+	Number      int
+	At          time.Time
+	Duration    time.Duration
+	StatusCode  int
+	Error       string
+	Succeeded   bool
+	Retry       bool
+	NextAttempt time.Time
+}
+
+// Options tunes a dispatcher. Deliveries to private addresses are refused
+// unless AllowPrivateNetworks is set.
+type Options struct {
+	Workers              int
+	MaxAttempts          int
+	BaseDelay            time.Duration
+	MaxDelay             time.Duration
+	Timeout              time.Duration
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher sends queued deliveries with a pool of workers. Enqueue never
+// blocks, so it is safe to call while holding locks.
+type Dispatcher struct {
+	opts   Options
+	client *http.Client
+	// check drops jobs whose webhook was removed or disabled, and brings
+	// the URL and secret of the others up to date; report receives the
+	// outcome of every attempt
+	check  func(*Job) bool
+	report func(Attempt)
+
+	mutex sync.Mutex
+	queue []Job
+	wake  chan struct{}
+}
+
+// NewDispatcher creates a dispatcher. Nothing is sent until Start is called.
+func NewDispatcher(opts Options, check func(*Job) bool, report func(Attempt)) *Dispatcher {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !opts.AllowPrivateNetworks {
+		// Addresses are checked once resolved, so host names that resolve
+		// to private addresses and redirects to them are refused too
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || IsPrivate(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+			}
+			return nil
+		}
+	}
+	// Deliveries never go through a proxy, which would hide the address
+	// that is really called
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &Dispatcher{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout, Transport: transport},
+		check:  check,
+		report: report,
+		wake:   make(chan struct{}, opts.Workers),
+	}
+}
+
+// Enqueue adds a job to the queue
+func (d *Dispatcher) Enqueue(job Job) {
+	d.mutex.Lock()
+	d.queue = append(d.queue, job)
+	d.mutex.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// Every worker already has a wake-up pending
+	}
+}
+
+// Start runs the workers in the background. The returned function stops
+// them once their current delivery is done; retries that are still waiting
+// are dropped.
+func (d *Dispatcher) Start() func() {
+	done := make(chan struct{})
+	var workers sync.WaitGroup
+	for i := 0; i < d.opts.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				job, ok := d.next(done)
+				if !ok {
+					return
+				}
+				d.deliver(job)
+			}
+		}()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			workers.Wait()
+		})
+	}
+}
+
+// next waits for a job, or reports false once the dispatcher is stopped
+func (d *Dispatcher) next(done chan struct{}) (Job, bool) {
+	for {
+		d.mutex.Lock()
+		if len(d.queue) > 0 {
+			job := d.queue[0]
+			d.queue = d.queue[1:]
+			d.mutex.Unlock()
+			return job, true
+		}
+		d.mutex.Unlock()
+
+		select {
+		case <-done:
+			return Job{}, false
+		case <-d.wake:
+		}
+	}
+}
+
+// deliver makes one attempt at a job. Network errors, timeouts and 408,
+// 429 and 5xx answers are retried; other answers and refused private
+// addresses are final.
+func (d *Dispatcher) deliver(job Job) {
+	if d.check != nil && !d.check(&job) {
+		return
+	}
+	job.Attempts++
+	attempt := Attempt{DeliveryID: job.DeliveryID, Number: job.Attempts, At: time.Now()}
+
+	retryable := true
+	req, err := http.NewRequest(http.MethodPost, job.URL, bytes.NewReader(job.Body))
+	if err == nil {
+		timestamp := attempt.At.Unix()
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "golang-baseline-webhooks/1.0")
+		req.Header.Set(HeaderEvent, job.Event)
+		req.Header.Set(HeaderDelivery, job.DeliveryID)
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		if job.Secret != "" {
+			req.Header.Set(HeaderSignature, Sign(job.Secret, timestamp, job.Body))
+		}
+
+		var resp *http.Response
+		if resp, err = d.client.Do(req); err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			attempt.StatusCode = resp.StatusCode
+			attempt.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+			retryable = resp.StatusCode == http.StatusRequestTimeout ||
+				resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+			if !attempt.Succeeded {
+				attempt.Error = resp.Status
+			}
+		}
+	} else {
+		retryable = false
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		// A private address stays private, so retrying cannot help
+		if errors.Is(err, ErrPrivateAddress) {
+			retryable = false
+		}
+	}
+	attempt.Duration = time.Since(attempt.At)
+
+	if !attempt.Succeeded && retryable && job.Attempts < d.opts.MaxAttempts {
+		delay := d.backoff(job.Attempts)
+		attempt.Retry = true
+		attempt.NextAttempt = time.Now().Add(delay)
+		d.report(attempt)
+		time.AfterFunc(delay, func() { d.Enqueue(job) })
+		return
+	}
+	d.report(attempt)
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled
+// for every failed attempt, capped at the maximum, with up to 10% jitter so
+// retries of many deliveries spread out
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.BaseDelay
+	for i := 1; i < attempts && delay < d.opts.MaxDelay; i++ {
+		delay *= 2
+	}
+	if d.opts.MaxDelay > 0 && delay > d.opts.MaxDelay {
+		delay = d.opts.MaxDelay
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/10 + 1))
+	}
+	return delay
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"story.created"}`)
+	signature := Sign("secret", 1700000000, body)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`1700000000.{"event":"story.created"}`))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Fatalf("signature = %q, want %q", signature, want)
+	}
+	if !Verify("secret", 1700000000, body, signature) {
+		t.Error("valid signature rejected")
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+	}{
+		{"other secret", "other", 1700000000, body, signature},
+		{"replayed timestamp", "secret", 1700000001, body, signature},
+		{"changed body", "secret", 1700000000, []byte(`{"event":"story.deleted"}`), signature},
+		{"missing prefix", "secret", 1700000000, body, signature[len(signaturePrefix):]},
+		{"empty", "secret", 1700000000, body, ""},
+	}
+	for _, tt := range tests {
+		if Verify(tt.secret, tt.timestamp, tt.body, tt.signature) {
+			t.Errorf("%s: signature accepted", tt.name)
+		}
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1":          true,
+		"10.1.2.3":           true,
+		"172.16.0.1":         true,
+		"192.168.1.1":        true,
+		"169.254.169.254":    true,
+		"100.64.0.1":         true,
+		"0.0.0.0":            true,
+		"224.0.0.1":          true,
+		"::1":                true,
+		"fe80::1":            true,
+		"fd00::1":            true,
+		"::ffff:127.0.0.1":   true,
+		"::ffff:169.254.1.1": true,
+		"93.184.216.34":      false,
+		"2606:4700::1111":    false,
+	}
+	for address, want := range tests {
+		if got := IsPrivate(netip.MustParseAddr(address)); got != want {
+			t.Errorf("IsPrivate(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+// collect starts a dispatcher and returns a channel receiving its attempts
+func collect(t *testing.T, opts Options, check func(*Job) bool) (*Dispatcher, chan Attempt) {
+	t.Helper()
+	attempts := make(chan Attempt, 16)
+	d := NewDispatcher(opts, check, func(a Attempt) { attempts <- a })
+	t.Cleanup(d.Start())
+	return d, attempts
+}
+
+func next(t *testing.T, attempts chan Attempt) Attempt {
+	t.Helper()
+	select {
+	case attempt := <-attempts:
+		return attempt
+	case <-time.After(5 * time.Second):
+		t.Fatal("no attempt reported")
+	}
+	return Attempt{}
+}
+
+func testOptions() Options {
+	return Options{
+		Workers:              2,
+		MaxAttempts:          3,
+		BaseDelay:            10 * time.Millisecond,
+		MaxDelay:             time.Second,
+		Timeout:              time.Second,
+		AllowPrivateNetworks: true,
+	}
+}
+
+func TestDeliverySigned(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	d, attempts := collect(t, testOptions(), nil)
+	d.Enqueue(Job{DeliveryID: "d1", URL: server.URL, Secret: "secret", Event: "story.created", Body: []byte(`{"a":1}`)})
+
+	attempt := next(t, attempts)
+	if !attempt.Succeeded || attempt.Number != 1 || attempt.StatusCode != http.StatusOK {
+		t.Fatalf("attempt = %+v", attempt)
+	}
+	r, body := <-received, <-bodies
+	if r.Header.Get(HeaderEvent) != "story.created" || r.Header.Get(HeaderDelivery) != "d1" {
+		t.Errorf("headers = %v", r.Header)
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify("secret", timestamp, body, r.Header.Get(HeaderSignature)) {
+		t.Error("delivery signature does not verify")
+	}
+}
+
+func TestRetriesWithBackoff(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) < 3 {
+					w.WriteHeader(status)
+				}
+			}))
+			defer server.Close()
+
+			d, attempts := collect(t, testOptions(), nil)
+			d.Enqueue(Job{DeliveryID: "d1", URL: server.URL})
+
+			first, second, third := next(t, attempts), next(t, attempts), next(t, attempts)
+			if !first.Retry || first.StatusCode != status || first.Succeeded {
+				t.Errorf("first attempt = %+v", first)
+			}
+			if !second.Retry || second.Number != 2 {
+				t.Errorf("second attempt = %+v", second)
+			}
+			if !third.Succeeded || third.Retry || third.Number != 3 {
+				t.Errorf("third attempt = %+v", third)
+			}
+			// The delay doubles after every failure
+			if wait := second.At.Sub(first.At); wait < 10*time.Millisecond {
+				t.Errorf("retried after %s", wait)
+			}
+			if wait := third.At.Sub(second.At); wait < 20*time.Millisecond {
+				t.Errorf("retried after %s", wait)
+			}
+		})
+	}
+}
+
+func TestFailsAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	d, attempts := collect(t, testOptions(), nil)
+	d.Enqueue(Job{DeliveryID: "d1", URL: server.URL})
+
+	for i := 1; i <= 3; i++ {
+		attempt := next(t, attempts)
+		if attempt.Number != i || attempt.Succeeded || attempt.Retry != (i < 3) {
+			t.Errorf("attempt %d = %+v", i, attempt)
+		}
+	}
+	select {
+	case attempt := <-attempts:
+		t.Errorf("attempted after the last attempt: %+v", attempt)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if calls.Load() != 3 {
+		t.Errorf("receiver called %d times", calls.Load())
+	}
+}
+
+func TestClientErrorsAreFinal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	d, attempts := collect(t, testOptions(), nil)
+	d.Enqueue(Job{DeliveryID: "d1", URL: server.URL})
+
+	if attempt := next(t, attempts); attempt.Retry || attempt.Succeeded || attempt.StatusCode != http.StatusGone {
+		t.Errorf("attempt = %+v", attempt)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(Options{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, nil, nil)
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		// Up to 10% jitter is added
+		if got := d.backoff(attempts); got < want || got > want+want/10 {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestCheckDropsAndRefreshesJobs(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	check := func(job *Job) bool {
+		if job.DeliveryID == "dropped" {
+			return false
+		}
+		job.URL = server.URL
+		return true
+	}
+	d, attempts := collect(t, testOptions(), check)
+	d.Enqueue(Job{DeliveryID: "dropped", URL: server.URL})
+	d.Enqueue(Job{DeliveryID: "moved", URL: "http://127.0.0.1:1/old"})
+
+	if attempt := next(t, attempts); attempt.DeliveryID != "moved" || !attempt.Succeeded {
+		t.Errorf("attempt = %+v", attempt)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("receiver called %d times", calls.Load())
+	}
+}
+
+func TestPrivateAddressesRefused(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	opts := testOptions()
+	opts.AllowPrivateNetworks = false
+	d, attempts := collect(t, opts, nil)
+	d.Enqueue(Job{DeliveryID: "d1", URL: server.URL})
+
+	attempt := next(t, attempts)
+	if attempt.Succeeded || attempt.Retry {
+		t.Errorf("attempt = %+v", attempt)
+	}
+	if calls.Load() != 0 {
+		t.Error("private address was called")
+	}
+
+	// The refusal is recognisable, so it is not retried
+	_, err := d.client.Get(server.URL)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("error = %v", err)
+	}
+}